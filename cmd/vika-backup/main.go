@@ -0,0 +1,105 @@
+// Command vika-backup backs up a space to a tar.gz archive, and restores the archive to a space.
+//
+// Usage:
+//
+//	vika-backup -token $APITABLE_TOKEN -space spc***** -o backup.tar.gz
+//	vika-backup -token $APITABLE_TOKEN -restore backup.tar.gz -target spc***** -folder fod*****
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/backup"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/common"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/common/profile"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/space"
+	"os"
+)
+
+func main() {
+	token := flag.String("token", os.Getenv("APITABLE_TOKEN"), "the developer token, default is $APITABLE_TOKEN")
+	domain := flag.String("domain", os.Getenv("DOMAIN"), "the api domain, default is the produced host")
+	spaceId := flag.String("space", "", "the space to back up")
+	output := flag.String("o", "", "the output archive of the backup")
+	restore := flag.String("restore", "", "the archive to restore")
+	target := flag.String("target", "", "the space to restore to")
+	folder := flag.String("folder", "", "the folder to restore to, default is the root folder")
+	skipAttachments := flag.Bool("skip-attachments", false, "don't back up the attachments")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: vika-backup [flags]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	backingUp := *spaceId != "" && *output != ""
+	restoring := *restore != "" && *target != ""
+	if *token == "" || backingUp == restoring {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	credential := common.NewCredential(*token)
+	cpf := profile.NewClientProfile()
+	cpf.HttpProfile.Domain = *domain
+	if backingUp {
+		runBackup(credential, cpf, *spaceId, *output, *skipAttachments)
+		return
+	}
+	runRestore(credential, cpf, *restore, *target, *folder)
+}
+
+func runBackup(credential *common.Credential, cpf *profile.ClientProfile, spaceId string, output string, skipAttachments bool) {
+	s, _ := space.NewSpace(credential, spaceId, cpf)
+	f, err := os.Create(output)
+	if err != nil {
+		fail(err)
+	}
+	manifest, err := backup.Backup(s, f, &backup.Options{
+		SkipAttachments: skipAttachments,
+		Progress: func(entry *backup.DatasheetEntry) {
+			fmt.Fprintf(os.Stderr, "backing up %s (%s)\n", entry.Path, entry.Id)
+		},
+	})
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(output)
+		fail(err)
+	}
+	printProblems(manifest.Problems)
+	fmt.Printf("backed up %d datasheets and %d attachments to %s\n", len(manifest.Datasheets), len(manifest.Attachments), output)
+}
+
+func runRestore(credential *common.Credential, cpf *profile.ClientProfile, archive string, spaceId string, folderId string) {
+	s, _ := space.NewSpace(credential, spaceId, cpf)
+	f, err := os.Open(archive)
+	if err != nil {
+		fail(err)
+	}
+	defer f.Close()
+	report, err := backup.Restore(s, f, &backup.RestoreOptions{
+		FolderId: folderId,
+		Progress: func(entry *backup.DatasheetEntry) {
+			fmt.Fprintf(os.Stderr, "restoring %s (%s)\n", entry.Path, entry.Id)
+		},
+	})
+	if report != nil {
+		printProblems(report.Problems)
+	}
+	if err != nil {
+		fail(err)
+	}
+	fmt.Printf("restored %d datasheets, %d records and %d attachments to %s\n", len(report.Datasheets), len(report.Records), report.Attachments, spaceId)
+	fmt.Println("note: the folders are not restored, the datasheets are created in the target folder")
+}
+
+func printProblems(problems []string) {
+	for _, problem := range problems {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", problem)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "vika-backup: %s\n", err)
+	os.Exit(1)
+}
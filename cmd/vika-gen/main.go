@@ -0,0 +1,73 @@
+// Command vika-gen generates go structs from datasheet schemas.
+//
+// Usage:
+//
+//	vika-gen -token $APITABLE_TOKEN -package model -o model/datasheets.go dst*****:Leads dst*****:Orders
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/codegen"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/common"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/common/profile"
+	apitable "github.com/apitable/apitable-sdks/apitable.go/lib/datasheet"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+func main() {
+	token := flag.String("token", os.Getenv("APITABLE_TOKEN"), "the developer token, default is $APITABLE_TOKEN")
+	domain := flag.String("domain", os.Getenv("DOMAIN"), "the api domain, default is the produced host")
+	pkg := flag.String("package", "model", "the package name of the generated file")
+	output := flag.String("o", "", "the output file, default is stdout")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: vika-gen [flags] datasheetId[:StructName]...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 || *token == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	credential := common.NewCredential(*token)
+	cpf := profile.NewClientProfile()
+	cpf.HttpProfile.Domain = *domain
+	tables := make([]*codegen.Table, 0, flag.NArg())
+	for _, arg := range flag.Args() {
+		datasheetId, name := arg, ""
+		if i := strings.Index(arg, ":"); i >= 0 {
+			datasheetId, name = arg[:i], arg[i+1:]
+		}
+		if name == "" {
+			name = datasheetId
+		}
+		datasheet, _ := apitable.NewDatasheet(credential, datasheetId, cpf)
+		fields, err := datasheet.DescribeFields(nil)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "vika-gen: describe fields of %s: %s\n", datasheetId, err)
+			os.Exit(1)
+		}
+		tables = append(tables, &codegen.Table{Name: name, DatasheetId: datasheetId, Fields: fields})
+	}
+	codegen.SortTables(tables)
+
+	src, err := codegen.Generate(&codegen.Options{
+		Package: *pkg,
+		Command: "vika-gen",
+	}, tables)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "vika-gen: %s\n", err)
+		os.Exit(1)
+	}
+	if *output == "" {
+		_, _ = os.Stdout.Write(src)
+		return
+	}
+	if err = ioutil.WriteFile(*output, src, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "vika-gen: %s\n", err)
+		os.Exit(1)
+	}
+}
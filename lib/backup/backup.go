@@ -0,0 +1,289 @@
+// Package backup provides the full space backup to a tar.gz archive and the restore from it
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	aterror "github.com/apitable/apitable-sdks/apitable.go/lib/common/error"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/datasheet"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/space"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// ManifestVersion the version of the archive format
+const ManifestVersion = 1
+
+// ManifestFile the path of the manifest in the archive
+const ManifestFile = "manifest.json"
+
+// DatasheetEntry the datasheet saved in the archive
+type DatasheetEntry struct {
+	// such as: `dst*****`
+	Id string `json:"id"`
+	// the datasheet name
+	Name string `json:"name"`
+	// the folder path of the datasheet, such as: Sales/2024/Leads
+	Path string `json:"path"`
+	// the count of the records
+	Records int `json:"records"`
+	// the path of the schema file in the archive, see datasheet.Schema
+	SchemaFile string `json:"schemaFile"`
+	// the path of the records file in the archive
+	RecordsFile string `json:"recordsFile"`
+}
+
+// AttachmentEntry the attachment saved in the archive
+type AttachmentEntry struct {
+	// the attachment token
+	Token string `json:"token"`
+	// the original file name
+	Name string `json:"name"`
+	// such as: image/jpeg
+	MimeType string `json:"mimeType,omitempty"`
+	// the size of the file
+	Size int64 `json:"size"`
+	// the path of the file in the archive
+	File string `json:"file"`
+}
+
+// Manifest describe the content of the archive
+type Manifest struct {
+	// the version of the archive format
+	Version int `json:"version"`
+	// the backed up space. such as: `spc*****`
+	SpaceId string `json:"spaceId"`
+	// the time of the backup. such as: timestamp
+	CreatedAt   int64              `json:"createdAt"`
+	Datasheets  []*DatasheetEntry  `json:"datasheets"`
+	Attachments []*AttachmentEntry `json:"attachments"`
+	// the sha256 of the files by the path in the archive
+	Checksums map[string]string `json:"checksums"`
+	// the problems found during the backup, such as the attachments failed to download
+	Problems []string `json:"problems"`
+}
+
+// Options the options of the backup
+type Options struct {
+	// don't download the attachments
+	SkipAttachments bool
+	// the http client to download the attachments, the default is http.DefaultClient
+	HTTPClient *http.Client
+	// called when a datasheet starts to back up. required: no.
+	Progress func(entry *DatasheetEntry)
+}
+
+// archiveWriter write the files to the tar and record the checksums
+type archiveWriter struct {
+	tw        *tar.Writer
+	checksums map[string]string
+	modTime   time.Time
+}
+
+func (a *archiveWriter) add(name string, size int64, r io.Reader) error {
+	header := &tar.Header{Name: name, Mode: 0644, Size: size, ModTime: a.modTime, Typeflag: tar.TypeReg}
+	if err := a.tw.WriteHeader(header); err != nil {
+		return err
+	}
+	hash := sha256.New()
+	if _, err := io.Copy(a.tw, io.TeeReader(r, hash)); err != nil {
+		return err
+	}
+	a.checksums[name] = hex.EncodeToString(hash.Sum(nil))
+	return nil
+}
+
+func (a *archiveWriter) addJson(name string, value interface{}) error {
+	b, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	return a.add(name, int64(len(b)), strings.NewReader(string(b)))
+}
+
+// Backup walk the space, and write the schema, records and attachments of all datasheets to a tar.gz archive.
+//
+// * the archive contains the manifest with the checksums of the files, see Manifest.
+// * the attachments failed to download are recorded in Manifest.Problems, the backup goes on.
+func Backup(s *space.Space, w io.Writer, options *Options) (manifest *Manifest, err error) {
+	opts := Options{}
+	if options != nil {
+		opts = *options
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = http.DefaultClient
+	}
+	nodes := []*space.NodeInfo{}
+	err = s.WalkNodes(func(node *space.NodeInfo) error {
+		if node.Type != nil && *node.Type == space.NodeType_Datasheet {
+			nodes = append(nodes, node)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	manifest = &Manifest{
+		Version:     ManifestVersion,
+		SpaceId:     s.SpaceId,
+		CreatedAt:   now.UnixNano() / int64(time.Millisecond),
+		Datasheets:  []*DatasheetEntry{},
+		Attachments: []*AttachmentEntry{},
+		Problems:    []string{},
+	}
+	gz := gzip.NewWriter(w)
+	archive := &archiveWriter{tw: tar.NewWriter(gz), checksums: map[string]string{}, modTime: now}
+	downloaded := map[string]bool{}
+	for _, node := range nodes {
+		entry := &DatasheetEntry{
+			Id:          *node.Id,
+			Path:        node.Path,
+			SchemaFile:  path.Join("datasheets", *node.Id, "schema.json"),
+			RecordsFile: path.Join("datasheets", *node.Id, "records.json"),
+		}
+		if node.Name != nil {
+			entry.Name = *node.Name
+		}
+		if opts.Progress != nil {
+			opts.Progress(entry)
+		}
+		dst := s.Datasheet(entry.Id)
+		schema, err := dst.DescribeSchema()
+		if err != nil {
+			return nil, err
+		}
+		records, err := dst.DescribeAllRecords(nil)
+		if err != nil {
+			return nil, err
+		}
+		entry.Records = len(records)
+		if err = archive.addJson(entry.SchemaFile, schema); err != nil {
+			return nil, err
+		}
+		if err = archive.addJson(entry.RecordsFile, records); err != nil {
+			return nil, err
+		}
+		manifest.Datasheets = append(manifest.Datasheets, entry)
+		if opts.SkipAttachments {
+			continue
+		}
+		for _, attachment := range attachments(schema.Fields, records) {
+			token := *attachment.Token
+			if downloaded[token] {
+				continue
+			}
+			downloaded[token] = true
+			saved, problem, err := addAttachment(archive, opts.HTTPClient, attachment)
+			if err != nil {
+				return nil, err
+			}
+			if problem != nil {
+				manifest.Problems = append(manifest.Problems, fmt.Sprintf("attachment %s of %s: %s", token, entry.Path, problem))
+				continue
+			}
+			manifest.Attachments = append(manifest.Attachments, saved)
+		}
+	}
+	manifest.Checksums = archive.checksums
+	if err = archive.addJson(ManifestFile, manifest); err != nil {
+		return nil, err
+	}
+	if err = archive.tw.Close(); err != nil {
+		return nil, err
+	}
+	if err = gz.Close(); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// attachments get the attachments of the attachment fields
+func attachments(fields []*datasheet.DatasheetField, records []*datasheet.Record) []*datasheet.Attachment {
+	result := []*datasheet.Attachment{}
+	for _, field := range fields {
+		if field.Type == nil || *field.Type != datasheet.FieldType_Attachment || field.Name == nil {
+			continue
+		}
+		for _, record := range records {
+			for _, attachment := range datasheet.RecordAttachments(record, *field.Name) {
+				if attachment.Token != nil && attachment.Url != nil {
+					result = append(result, attachment)
+				}
+			}
+		}
+	}
+	return result
+}
+
+// safeName remove the path separators of the file name
+func safeName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r < ' ' {
+			return '_'
+		}
+		return r
+	}, name)
+	if name == "" || name == "." || name == ".." {
+		return "file"
+	}
+	return name
+}
+
+// fetch download the url to a temporary file, as the tar header requires the size.
+// the caller closes and removes the file.
+func fetch(client *http.Client, url string) (file *os.File, size int64, err error) {
+	response, err := client.Get(url)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("unexpected status %s", response.Status)
+	}
+	if file, err = ioutil.TempFile("", "vika-backup"); err != nil {
+		return nil, 0, err
+	}
+	if size, err = io.Copy(file, response.Body); err == nil {
+		_, err = file.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return nil, 0, err
+	}
+	return file, size, nil
+}
+
+// addAttachment download the attachment and add it to the archive.
+// the download errors are returned as problems, the archive errors break the backup.
+func addAttachment(archive *archiveWriter, client *http.Client, attachment *datasheet.Attachment) (entry *AttachmentEntry, problem error, err error) {
+	file, size, problem := fetch(client, *attachment.Url)
+	if problem != nil {
+		return nil, problem, nil
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+	entry = &AttachmentEntry{Token: *attachment.Token, Size: size}
+	if attachment.Name != nil {
+		entry.Name = *attachment.Name
+	}
+	if attachment.MimeType != nil {
+		entry.MimeType = *attachment.MimeType
+	}
+	entry.File = path.Join("attachments", safeName(entry.Token), safeName(entry.Name))
+	if err = archive.add(entry.File, size, file); err != nil {
+		msg := fmt.Sprintf("Fail to write archive because %s", err)
+		return nil, nil, aterror.NewSDKError(500, msg, "ClientError.FileWriteError")
+	}
+	return entry, nil, nil
+}
@@ -0,0 +1,500 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/common"
+	aterror "github.com/apitable/apitable-sdks/apitable.go/lib/common/error"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/datasheet"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/export"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/space"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// RestoreOptions the options of the restore
+type RestoreOptions struct {
+	// the folder to create the datasheets in, the default is the root folder. such as: `fod*****`
+	FolderId string
+	// restore the datasheets by the source datasheet ids only. required: no.
+	Datasheets []string
+	// called when a datasheet starts to restore. required: no.
+	Progress func(entry *DatasheetEntry)
+}
+
+// RestoreReport the result of the restore
+type RestoreReport struct {
+	// the created datasheet ids by the source datasheet ids
+	Datasheets map[string]string `json:"datasheets"`
+	// the created record ids by the source record ids
+	Records map[string]string `json:"records"`
+	// the count of the uploaded attachments
+	Attachments int `json:"attachments"`
+	// the things not restored, such as the formula fields and the member values of another space
+	Problems []string `json:"problems"`
+}
+
+func (r *RestoreReport) problem(format string, args ...interface{}) {
+	r.Problems = append(r.Problems, fmt.Sprintf(format, args...))
+}
+
+// restoring the datasheet being restored
+type restoring struct {
+	entry   *DatasheetEntry
+	schema  *datasheet.Schema
+	records []*datasheet.Record
+	target  *datasheet.Datasheet
+	// the restored fields by name
+	fields map[string]*datasheet.DatasheetField
+	// the source fields restored as SingleText by name, the values are rendered as text
+	downgraded map[string]*datasheet.DatasheetField
+	// the link fields created by the restore, the link values are written from these fields
+	links []*datasheet.DatasheetField
+}
+
+// extract unpack the archive to the directory, and verify the checksums of the manifest.
+func extract(r io.Reader, dir string) (*Manifest, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, invalidArchive(err.Error())
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	checksums := map[string]string{}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, invalidArchive(err.Error())
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := path.Clean(header.Name)
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return nil, invalidArchive("unsafe path " + header.Name)
+		}
+		filePath := filepath.Join(dir, filepath.FromSlash(name))
+		if err = os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			return nil, err
+		}
+		f, err := os.Create(filePath)
+		if err != nil {
+			return nil, err
+		}
+		hash := sha256.New()
+		_, err = io.Copy(io.MultiWriter(f, hash), tr)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return nil, invalidArchive(err.Error())
+		}
+		checksums[name] = hex.EncodeToString(hash.Sum(nil))
+	}
+	manifest := &Manifest{}
+	if err = readJson(dir, ManifestFile, manifest); err != nil {
+		return nil, err
+	}
+	if manifest.Version != ManifestVersion {
+		return nil, invalidArchive(fmt.Sprintf("unsupported version %d", manifest.Version))
+	}
+	for name, checksum := range manifest.Checksums {
+		if checksums[name] != checksum {
+			msg := fmt.Sprintf("Checksum mismatch of %s in the archive", name)
+			return nil, aterror.NewSDKError(500, msg, "ClientError.ChecksumMismatch")
+		}
+	}
+	return manifest, nil
+}
+
+func invalidArchive(reason string) error {
+	msg := fmt.Sprintf("Invalid backup archive: %s", reason)
+	return aterror.NewSDKError(400, msg, "ClientError.InvalidArchive")
+}
+
+func readJson(dir string, name string, value interface{}) error {
+	b, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+	if err != nil {
+		return invalidArchive(err.Error())
+	}
+	if err = json.Unmarshal(b, value); err != nil {
+		return invalidArchive(fmt.Sprintf("%s: %s", name, err))
+	}
+	return nil
+}
+
+// Verify read the archive, and check the checksums of all files in the manifest.
+func Verify(r io.Reader) (*Manifest, error) {
+	dir, err := ioutil.TempDir("", "vika-restore")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	return extract(r, dir)
+}
+
+// Restore recreate the datasheets, fields and records of the archive in the target space.
+//
+// * the datasheets are created in FolderId, the folder structure is not restored.
+// * the attachments are uploaded again, and the link values are rewritten to the new record ids.
+// * the computed fields, such as formula and lookup, can't be created by api, they're reported in Problems.
+// * the member values are kept only if the target space is the backed up space.
+func Restore(target *space.Space, r io.Reader, options *RestoreOptions) (report *RestoreReport, err error) {
+	opts := RestoreOptions{}
+	if options != nil {
+		opts = *options
+	}
+	dir, err := ioutil.TempDir("", "vika-restore")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	manifest, err := extract(r, dir)
+	if err != nil {
+		return nil, err
+	}
+	selected := map[string]bool{}
+	for _, id := range opts.Datasheets {
+		selected[id] = true
+	}
+	report = &RestoreReport{Datasheets: map[string]string{}, Records: map[string]string{}, Problems: []string{}}
+	sheets := []*restoring{}
+	for _, entry := range manifest.Datasheets {
+		if len(selected) > 0 && !selected[entry.Id] {
+			continue
+		}
+		sheet := &restoring{entry: entry, schema: &datasheet.Schema{}, fields: map[string]*datasheet.DatasheetField{}, downgraded: map[string]*datasheet.DatasheetField{}}
+		if err = readJson(dir, entry.SchemaFile, sheet.schema); err != nil {
+			return nil, err
+		}
+		if err = readJson(dir, entry.RecordsFile, &sheet.records); err != nil {
+			return nil, err
+		}
+		sheets = append(sheets, sheet)
+	}
+	for _, sheet := range sheets {
+		if opts.Progress != nil {
+			opts.Progress(sheet.entry)
+		}
+		if err = createDatasheet(target, sheet, opts.FolderId, report); err != nil {
+			return report, err
+		}
+	}
+	// the link fields require the foreign datasheets to be created first.
+	byId := map[string]*restoring{}
+	for _, sheet := range sheets {
+		byId[sheet.entry.Id] = sheet
+	}
+	brothers := map[string]bool{}
+	for _, sheet := range sheets {
+		if err = createLinkFields(sheet, byId, brothers, report); err != nil {
+			return report, err
+		}
+	}
+	uploader := &uploader{dir: dir, attachments: map[string]*AttachmentEntry{}, uploaded: map[string]*datasheet.AttachmentValue{}}
+	for _, attachment := range manifest.Attachments {
+		uploader.attachments[attachment.Token] = attachment
+	}
+	sameSpace := manifest.SpaceId == target.SpaceId
+	for _, sheet := range sheets {
+		if err = createRecords(sheet, uploader, sameSpace, report); err != nil {
+			return report, err
+		}
+	}
+	report.Attachments = len(uploader.uploaded)
+	for _, sheet := range sheets {
+		if err = writeLinks(sheet, report); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// restoreProperty get the typed property to create the field, false if the field can't be created.
+func restoreProperty(field *datasheet.DatasheetField) (property interface{}, ok bool) {
+	fieldType := *field.Type
+	switch fieldType {
+	case datasheet.FieldType_Text, datasheet.FieldType_URL, datasheet.FieldType_Phone, datasheet.FieldType_Attachment:
+		return nil, true
+	}
+	if field.Property == nil {
+		return nil, fieldType == datasheet.FieldType_SingleText
+	}
+	switch fieldType {
+	case datasheet.FieldType_SingleText:
+		return field.SingleTextFieldProperty(), true
+	case datasheet.FieldType_SingleSelect, datasheet.FieldType_MultiSelect:
+		p := field.SelectFieldProperty()
+		if p == nil {
+			return nil, false
+		}
+		// the option ids are generated by the target datasheet
+		options := []*datasheet.SelectFieldOption{}
+		for _, option := range p.Options {
+			options = append(options, &datasheet.SelectFieldOption{Name: option.Name, Color: option.Color})
+		}
+		return &datasheet.SelectFieldProperty{Options: options}, true
+	case datasheet.FieldType_Number:
+		return field.NumberFieldProperty(), true
+	case datasheet.FieldType_Currency:
+		return field.CurrencyFieldProperty(), true
+	case datasheet.FieldType_Percent:
+		return field.PercentFieldProperty(), true
+	case datasheet.FieldType_DateTime:
+		return field.DateTimeFieldProperty(), true
+	case datasheet.FieldType_Checkbox:
+		return field.CheckboxFieldProperty(), true
+	case datasheet.FieldType_Rating:
+		return field.RatingFieldProperty(), true
+	case datasheet.FieldType_Member:
+		p := field.MemberFieldProperty()
+		if p == nil {
+			return nil, false
+		}
+		// the member options are the members of the source space
+		return &datasheet.MemberFieldProperty{IsMulti: p.IsMulti, ShouldSendMsg: p.ShouldSendMsg}, true
+	}
+	return nil, false
+}
+
+func createDatasheet(target *space.Space, sheet *restoring, folderId string, report *RestoreReport) error {
+	fields := []*space.DatasheetFieldSchema{}
+	for i, field := range sheet.schema.Fields {
+		if field.Type == nil || field.Name == nil {
+			continue
+		}
+		if *field.Type == datasheet.FieldType_MagicLink {
+			continue
+		}
+		property, ok := restoreProperty(field)
+		if ok && property != nil && datasheet.ValidateFieldProperty(*field.Type, property) != nil {
+			ok = false
+		}
+		fieldType := *field.Type
+		if !ok {
+			if i > 0 {
+				report.problem("field %s of %s is not restored: unsupported type %s", *field.Name, sheet.entry.Path, fieldType)
+				continue
+			}
+			// the primary field is required, restore it as text.
+			report.problem("primary field %s of %s is restored as SingleText instead of %s", *field.Name, sheet.entry.Path, fieldType)
+			fieldType, property = datasheet.FieldType_SingleText, nil
+			sheet.downgraded[*field.Name] = field
+		}
+		restored := fieldType
+		fields = append(fields, &space.DatasheetFieldSchema{Type: &restored, Name: field.Name, Property: property})
+		sheet.fields[*field.Name] = &datasheet.DatasheetField{Id: field.Id, Name: field.Name, Type: &restored, Property: field.Property}
+	}
+	dst, err := target.CreateDatasheet(sheet.entry.Name, folderId, fields, "")
+	if err != nil {
+		return err
+	}
+	sheet.target = dst
+	report.Datasheets[sheet.entry.Id] = dst.DatasheetId
+	return nil
+}
+
+// createLinkFields create the link fields to the restored datasheets.
+// creating a link field creates its brother field in the foreign datasheet, so only one field of the pair is created.
+func createLinkFields(sheet *restoring, byId map[string]*restoring, brothers map[string]bool, report *RestoreReport) error {
+	for _, field := range sheet.schema.Fields {
+		if field.Type == nil || *field.Type != datasheet.FieldType_MagicLink || field.Name == nil || field.Id == nil {
+			continue
+		}
+		if brothers[*field.Id] {
+			continue
+		}
+		var property *datasheet.MagicLinkFieldProperty
+		if field.Property != nil {
+			property = field.MagicLinkFieldProperty()
+		}
+		if property == nil || property.ForeignDatasheetId == nil {
+			report.problem("link field %s of %s is not restored: the property is missing", *field.Name, sheet.entry.Path)
+			continue
+		}
+		foreign, ok := byId[*property.ForeignDatasheetId]
+		if !ok {
+			report.problem("link field %s of %s is not restored: the datasheet %s is not in the archive", *field.Name, sheet.entry.Path, *property.ForeignDatasheetId)
+			continue
+		}
+		request := datasheet.NewCreateFieldRequest()
+		fieldType := datasheet.FieldType_MagicLink
+		request.Type = &fieldType
+		request.Name = field.Name
+		request.Property = &datasheet.MagicLinkFieldProperty{
+			ForeignDatasheetId: common.StringPtr(foreign.target.DatasheetId),
+			LimitSingleRecord:  property.LimitSingleRecord,
+		}
+		if _, err := sheet.target.CreateField(request); err != nil {
+			return err
+		}
+		sheet.links = append(sheet.links, field)
+		if property.BrotherFieldId != nil && *property.BrotherFieldId != "" {
+			brothers[*property.BrotherFieldId] = true
+			report.problem("link field %s of %s is recreated as the brother field of %s, its name may differ", *property.BrotherFieldId, foreign.entry.Path, *field.Name)
+		}
+	}
+	return nil
+}
+
+// uploader upload the attachments of the archive once per token
+type uploader struct {
+	dir         string
+	attachments map[string]*AttachmentEntry
+	uploaded    map[string]*datasheet.AttachmentValue
+}
+
+func (u *uploader) upload(dst *datasheet.Datasheet, token string) (*datasheet.AttachmentValue, error) {
+	if value, ok := u.uploaded[token]; ok {
+		return value, nil
+	}
+	entry, ok := u.attachments[token]
+	if !ok {
+		return nil, fmt.Errorf("not in the archive")
+	}
+	request := datasheet.NewUploadRequest()
+	request.FilePath = filepath.Join(u.dir, filepath.FromSlash(entry.File))
+	attachment, err := dst.UploadFile(request)
+	if err != nil {
+		return nil, err
+	}
+	value := &datasheet.AttachmentValue{Name: entry.Name}
+	if attachment != nil && attachment.Token != nil {
+		value.Token = *attachment.Token
+	}
+	u.uploaded[token] = value
+	return value, nil
+}
+
+func createRecords(sheet *restoring, uploader *uploader, sameSpace bool, report *RestoreReport) error {
+	names := make([]string, 0, len(sheet.fields))
+	for name := range sheet.fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	droppedMembers := map[string]bool{}
+	renderer := &export.Renderer{}
+	for start := 0; start < len(sheet.records); start += common.MaxWriteRecords {
+		end := start + common.MaxWriteRecords
+		if end > len(sheet.records) {
+			end = len(sheet.records)
+		}
+		batch := sheet.records[start:end]
+		request := datasheet.NewCreateRecordsRequest()
+		for _, record := range batch {
+			fields := datasheet.Field{}
+			if record.BaseRecord != nil && record.Fields != nil {
+				for _, name := range names {
+					value, ok := (*record.Fields)[name]
+					if !ok || value == nil {
+						continue
+					}
+					switch *sheet.fields[name].Type {
+					case datasheet.FieldType_SingleText:
+						if source, ok := sheet.downgraded[name]; ok {
+							value = renderer.Render(source, value)
+						}
+					case datasheet.FieldType_Attachment:
+						values := []*datasheet.AttachmentValue{}
+						for _, attachment := range datasheet.RecordAttachments(record, name) {
+							if attachment.Token == nil {
+								continue
+							}
+							uploaded, err := uploader.upload(sheet.target, *attachment.Token)
+							if err != nil {
+								report.problem("attachment %s of %s is not restored: %s", *attachment.Token, sheet.entry.Path, err)
+								continue
+							}
+							values = append(values, uploaded)
+						}
+						value = values
+					case datasheet.FieldType_Member:
+						if !sameSpace {
+							if !droppedMembers[name] {
+								droppedMembers[name] = true
+								report.problem("member values of %s in %s are not restored: the members belong to another space", name, sheet.entry.Path)
+							}
+							continue
+						}
+					}
+					fields[name] = value
+				}
+			}
+			request.Records = append(request.Records, &datasheet.Fields{Fields: &fields})
+		}
+		created, err := sheet.target.CreateRecords(request)
+		if err != nil {
+			return err
+		}
+		for i, record := range created {
+			if i >= len(batch) {
+				break
+			}
+			source := batch[i]
+			if source.BaseRecord != nil && source.RecordId != nil && record.BaseRecord != nil && record.RecordId != nil {
+				report.Records[*source.RecordId] = *record.RecordId
+			}
+		}
+	}
+	return nil
+}
+
+// writeLinks write the link values with the new record ids
+func writeLinks(sheet *restoring, report *RestoreReport) error {
+	if len(sheet.links) == 0 {
+		return nil
+	}
+	updates := []*datasheet.BaseRecord{}
+	for _, record := range sheet.records {
+		if record.BaseRecord == nil || record.RecordId == nil || record.Fields == nil {
+			continue
+		}
+		recordId, ok := report.Records[*record.RecordId]
+		if !ok {
+			continue
+		}
+		fields := datasheet.Field{}
+		for _, field := range sheet.links {
+			values, _ := (*record.Fields)[*field.Name].([]interface{})
+			linked := []string{}
+			for _, value := range values {
+				id, _ := value.(string)
+				if newId, ok := report.Records[id]; ok {
+					linked = append(linked, newId)
+				} else {
+					report.problem("link %s of record %s in %s is not restored: the record is not restored", id, *record.RecordId, sheet.entry.Path)
+				}
+			}
+			if len(linked) > 0 {
+				fields[*field.Name] = linked
+			}
+		}
+		if len(fields) > 0 {
+			updates = append(updates, &datasheet.BaseRecord{RecordId: common.StringPtr(recordId), Fields: &fields})
+		}
+	}
+	for start := 0; start < len(updates); start += common.MaxWriteRecords {
+		end := start + common.MaxWriteRecords
+		if end > len(updates) {
+			end = len(updates)
+		}
+		request := datasheet.NewModifyRecordsRequest()
+		request.Records = updates[start:end]
+		if _, err := sheet.target.ModifyRecords(request); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,375 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/common"
+	aterror "github.com/apitable/apitable-sdks/apitable.go/lib/common/error"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/datasheet"
+	"strings"
+	"sync"
+	"time"
+)
+
+// the default interval to reconcile the record ids to detect deletions
+const defaultReconcileInterval = 10 * time.Minute
+
+// the default overlap of the incremental refresh, to tolerate the clock skew and the same millisecond updates
+const defaultOverlap = time.Second
+
+// FormulaBuilder build the formula to query the records modified since the time
+type FormulaBuilder func(field string, since time.Time) string
+
+// ModifiedSinceFormula the default formula to query the records modified since the time, such as:
+// IS_AFTER({Last Modified}, DATETIME_PARSE("2024-01-01T00:00:00.000Z"))
+func ModifiedSinceFormula(field string, since time.Time) string {
+	return fmt.Sprintf(`IS_AFTER({%s}, DATETIME_PARSE("%s"))`, field, since.UTC().Format("2006-01-02T15:04:05.000Z"))
+}
+
+// Options the options of the cache
+type Options struct {
+	// the record storage, the default is the memory store
+	Store Store
+	// the name or id of the LastModifiedTime field, required for the incremental refresh.
+	// if it's empty, all records are loaded on every refresh. the id is resolved to the name once by `DescribeFields`.
+	ModifiedTimeField string
+	// the interval to reconcile all record ids to detect deletions, the default is 10 minutes
+	ReconcileInterval time.Duration
+	// the overlap of the incremental refresh, the default is 1 second
+	Overlap time.Duration
+	// filter by view. value such as: viw*****. required: no.
+	ViewId string
+	// the formula to query modified records, the default is ModifiedSinceFormula
+	Formula FormulaBuilder
+}
+
+// Metrics the statistics of the cache
+type Metrics struct {
+	// the last time the records are refreshed successfully
+	LastRefresh time.Time
+	// the last time the record ids are reconciled
+	LastReconcile time.Time
+	// the count of the successful refreshes
+	Refreshes int64
+	// the count of the failed refreshes
+	Failures int64
+	// the count of the records updated by refreshes
+	Updated int64
+	// the count of the records deleted by reconciliation
+	Deleted int64
+	// the count of GetRecord found the record
+	Hits int64
+	// the count of GetRecord not found the record
+	Misses int64
+}
+
+// Staleness the duration since the last successful refresh
+func (m Metrics) Staleness() time.Duration {
+	if m.LastRefresh.IsZero() {
+		return 0
+	}
+	return time.Since(m.LastRefresh)
+}
+
+// RefreshResult the changes of one refresh
+type RefreshResult struct {
+	// whether all records are loaded
+	Full bool
+	// the count of the records inserted or updated
+	Updated int
+	// the ids of the deleted records
+	Deleted []string
+}
+
+// Cache the local record cache of the datasheet, refreshed incrementally by the modification time.
+type Cache struct {
+	datasheet *datasheet.Datasheet
+	options   Options
+	// serialize the refreshes
+	refreshMu sync.Mutex
+	mu        sync.Mutex
+	metrics   Metrics
+	// the name of ModifiedTimeField, the records are keyed by the field name
+	timeField string
+}
+
+// NewCache init cache instance, call Refresh to load the records
+func NewCache(dst *datasheet.Datasheet, options *Options) *Cache {
+	c := &Cache{datasheet: dst}
+	if options != nil {
+		c.options = *options
+	}
+	if c.options.Store == nil {
+		c.options.Store = NewMemoryStore()
+	}
+	if c.options.ReconcileInterval <= 0 {
+		c.options.ReconcileInterval = defaultReconcileInterval
+	}
+	if c.options.Overlap <= 0 {
+		c.options.Overlap = defaultOverlap
+	}
+	if c.options.Formula == nil {
+		c.options.Formula = ModifiedSinceFormula
+	}
+	if !strings.HasPrefix(c.options.ModifiedTimeField, "fld") {
+		c.timeField = c.options.ModifiedTimeField
+	}
+	return c
+}
+
+// resolveTimeField resolve the field id of ModifiedTimeField to the field name once
+func (c *Cache) resolveTimeField() error {
+	if c.timeField != "" || c.options.ModifiedTimeField == "" {
+		return nil
+	}
+	mapper, err := datasheet.NewFieldMapper(c.datasheet, false)
+	if err != nil {
+		return err
+	}
+	name, err := mapper.FieldName(c.options.ModifiedTimeField)
+	if err != nil {
+		return err
+	}
+	c.timeField = name
+	return nil
+}
+
+// Store get the record storage
+func (c *Cache) Store() Store {
+	return c.options.Store
+}
+
+func (c *Cache) newRequest() *datasheet.DescribeRecordRequest {
+	request := datasheet.NewDescribeRecordRequest()
+	if c.options.ViewId != "" {
+		request.ViewId = common.StringPtr(c.options.ViewId)
+	}
+	return request
+}
+
+// modifiedTime get the modification time of the record, 0 if unknown.
+func (c *Cache) modifiedTime(record *datasheet.Record) int64 {
+	if c.timeField == "" || record.Fields == nil {
+		return 0
+	}
+	switch v := (*record.Fields)[c.timeField].(type) {
+	case float64:
+		return int64(v)
+	case int64:
+		return v
+	case string:
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			return t.UnixNano() / int64(time.Millisecond)
+		}
+	}
+	return 0
+}
+
+func (c *Cache) maxModifiedTime(records []*datasheet.Record, cursor int64) int64 {
+	for _, record := range records {
+		if t := c.modifiedTime(record); t > cursor {
+			cursor = t
+		}
+	}
+	return cursor
+}
+
+// Refresh update the cache from the datasheet
+//
+// * the first refresh, or the refresh without ModifiedTimeField, loads all records.
+// * the others load the records modified since the last refresh by `FilterByFormula`.
+// * the record ids are reconciled on ReconcileInterval to detect deletions.
+func (c *Cache) Refresh() (result *RefreshResult, err error) {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+	result, err = c.refresh(false)
+	c.record(result, err)
+	return
+}
+
+// Reload drop the cursor and load all records
+func (c *Cache) Reload() (result *RefreshResult, err error) {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+	result, err = c.refresh(true)
+	c.record(result, err)
+	return
+}
+
+// record update the metrics by the result of the refresh
+func (c *Cache) record(result *RefreshResult, err error) {
+	c.mu.Lock()
+	if err != nil {
+		c.metrics.Failures++
+	} else {
+		c.metrics.Refreshes++
+		c.metrics.LastRefresh = time.Now()
+		c.metrics.Updated += int64(result.Updated)
+		c.metrics.Deleted += int64(len(result.Deleted))
+	}
+	c.mu.Unlock()
+}
+
+func (c *Cache) refresh(full bool) (result *RefreshResult, err error) {
+	if err = c.resolveTimeField(); err != nil {
+		return nil, err
+	}
+	store := c.options.Store
+	meta, err := store.Meta()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	reconciled := false
+	result = &RefreshResult{}
+	if full || meta.Cursor == 0 || c.timeField == "" {
+		records, err := c.datasheet.DescribeAllRecords(c.newRequest())
+		if err != nil {
+			return nil, err
+		}
+		if result.Deleted, err = c.deleteMissing(records); err != nil {
+			return nil, err
+		}
+		if err = store.Put(records); err != nil {
+			return nil, err
+		}
+		result.Full = true
+		result.Updated = len(records)
+		meta.Cursor = c.maxModifiedTime(records, 0)
+		reconciled = true
+	} else {
+		since := time.Unix(0, meta.Cursor*int64(time.Millisecond)).Add(-c.options.Overlap)
+		request := c.newRequest()
+		request.FilterByFormula = common.StringPtr(c.options.Formula(c.timeField, since))
+		records, err := c.datasheet.DescribeAllRecords(request)
+		if err != nil {
+			return nil, err
+		}
+		if err = store.Put(records); err != nil {
+			return nil, err
+		}
+		result.Updated = len(records)
+		meta.Cursor = c.maxModifiedTime(records, meta.Cursor)
+		reconciledAt := time.Unix(0, meta.ReconciledAt*int64(time.Millisecond))
+		if now.Sub(reconciledAt) >= c.options.ReconcileInterval {
+			if result.Deleted, err = c.reconcile(); err != nil {
+				return nil, err
+			}
+			reconciled = true
+		}
+	}
+	if reconciled {
+		meta.ReconciledAt = now.UnixNano() / int64(time.Millisecond)
+	}
+	if err = store.SetMeta(meta); err != nil {
+		return nil, err
+	}
+	if err = store.Flush(); err != nil {
+		return nil, err
+	}
+	if reconciled {
+		c.mu.Lock()
+		c.metrics.LastReconcile = now
+		c.mu.Unlock()
+	}
+	return result, nil
+}
+
+// reconcile query all record ids, and delete the cached records not found.
+func (c *Cache) reconcile() (deleted []string, err error) {
+	request := c.newRequest()
+	if c.timeField != "" {
+		// only query one field to reduce the response size.
+		request.Fields = common.StringPtrs([]string{c.timeField})
+	}
+	records, err := c.datasheet.DescribeAllRecords(request)
+	if err != nil {
+		return nil, err
+	}
+	return c.deleteMissing(records)
+}
+
+func (c *Cache) deleteMissing(records []*datasheet.Record) (deleted []string, err error) {
+	remote := make(map[string]bool, len(records))
+	for _, record := range records {
+		if record.BaseRecord != nil && record.RecordId != nil {
+			remote[*record.RecordId] = true
+		}
+	}
+	ids, err := c.options.Store.Ids()
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		if !remote[id] {
+			deleted = append(deleted, id)
+		}
+	}
+	if len(deleted) > 0 {
+		err = c.options.Store.Delete(deleted)
+	}
+	return
+}
+
+// Run refresh the cache on every interval until the context is done, the errors are passed to onError if not nil.
+func (c *Cache) Run(ctx context.Context, interval time.Duration, onError func(err error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := c.Refresh(); err != nil && onError != nil {
+			onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// GetRecord get the cached record by record id, return an error with code 404 if not found.
+func (c *Cache) GetRecord(recordId string) (*datasheet.Record, error) {
+	record, ok, err := c.options.Store.Get(recordId)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	if ok {
+		c.metrics.Hits++
+	} else {
+		c.metrics.Misses++
+	}
+	c.mu.Unlock()
+	if !ok {
+		msg := fmt.Sprintf("Record not found: %s", recordId)
+		return nil, aterror.NewSDKError(404, msg, "ClientError.RecordNotFound")
+	}
+	return record, nil
+}
+
+// Scan get the cached records matching the filter, all records are returned if the filter is nil.
+func (c *Cache) Scan(filter func(record *datasheet.Record) bool) (records []*datasheet.Record, err error) {
+	records = []*datasheet.Record{}
+	err = c.options.Store.Range(func(record *datasheet.Record) bool {
+		if filter == nil || filter(record) {
+			records = append(records, record)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// Count the count of the cached records
+func (c *Cache) Count() (int, error) {
+	return c.options.Store.Count()
+}
+
+// Metrics get the statistics of the cache
+func (c *Cache) Metrics() Metrics {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.metrics
+}
@@ -0,0 +1,235 @@
+// Package cache provides the local persistent record cache of the datasheet
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	aterror "github.com/apitable/apitable-sdks/apitable.go/lib/common/error"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/datasheet"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+func init() {
+	// the cell values are decoded from json, register the composite types for gob.
+	gob.Register(map[string]interface{}{})
+	gob.Register([]interface{}{})
+}
+
+// Meta the sync state saved with the records
+type Meta struct {
+	// the max modification time of the cached records. such as: timestamp
+	Cursor int64 `json:"cursor"`
+	// the last time all records or record ids are loaded. such as: timestamp
+	ReconciledAt int64 `json:"reconciledAt"`
+}
+
+// Store the storage of the cached records
+type Store interface {
+	// Get get the record by record id
+	Get(recordId string) (*datasheet.Record, bool, error)
+	// Put insert or replace the records
+	Put(records []*datasheet.Record) error
+	// Delete remove the records by record id
+	Delete(recordIds []string) error
+	// Range call fn for each record in record id order, stop when fn returns false
+	Range(fn func(record *datasheet.Record) bool) error
+	// Count the count of the records
+	Count() (int, error)
+	// Ids all record ids
+	Ids() ([]string, error)
+	// Meta get the sync state
+	Meta() (*Meta, error)
+	// SetMeta save the sync state
+	SetMeta(meta *Meta) error
+	// Flush persist the changes
+	Flush() error
+}
+
+// MemoryStore the store keeping records in memory
+type MemoryStore struct {
+	mu      sync.RWMutex
+	records map[string]*datasheet.Record
+	meta    Meta
+}
+
+// NewMemoryStore init memory store instance
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: map[string]*datasheet.Record{}}
+}
+
+func (s *MemoryStore) Get(recordId string) (*datasheet.Record, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	record, ok := s.records[recordId]
+	return record, ok, nil
+}
+
+func (s *MemoryStore) Put(records []*datasheet.Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, record := range records {
+		if record == nil || record.BaseRecord == nil || record.RecordId == nil {
+			continue
+		}
+		s.records[*record.RecordId] = record
+	}
+	return nil
+}
+
+func (s *MemoryStore) Delete(recordIds []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range recordIds {
+		delete(s.records, id)
+	}
+	return nil
+}
+
+func (s *MemoryStore) Range(fn func(record *datasheet.Record) bool) error {
+	ids, _ := s.Ids()
+	for _, id := range ids {
+		s.mu.RLock()
+		record, ok := s.records[id]
+		s.mu.RUnlock()
+		if ok && !fn(record) {
+			break
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) Count() (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.records), nil
+}
+
+func (s *MemoryStore) Ids() ([]string, error) {
+	s.mu.RLock()
+	ids := make([]string, 0, len(s.records))
+	for id := range s.records {
+		ids = append(ids, id)
+	}
+	s.mu.RUnlock()
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func (s *MemoryStore) Meta() (*Meta, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	meta := s.meta
+	return &meta, nil
+}
+
+func (s *MemoryStore) SetMeta(meta *Meta) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.meta = *meta
+	return nil
+}
+
+func (s *MemoryStore) Flush() error {
+	return nil
+}
+
+// Encoding the file format of the file store
+type Encoding string
+
+const (
+	Encoding_JSON Encoding = "json"
+	Encoding_Gob  Encoding = "gob"
+)
+
+// FileStore the store keeping records in memory, and persisting them to a json or gob file on flush
+type FileStore struct {
+	*MemoryStore
+	filePath string
+	encoding Encoding
+}
+
+// snapshot the content of the store file
+type snapshot struct {
+	Meta    Meta                `json:"meta"`
+	Records []*datasheet.Record `json:"records"`
+}
+
+// NewFileStore init file store instance, and load the records if the file exists
+func NewFileStore(filePath string, encoding Encoding) (store *FileStore, err error) {
+	if encoding != Encoding_JSON && encoding != Encoding_Gob {
+		msg := fmt.Sprintf("Unsupported encoding: %s", encoding)
+		return nil, aterror.NewSDKError(400, msg, "ClientError.InvalidArgument")
+	}
+	store = &FileStore{
+		MemoryStore: NewMemoryStore(),
+		filePath:    filePath,
+		encoding:    encoding,
+	}
+	b, err := ioutil.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		msg := fmt.Sprintf("Fail to read cache because %s", err)
+		return nil, aterror.NewSDKError(500, msg, "ClientError.FileReadError")
+	}
+	data := &snapshot{}
+	if encoding == Encoding_JSON {
+		err = json.Unmarshal(b, data)
+	} else {
+		err = gob.NewDecoder(bytes.NewReader(b)).Decode(data)
+	}
+	if err != nil {
+		msg := fmt.Sprintf("Fail to decode cache because %s", err)
+		return nil, aterror.NewSDKError(500, msg, "ClientError.DecodeError")
+	}
+	_ = store.Put(data.Records)
+	_ = store.SetMeta(&data.Meta)
+	return store, nil
+}
+
+// Flush write all records to the file, the file is replaced atomically.
+func (s *FileStore) Flush() error {
+	data := &snapshot{}
+	meta, _ := s.Meta()
+	data.Meta = *meta
+	_ = s.Range(func(record *datasheet.Record) bool {
+		data.Records = append(data.Records, record)
+		return true
+	})
+	var buf bytes.Buffer
+	var err error
+	if s.encoding == Encoding_JSON {
+		err = json.NewEncoder(&buf).Encode(data)
+	} else {
+		err = gob.NewEncoder(&buf).Encode(data)
+	}
+	if err != nil {
+		msg := fmt.Sprintf("Fail to encode cache because %s", err)
+		return aterror.NewSDKError(500, msg, "ClientError.EncodeError")
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(s.filePath), filepath.Base(s.filePath)+".tmp")
+	if err == nil {
+		_, err = tmp.Write(buf.Bytes())
+		if closeErr := tmp.Close(); err == nil {
+			err = closeErr
+		}
+		if err == nil {
+			err = os.Rename(tmp.Name(), s.filePath)
+		}
+		if err != nil {
+			_ = os.Remove(tmp.Name())
+		}
+	}
+	if err != nil {
+		msg := fmt.Sprintf("Fail to write cache because %s", err)
+		return aterror.NewSDKError(500, msg, "ClientError.FileWriteError")
+	}
+	return nil
+}
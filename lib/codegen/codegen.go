@@ -0,0 +1,335 @@
+// Package codegen provides the go source generator for datasheet schemas
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	aterror "github.com/apitable/apitable-sdks/apitable.go/lib/common/error"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/datasheet"
+	"go/format"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const datasheetImport = "github.com/apitable/apitable-sdks/apitable.go/lib/datasheet"
+
+// Table describe the datasheet to generate a struct for
+type Table struct {
+	// the struct name, such as: Leads
+	Name string
+	// such as: `dst*****`
+	DatasheetId string
+	// the fields of the datasheet
+	Fields []*datasheet.DatasheetField
+}
+
+// Options the options of the generated file
+type Options struct {
+	// the package name of the generated file
+	Package string
+	// the command line to record in the file header
+	Command string
+}
+
+// goType describe how a field is typed in go source
+type goType struct {
+	// the go type, such as: float64
+	Type string
+	// whether the field value is computed by the datasheet
+	Readonly bool
+}
+
+// goTypeOf get the go type of the datasheet field
+func goTypeOf(field *datasheet.DatasheetField) goType {
+	if field.Type == nil {
+		return goType{Type: "interface{}"}
+	}
+	switch *field.Type {
+	case datasheet.FieldType_SingleText, datasheet.FieldType_Text, datasheet.FieldType_URL,
+		datasheet.FieldType_Phone, datasheet.FieldType_SingleSelect:
+		return goType{Type: "string"}
+	case datasheet.FieldType_MultiSelect:
+		return goType{Type: "[]string"}
+	case datasheet.FieldType_Number, datasheet.FieldType_Currency, datasheet.FieldType_Percent:
+		return goType{Type: "float64"}
+	case datasheet.FieldType_Rating:
+		return goType{Type: "int64"}
+	case datasheet.FieldType_Checkbox:
+		return goType{Type: "bool"}
+	case datasheet.FieldType_DateTime:
+		return goType{Type: "int64"}
+	case datasheet.FieldType_Attachment:
+		return goType{Type: "[]*datasheet.Attachment"}
+	case datasheet.FieldType_Member:
+		return goType{Type: "[]*datasheet.UnitFieldValue"}
+	case datasheet.FieldType_MagicLink:
+		return goType{Type: "[]string"}
+	case datasheet.FieldType_AutoNumber:
+		return goType{Type: "int64", Readonly: true}
+	case datasheet.FieldType_CreatedTime, datasheet.FieldType_LastModifiedTime:
+		return goType{Type: "int64", Readonly: true}
+	case datasheet.FieldType_CreatedBy, datasheet.FieldType_LastModifiedBy:
+		return goType{Type: "*datasheet.UserInfo", Readonly: true}
+	case datasheet.FieldType_Formula:
+		var valueType *datasheet.ValueType
+		if field.Property == nil {
+			return goType{Type: "interface{}", Readonly: true}
+		}
+		if property := field.FormulaFieldProperty(); property != nil {
+			valueType = property.ValueType
+		}
+		return goType{Type: valueGoType(valueType), Readonly: true}
+	case datasheet.FieldType_MagicLookUp:
+		var valueType *datasheet.ValueType
+		if field.Property == nil {
+			return goType{Type: "interface{}", Readonly: true}
+		}
+		if property := field.MagicLookUpFieldProperty(); property != nil {
+			valueType = property.ValueType
+		}
+		return goType{Type: valueGoType(valueType), Readonly: true}
+	}
+	return goType{Type: "interface{}"}
+}
+
+func valueGoType(valueType *datasheet.ValueType) string {
+	if valueType == nil {
+		return "interface{}"
+	}
+	switch *valueType {
+	case datasheet.ValueType_String:
+		return "string"
+	case datasheet.ValueType_Number:
+		return "float64"
+	case datasheet.ValueType_Boolean:
+		return "bool"
+	case datasheet.ValueType_DateTime:
+		return "int64"
+	case datasheet.ValueType_Array:
+		return "[]interface{}"
+	}
+	return "interface{}"
+}
+
+// Identifier convert the name to an exported go identifier, such as: `due date` => DueDate
+func Identifier(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	s := b.String()
+	if s == "" {
+		return ""
+	}
+	// the identifier must start with an upper case letter to be exported, such as chinese names.
+	first := []rune(s)[0]
+	if !unicode.IsUpper(first) {
+		s = "X" + s
+	}
+	return s
+}
+
+type generator struct {
+	buf bytes.Buffer
+	// the package level identifiers declared in the file
+	idents map[string]bool
+}
+
+// unique make the package level identifier unique, such as: LeadsStatus, LeadsStatus2
+func (g *generator) unique(ident string) string {
+	base := ident
+	for i := 2; g.idents[ident]; i++ {
+		ident = fmt.Sprintf("%s%d", base, i)
+	}
+	g.idents[ident] = true
+	return ident
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// Generate generate the go source with a struct per datasheet
+func Generate(options *Options, tables []*Table) ([]byte, error) {
+	body := &generator{idents: map[string]bool{}}
+	// the struct names and their declarations are reserved first, the option types and constants are suffixed on conflicts.
+	names := make([]string, len(tables))
+	for i, table := range tables {
+		name := Identifier(table.Name)
+		declared := []string{name, name + "DatasheetId", "Decode" + name}
+		for _, ident := range declared {
+			if name == "" || body.idents[ident] {
+				msg := fmt.Sprintf("Invalid or duplicate struct name: %s", table.Name)
+				return nil, aterror.NewSDKError(400, msg, "ClientError.InvalidArgument")
+			}
+		}
+		for _, ident := range declared {
+			body.idents[ident] = true
+		}
+		names[i] = name
+	}
+	for i, table := range tables {
+		body.table(names[i], table)
+	}
+
+	file := &generator{}
+	if options.Command != "" {
+		file.printf("// Code generated by %s; DO NOT EDIT.\n\n", options.Command)
+	} else {
+		file.printf("// Code generated by vika-gen; DO NOT EDIT.\n\n")
+	}
+	file.printf("package %s\n\n", options.Package)
+	file.printf("import \"%s\"\n\n", datasheetImport)
+	file.buf.Write(body.buf.Bytes())
+	src, err := format.Source(file.buf.Bytes())
+	if err != nil {
+		msg := fmt.Sprintf("Fail to format generated source because %s", err)
+		return nil, aterror.NewSDKError(500, msg, "ClientError.FormatError")
+	}
+	return src, nil
+}
+
+type column struct {
+	ident string
+	field *datasheet.DatasheetField
+	typ   goType
+}
+
+func (g *generator) table(name string, table *Table) {
+	columns := make([]*column, 0, len(table.Fields))
+	// the names of the generated struct fields and methods should not conflict.
+	idents := map[string]bool{"RecordId": true, "Fields": true}
+	for _, field := range table.Fields {
+		ident := Identifier(stringValue(field.Name))
+		if ident == "" {
+			ident = Identifier(stringValue(field.Id))
+		}
+		// make the identifier unique, such as: Name, Name2
+		base := ident
+		for i := 2; idents[ident] || idents["Get"+ident] || idents["Set"+ident]; i++ {
+			ident = fmt.Sprintf("%s%d", base, i)
+		}
+		idents[ident] = true
+		idents["Get"+ident] = true
+		idents["Set"+ident] = true
+		columns = append(columns, &column{ident: ident, field: field, typ: goTypeOf(field)})
+	}
+
+	// select options
+	for _, col := range columns {
+		if col.field.Property == nil || col.field.Type == nil {
+			continue
+		}
+		property := col.field.SelectFieldProperty()
+		if property == nil || len(property.Options) == 0 {
+			continue
+		}
+		typeName := g.unique(name + col.ident)
+		if *col.field.Type == datasheet.FieldType_MultiSelect {
+			col.typ.Type = "[]" + typeName
+		} else {
+			col.typ.Type = typeName
+		}
+		g.printf("// %s the options of the field %q\n", typeName, stringValue(col.field.Name))
+		g.printf("type %s = string\n\n", typeName)
+		g.printf("// all options of the field %q\n", stringValue(col.field.Name))
+		g.printf("const (\n")
+		for i, option := range property.Options {
+			optionName := Identifier(stringValue(option.Name))
+			if optionName == "" {
+				optionName = fmt.Sprintf("Option%d", i+1)
+			}
+			ident := g.unique(typeName + optionName)
+			g.printf("\t%s %s = %q\n", ident, typeName, stringValue(option.Name))
+		}
+		g.printf(")\n\n")
+	}
+
+	// struct
+	g.printf("// %sDatasheetId the datasheet id of %s\n", name, name)
+	g.printf("const %sDatasheetId = %q\n\n", name, table.DatasheetId)
+	g.printf("// %s describe the record of the datasheet %s\n", name, table.DatasheetId)
+	g.printf("type %s struct {\n", name)
+	g.printf("\tRecordId string `vika:\"@recordId\"`\n")
+	for _, col := range columns {
+		tag := datasheet.EscapeTagName(stringValue(col.field.Name)) + "," + stringValue(col.field.Id)
+		if col.typ.Readonly {
+			tag += ",readonly"
+		} else {
+			// the zero values are written too, so the checkbox can be unchecked and the text can be cleared.
+			tag += ",omitempty=false"
+		}
+		g.printf("\t// %s %s\n", col.ident, fieldComment(col.field))
+		g.printf("\t%s %s `vika:%q`\n", col.ident, col.typ.Type, tag)
+	}
+	g.printf("}\n\n")
+
+	// decoder and encoder
+	g.printf("// Decode%s decode the record into %s\n", name, name)
+	g.printf("func Decode%s(record *datasheet.Record) (*%s, error) {\n", name, name)
+	g.printf("\tv := &%s{}\n", name)
+	g.printf("\tif err := datasheet.DecodeRecord(record, v); err != nil {\n\t\treturn nil, err\n\t}\n")
+	g.printf("\treturn v, nil\n}\n\n")
+	g.printf("// Fields encode %s into record fields keyed by the field key, readonly fields are skipped and the zero values are written.\n", name)
+	g.printf("func (r *%s) Fields(fieldKey string) (*datasheet.Field, error) {\n", name)
+	g.printf("\treturn datasheet.EncodeFields(r, fieldKey)\n}\n\n")
+
+	// typed accessors
+	for _, col := range columns {
+		g.printf("// Get%s get the value of the field %q\n", col.ident, stringValue(col.field.Name))
+		g.printf("func (r *%s) Get%s() %s {\n", name, col.ident, col.typ.Type)
+		g.printf("\tif r == nil {\n\t\treturn %s\n\t}\n", zeroValue(col.typ.Type))
+		g.printf("\treturn r.%s\n}\n\n", col.ident)
+		if col.typ.Readonly {
+			continue
+		}
+		g.printf("// Set%s set the value of the field %q\n", col.ident, stringValue(col.field.Name))
+		g.printf("func (r *%s) Set%s(v %s) {\n", name, col.ident, col.typ.Type)
+		g.printf("\tr.%s = v\n}\n\n", col.ident)
+	}
+}
+
+func fieldComment(field *datasheet.DatasheetField) string {
+	fieldType := ""
+	if field.Type != nil {
+		fieldType = string(*field.Type)
+	}
+	return fmt.Sprintf("%s field %q", fieldType, stringValue(field.Name))
+}
+
+func zeroValue(goType string) string {
+	switch {
+	case strings.HasPrefix(goType, "[]"), strings.HasPrefix(goType, "*"), goType == "interface{}":
+		return "nil"
+	case goType == "float64", goType == "int64":
+		return "0"
+	case goType == "bool":
+		return "false"
+	}
+	// string and the option types of select fields
+	return `""`
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// SortTables sort the tables by name to keep the generated source stable
+func SortTables(tables []*Table) {
+	sort.Slice(tables, func(i, j int) bool {
+		return tables[i].Name < tables[j].Name
+	})
+}
@@ -130,7 +130,8 @@ func (c *Client) sendWithToken(request athttp.Request, response athttp.Response)
 	}
 	requestPayload := ""
 	if httpRequestMethod == athttp.POST || httpRequestMethod == athttp.PATCH {
-		if c.profile.Upload {
+		// send the file if the request carries one, such as the attachment upload request
+		if c.profile.Upload || request.GetFile() != nil {
 			requestPayload = string(request.GetFile())
 		} else {
 			b, err := json.Marshal(request)
@@ -182,12 +183,16 @@ func createFormFile(bodyWriter *multipart.Writer, fieldname, filePath string, co
 func GetFileContentType(out *os.File) (string, error) {
 	// Only the first 512 bytes are used to sniff the content type.
 	buffer := make([]byte, 512)
-	_, err := out.Read(buffer)
-	if err != nil {
+	n, err := out.Read(buffer)
+	if err != nil && err != io.EOF {
 		return "", err
 	}
 	// Use the net/http package's handy DectectContentType function. Always returns a valid
 	// content-type by returning "application/octet-stream" if no others seemed to match.
-	contentType := http.DetectContentType(buffer)
+	contentType := http.DetectContentType(buffer[:n])
+	// rewind the file, so the sniffed bytes are uploaded too.
+	if _, err = out.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
 	return contentType, nil
 }
@@ -22,6 +22,8 @@ const (
 	DefaultPageSize int = 100
 	// the max count for get get records
 	MaxPageSize int = 1000
+	// the max count of records per create, modify or delete request
+	MaxWriteRecords int = 10
 	// the default page for paged records
 	DefaultPageNum int = 1
 	// the default request timeout
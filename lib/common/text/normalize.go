@@ -0,0 +1,106 @@
+// Package text provides the text helpers shared by the packages, such as the normalization for the matching and the search
+package text
+
+import (
+	"strings"
+	"unicode"
+)
+
+// the full width katakana of the half width katakana from U+FF66 to U+FF9D
+var halfWidthKatakana = []rune("ヲァィゥェォャュョッーアイウエオカキクケコサシスセソタチツテトナニヌネノハヒフヘホマミムメモヤユヨラリルレロワン")
+
+// the half width punctuations from U+FF61 to U+FF65
+var halfWidthPunctuation = []rune("。「」、・")
+
+const (
+	halfWidthVoicedMark     = 'ﾞ'
+	halfWidthSemiVoicedMark = 'ﾟ'
+)
+
+// Normalize fold the text for fuzzy matching.
+//
+// * the full width ascii letters, digits and symbols are converted to half width, such as: Ａ => A.
+// * the half width katakana are converted to full width, such as: ｶﾞ => ガ.
+// * the letters are lower case, and the whitespaces are trimmed and collapsed into one space.
+func Normalize(s string) string {
+	var b strings.Builder
+	runes := []rune(s)
+	space := false
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r >= '！' && r <= '～':
+			r -= 0xFEE0
+		case r >= '｡' && r <= '･':
+			r = halfWidthPunctuation[r-'｡']
+		case r >= 'ｦ' && r <= 'ﾝ':
+			r = halfWidthKatakana[r-'ｦ']
+			if i+1 < len(runes) {
+				if voiced, ok := voice(r, runes[i+1]); ok {
+					r = voiced
+					i++
+				}
+			}
+		}
+		if unicode.IsSpace(r) {
+			space = b.Len() > 0
+			continue
+		}
+		if space {
+			b.WriteRune(' ')
+			space = false
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// voice combine the katakana with the half width voiced mark, such as: カ + ﾞ => ガ
+func voice(r rune, mark rune) (rune, bool) {
+	switch mark {
+	case halfWidthVoicedMark:
+		switch {
+		case r == 'ウ':
+			return 'ヴ', true
+		case strings.ContainsRune("カキクケコサシスセソタチツテトハヒフヘホ", r):
+			return r + 1, true
+		}
+	case halfWidthSemiVoicedMark:
+		if strings.ContainsRune("ハヒフヘホ", r) {
+			return r + 2, true
+		}
+	}
+	return r, false
+}
+
+// Distance the edit distance of the runes, the count of the inserted, deleted and replaced runes
+func Distance(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min3(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
+
+func min3(a int, b int, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
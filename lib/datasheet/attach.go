@@ -0,0 +1,217 @@
+package datasheet
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/common"
+	aterror "github.com/apitable/apitable-sdks/apitable.go/lib/common/error"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// AttachMode how the uploaded files are written to the attachment field
+type AttachMode string
+
+const (
+	// AttachMode_Append keep the attachments already on the record, and add the files after them
+	AttachMode_Append AttachMode = "append"
+	// AttachMode_Replace replace the attachments of the record with the files
+	AttachMode_Replace AttachMode = "replace"
+)
+
+// the count of the concurrent uploads of AttachFiles
+const attachConcurrency = 4
+
+// UploadCache the uploaded attachments by the content hash of the files, so the same file is uploaded once.
+//
+// * the tokens are cached by datasheet, as the attachments belong to the datasheet.
+// * the cache is saved to the file after each upload if the file path is set.
+type UploadCache struct {
+	mu       sync.Mutex
+	filePath string
+	values   map[string]*AttachmentValue
+}
+
+// NewUploadCache init upload cache instance, and load the cache file if it exists.
+// the cache is kept in memory only if the file path is empty.
+func NewUploadCache(filePath string) (cache *UploadCache, err error) {
+	cache = &UploadCache{filePath: filePath, values: map[string]*AttachmentValue{}}
+	if filePath == "" {
+		return cache, nil
+	}
+	b, err := ioutil.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(b, &cache.values); err != nil {
+		msg := fmt.Sprintf("Invalid upload cache %s because %s", filePath, err)
+		return nil, aterror.NewSDKError(400, msg, "ClientError.InvalidArgument")
+	}
+	return cache, nil
+}
+
+func uploadCacheKey(datasheetId string, hash string) string {
+	return datasheetId + ":" + hash
+}
+
+// Get get the uploaded attachment of the content hash, nil if not uploaded
+func (c *UploadCache) Get(datasheetId string, hash string) *AttachmentValue {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.values[uploadCacheKey(datasheetId, hash)]
+}
+
+// Put save the uploaded attachment of the content hash
+func (c *UploadCache) Put(datasheetId string, hash string, value *AttachmentValue) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[uploadCacheKey(datasheetId, hash)] = value
+	if c.filePath == "" {
+		return nil
+	}
+	b, err := json.MarshalIndent(c.values, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(c.filePath), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(c.filePath, b, 0644)
+}
+
+// uploadCache the cache of the datasheet, or the cache of one call if the datasheet has no UploadCache
+func (c *Datasheet) uploadCache() *UploadCache {
+	if c.UploadCache != nil {
+		return c.UploadCache
+	}
+	return &UploadCache{values: map[string]*AttachmentValue{}}
+}
+
+// uploadFiles upload the files concurrently, the files of the same content are uploaded once.
+func (c *Datasheet) uploadFiles(files []string) ([]*AttachmentValue, error) {
+	cache := c.uploadCache()
+	hashes := make([]string, len(files))
+	// the first file of each content hash not in the cache
+	uploads := map[string]string{}
+	for i, file := range files {
+		_, hash, err := fileChecksum(file)
+		if err != nil {
+			msg := fmt.Sprintf("Fail to read file %s because %s", file, err)
+			return nil, aterror.NewSDKError(500, msg, "ClientError.FileReadError")
+		}
+		hashes[i] = hash
+		if _, ok := uploads[hash]; !ok && cache.Get(c.DatasheetId, hash) == nil {
+			uploads[hash] = file
+		}
+	}
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var uploadErr error
+	semaphore := make(chan struct{}, attachConcurrency)
+	for hash, file := range uploads {
+		hash, file := hash, file
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			request := NewUploadRequest()
+			request.FilePath = file
+			attachment, err := c.UploadFile(request)
+			if err == nil && (attachment == nil || attachment.Token == nil) {
+				err = aterror.NewSDKError(500, "The upload response has no token", "ClientError.UploadError")
+			}
+			if err == nil {
+				value := &AttachmentValue{Token: *attachment.Token, Name: filepath.Base(file)}
+				if attachment.Name != nil {
+					value.Name = *attachment.Name
+				}
+				err = cache.Put(c.DatasheetId, hash, value)
+			}
+			if err != nil {
+				mu.Lock()
+				if uploadErr == nil {
+					uploadErr = err
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if uploadErr != nil {
+		return nil, uploadErr
+	}
+	values := make([]*AttachmentValue, len(files))
+	for i, file := range files {
+		// the token is shared by the same content, the name is the local file name
+		values[i] = &AttachmentValue{Token: cache.Get(c.DatasheetId, hashes[i]).Token, Name: filepath.Base(file)}
+	}
+	return values, nil
+}
+
+// AttachFiles upload the local files, and write them to the attachment field of the record.
+//
+// * the files are uploaded concurrently, the files of the same content are uploaded once.
+// * the file uploaded by the calls before is not uploaded again only if UploadCache of the datasheet is set.
+// * in append mode, the attachments already on the record are kept, the same token is not added twice.
+// * returns the record after modified.
+func (c *Datasheet) AttachFiles(recordId string, fieldName string, files []string, mode AttachMode) (record *Record, err error) {
+	if recordId == "" || fieldName == "" {
+		return nil, aterror.NewSDKError(400, "The record id and field name are required", "ClientError.InvalidArgument")
+	}
+	if mode != AttachMode_Append && mode != AttachMode_Replace {
+		msg := fmt.Sprintf("Unsupported attach mode: %s", mode)
+		return nil, aterror.NewSDKError(400, msg, "ClientError.InvalidArgument")
+	}
+	values := []*AttachmentValue{}
+	tokens := map[string]bool{}
+	if mode == AttachMode_Append {
+		request := NewDescribeRecordRequest()
+		request.RecordIds = []*string{common.StringPtr(recordId)}
+		current, err := c.DescribeRecord(request)
+		if err != nil {
+			return nil, err
+		}
+		if current == nil {
+			msg := fmt.Sprintf("The record %s is not found", recordId)
+			return nil, aterror.NewSDKError(404, msg, "ClientError.RecordNotFound")
+		}
+		for _, attachment := range RecordAttachments(current, fieldName) {
+			if attachment.Token == nil || tokens[*attachment.Token] {
+				continue
+			}
+			tokens[*attachment.Token] = true
+			value := &AttachmentValue{Token: *attachment.Token}
+			if attachment.Name != nil {
+				value.Name = *attachment.Name
+			}
+			values = append(values, value)
+		}
+	}
+	uploaded, err := c.uploadFiles(files)
+	if err != nil {
+		return nil, err
+	}
+	for _, value := range uploaded {
+		if tokens[value.Token] {
+			continue
+		}
+		tokens[value.Token] = true
+		values = append(values, value)
+	}
+	request := NewModifyRecordsRequest()
+	request.Records = []*BaseRecord{{RecordId: common.StringPtr(recordId), Fields: &Field{fieldName: values}}}
+	records, err := c.ModifyRecords(request)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) > 0 {
+		return records[0], nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,311 @@
+package datasheet
+
+import (
+	"fmt"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/common"
+	aterror "github.com/apitable/apitable-sdks/apitable.go/lib/common/error"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// CopyMode copy or move the records
+type CopyMode string
+
+const (
+	CopyMode_Copy CopyMode = "copy"
+	// CopyMode_Move delete the source records after all destination records are created
+	CopyMode_Move CopyMode = "move"
+)
+
+// FieldMapping how to get the value of a destination field from the source record.
+// the value is taken from Func, From or Value in order.
+type FieldMapping struct {
+	// the destination field name. required: yes.
+	To string
+	// the source field name, the value is copied as it is
+	From string
+	// the constant value, such as the status of the archived records
+	Value FieldValue
+	// compute the value from the source record, the nil value leaves the field empty
+	Func func(record *Record) (FieldValue, error)
+}
+
+// CopyOptions the options of CopyRecords
+type CopyOptions struct {
+	// the default is CopyMode_Copy
+	Mode CopyMode
+	// the records count of a create request, the default and max value is common.MaxWriteRecords
+	BatchSize int
+	// download the attachments and upload them to the destination.
+	// the default is true if the datasheets are in different spaces, compared by SpaceId.
+	ReuploadAttachments bool
+	// the http client to download the attachments, the default is http.DefaultClient
+	HTTPClient *http.Client
+}
+
+// CopyResult the result of CopyRecords
+type CopyResult struct {
+	// the destination record ids by the source record ids
+	RecordIds map[string]string `json:"recordIds"`
+	Copied    int               `json:"copied"`
+	// the count of the deleted source records in move mode
+	Deleted int `json:"deleted"`
+	// the count of the attachments uploaded to the destination
+	Attachments int `json:"attachments"`
+}
+
+// copier copy the records of the source datasheet to the destination
+type copier struct {
+	src      *Datasheet
+	dst      *Datasheet
+	mapping  []*FieldMapping
+	fields   map[string]*DatasheetField
+	reupload bool
+	dir      string
+	download DownloadOptions
+	// the uploaded attachments by the source token
+	uploaded map[string]*AttachmentValue
+}
+
+// CopyRecords copy the records matching the formula from src to dst, and write them in batches.
+//
+// * filter is the formula to select the source records, all records are copied if it's empty.
+// * the fields are copied by the mapping, or the fields of the same name if the mapping is empty.
+// * the attachments are uploaded again if the destination is in another space.
+// * in move mode, the source records are deleted after the destination records are created.
+// * in move mode, if a batch fails, the source records of the created batches are deleted before the error is returned,
+// so RecordIds and Deleted of the result are the moved records, and a retry will not copy them again.
+func CopyRecords(src *Datasheet, dst *Datasheet, mapping []*FieldMapping, filter string, options *CopyOptions) (result *CopyResult, err error) {
+	opts := CopyOptions{}
+	if options != nil {
+		opts = *options
+	}
+	if opts.Mode == "" {
+		opts.Mode = CopyMode_Copy
+	}
+	if opts.Mode != CopyMode_Copy && opts.Mode != CopyMode_Move {
+		msg := fmt.Sprintf("Unsupported copy mode: %s", opts.Mode)
+		return nil, aterror.NewSDKError(400, msg, "ClientError.InvalidArgument")
+	}
+	if opts.BatchSize <= 0 || opts.BatchSize > common.MaxWriteRecords {
+		opts.BatchSize = common.MaxWriteRecords
+	}
+	c := &copier{
+		src:      src,
+		dst:      dst,
+		reupload: opts.ReuploadAttachments || (src.SpaceId != "" && dst.SpaceId != "" && src.SpaceId != dst.SpaceId),
+		download: DownloadOptions{HTTPClient: opts.HTTPClient, Retries: 3, RetryDelay: defaultRetryDelay},
+		uploaded: map[string]*AttachmentValue{},
+	}
+	if c.download.HTTPClient == nil {
+		c.download.HTTPClient = http.DefaultClient
+	}
+	if err = c.loadFields(mapping); err != nil {
+		return nil, err
+	}
+	request := NewDescribeRecordRequest()
+	if filter != "" {
+		request.FilterByFormula = common.StringPtr(filter)
+	}
+	records, err := src.DescribeAllRecords(request)
+	if err != nil {
+		return nil, err
+	}
+	if c.reupload {
+		if c.dir, err = ioutil.TempDir("", "vika-copy"); err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(c.dir)
+	}
+	result = &CopyResult{RecordIds: map[string]string{}}
+	copied, err := c.copy(records, opts.BatchSize, result)
+	result.Attachments = len(c.uploaded)
+	if opts.Mode != CopyMode_Move {
+		return result, err
+	}
+	// the copied records are deleted even if a later batch fails, so they are not copied again by a retry.
+	if e := c.deleteSources(copied, result); err == nil {
+		err = e
+	}
+	return result, err
+}
+
+// copy create the destination records in batches, and return the ids of the copied source records.
+func (c *copier) copy(records []*Record, batchSize int, result *CopyResult) (copied []*string, err error) {
+	for start := 0; start < len(records); start += batchSize {
+		end := start + batchSize
+		if end > len(records) {
+			end = len(records)
+		}
+		batch := records[start:end]
+		create := NewCreateRecordsRequest()
+		for _, record := range batch {
+			fields, err := c.fieldsOf(record)
+			if err != nil {
+				return copied, err
+			}
+			create.Records = append(create.Records, &Fields{Fields: fields})
+		}
+		created, err := c.dst.CreateRecords(create)
+		if err != nil {
+			return copied, err
+		}
+		if len(created) != len(batch) {
+			msg := fmt.Sprintf("Expect %d records created, but got %d", len(batch), len(created))
+			return copied, aterror.NewSDKError(500, msg, "ClientError.CopyError")
+		}
+		for i, record := range created {
+			source := batch[i]
+			if source.BaseRecord == nil || source.RecordId == nil || record.BaseRecord == nil || record.RecordId == nil {
+				continue
+			}
+			result.RecordIds[*source.RecordId] = *record.RecordId
+			copied = append(copied, source.RecordId)
+		}
+		result.Copied += len(created)
+	}
+	return copied, nil
+}
+
+// deleteSources delete the copied source records in move mode
+func (c *copier) deleteSources(copied []*string, result *CopyResult) error {
+	for start := 0; start < len(copied); start += common.MaxWriteRecords {
+		end := start + common.MaxWriteRecords
+		if end > len(copied) {
+			end = len(copied)
+		}
+		request := NewDeleteRecordsRequest()
+		request.RecordIds = copied[start:end]
+		if err := c.src.DeleteRecords(request); err != nil {
+			return err
+		}
+		result.Deleted += end - start
+	}
+	return nil
+}
+
+// loadFields load the destination fields, and check the mapping.
+// the mapping of the same field names is built if it's empty.
+func (c *copier) loadFields(mapping []*FieldMapping) error {
+	fields, err := c.dst.DescribeFields(nil)
+	if err != nil {
+		return err
+	}
+	c.fields = map[string]*DatasheetField{}
+	for _, field := range fields {
+		if field.Name != nil {
+			c.fields[*field.Name] = field
+		}
+	}
+	writable := func(field *DatasheetField) bool {
+		return !fieldTypeValue(field.Type).IsComputed()
+	}
+	if len(mapping) == 0 {
+		srcFields, err := c.src.DescribeFields(nil)
+		if err != nil {
+			return err
+		}
+		for _, field := range srcFields {
+			if field.Name == nil {
+				continue
+			}
+			if target, ok := c.fields[*field.Name]; ok && writable(target) {
+				c.mapping = append(c.mapping, &FieldMapping{To: *field.Name, From: *field.Name})
+			}
+		}
+		return nil
+	}
+	for _, m := range mapping {
+		if m == nil || m.To == "" {
+			return aterror.NewSDKError(400, "The destination field of the mapping is required", "ClientError.InvalidArgument")
+		}
+		field, ok := c.fields[m.To]
+		if !ok {
+			msg := fmt.Sprintf("The field %s is not found in the destination datasheet", m.To)
+			return aterror.NewSDKError(404, msg, "ClientError.FieldNotFound")
+		}
+		if !writable(field) {
+			msg := fmt.Sprintf("The field %s can't be written, the type is %s", m.To, fieldTypeValue(field.Type))
+			return aterror.NewSDKError(400, msg, "ClientError.UnsupportedField")
+		}
+	}
+	c.mapping = mapping
+	return nil
+}
+
+// fieldsOf map the source record to the destination fields
+func (c *copier) fieldsOf(record *Record) (*Field, error) {
+	fields := Field{}
+	for _, m := range c.mapping {
+		var value FieldValue
+		switch {
+		case m.Func != nil:
+			var err error
+			if value, err = m.Func(record); err != nil {
+				return nil, err
+			}
+		case m.From != "":
+			if record.BaseRecord != nil && record.Fields != nil {
+				value = (*record.Fields)[m.From]
+			}
+		default:
+			value = m.Value
+		}
+		if value == nil {
+			continue
+		}
+		if fieldTypeValue(c.fields[m.To].Type) == FieldType_Attachment {
+			attachments, err := c.attachments(value)
+			if err != nil {
+				return nil, err
+			}
+			value = attachments
+		}
+		fields[m.To] = value
+	}
+	return &fields, nil
+}
+
+// attachments get the attachment values to write, the attachments are uploaded again if required
+func (c *copier) attachments(value FieldValue) ([]*AttachmentValue, error) {
+	values := []*AttachmentValue{}
+	for _, attachment := range RecordAttachments(&Record{BaseRecord: &BaseRecord{Fields: &Field{"": value}}}, "") {
+		if attachment.Token == nil {
+			continue
+		}
+		token, name := *attachment.Token, ""
+		if attachment.Name != nil {
+			name = *attachment.Name
+		}
+		if !c.reupload {
+			values = append(values, &AttachmentValue{Token: token, Name: name})
+			continue
+		}
+		uploaded, ok := c.uploaded[token]
+		if !ok {
+			if attachment.Url == nil {
+				msg := fmt.Sprintf("The attachment %s has no url to download", token)
+				return nil, aterror.NewSDKError(400, msg, "ClientError.InvalidArgument")
+			}
+			file := &DownloadedFile{Token: token, Name: name, Path: path.Join(safeFileName(token), safeFileName(name))}
+			if name == "" {
+				file.Path = path.Join(safeFileName(token), "file")
+			}
+			if err := downloadWithRetry(&c.download, attachment, c.dir, file); err != nil {
+				msg := fmt.Sprintf("Fail to download the attachment %s because %s", token, err)
+				return nil, aterror.NewSDKError(500, msg, "ClientError.DownloadError")
+			}
+			files, err := c.dst.uploadFiles([]string{filepath.Join(c.dir, filepath.FromSlash(file.Path))})
+			if err != nil {
+				return nil, err
+			}
+			uploaded = files[0]
+			c.uploaded[token] = uploaded
+		}
+		values = append(values, uploaded)
+	}
+	return values, nil
+}
@@ -0,0 +1,389 @@
+package datasheet
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	aterror "github.com/apitable/apitable-sdks/apitable.go/lib/common/error"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DownloadIndexFile the index of the downloaded attachments in the destination directory, keyed by token
+const DownloadIndexFile = ".attachments.json"
+
+// the default path of the attachment relative to the destination directory
+const defaultNameTemplate = "{recordId}/{name}"
+
+// the default delay before the first retry of a failed download
+const defaultRetryDelay = time.Second
+
+// DownloadOptions the options of the attachment download
+type DownloadOptions struct {
+	// the count of the concurrent downloads, the default is 4
+	Concurrency int
+	// the retry times of a failed download, the default is 3, the negative value disables the retry
+	Retries int
+	// the delay before the first retry, doubled for each retry. the default is 1s
+	RetryDelay time.Duration
+	// the file path relative to the destination directory, the default is `{recordId}/{name}`.
+	// the placeholders: {recordId}, {token}, {name}, {base}, {ext}, {index}.
+	NameTemplate string
+	// the http client to download the attachments, the default is http.DefaultClient
+	HTTPClient *http.Client
+}
+
+// DownloadedFile the attachment in the destination directory
+type DownloadedFile struct {
+	RecordId string `json:"recordId"`
+	Token    string `json:"token"`
+	Name     string `json:"name"`
+	// the file path relative to the destination directory
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	Sha256 string `json:"sha256"`
+	// the file was downloaded before, or shared with another record of the same token
+	Skipped bool `json:"skipped"`
+	// the reason of the failed download
+	Error string `json:"error,omitempty"`
+}
+
+// DownloadManifest the result of the attachment download
+type DownloadManifest struct {
+	Files []*DownloadedFile `json:"files"`
+	// the file paths by record id, the failed downloads are not included
+	Records    map[string][]string `json:"records"`
+	Downloaded int                 `json:"downloaded"`
+	Skipped    int                 `json:"skipped"`
+	Failed     int                 `json:"failed"`
+}
+
+// RecordAttachments decode the attachments of the record field, nil if the field is empty
+func RecordAttachments(record *Record, fieldName string) []*Attachment {
+	if record == nil || record.BaseRecord == nil || record.Fields == nil {
+		return nil
+	}
+	value, ok := (*record.Fields)[fieldName]
+	if !ok || value == nil {
+		return nil
+	}
+	b, _ := json.Marshal(value)
+	attachments := []*Attachment{}
+	if err := json.Unmarshal(b, &attachments); err != nil {
+		return nil
+	}
+	return attachments
+}
+
+// DownloadAttachments download the attachments of the field to the destination directory.
+//
+// * the files already downloaded are skipped by token, see DownloadIndexFile. the same token is downloaded once.
+// * the interrupted download is resumed from the `.part` file by range request.
+// * the size of the file is verified with the attachment size.
+// * the files of the same path are suffixed by the order, such as: a.txt, a-1.txt, see uniquePath.
+// * the failed downloads are recorded in the manifest, and the error is returned after all downloads finish.
+func DownloadAttachments(records []*Record, fieldName string, destDir string, options *DownloadOptions) (manifest *DownloadManifest, err error) {
+	opts := DownloadOptions{}
+	if options != nil {
+		opts = *options
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 4
+	}
+	if opts.Retries == 0 {
+		opts.Retries = 3
+	} else if opts.Retries < 0 {
+		opts.Retries = 0
+	}
+	if opts.RetryDelay <= 0 {
+		opts.RetryDelay = defaultRetryDelay
+	}
+	if opts.NameTemplate == "" {
+		opts.NameTemplate = defaultNameTemplate
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = http.DefaultClient
+	}
+	if err = os.MkdirAll(destDir, 0755); err != nil {
+		return nil, err
+	}
+	index, err := readDownloadIndex(destDir)
+	if err != nil {
+		return nil, err
+	}
+	manifest = &DownloadManifest{Files: []*DownloadedFile{}, Records: map[string][]string{}}
+	// the first file of each token to download, the other files of the token share it
+	owners := map[string]*DownloadedFile{}
+	pending := []*pendingDownload{}
+	// the tokens by the file path, to avoid the downloads of different tokens to the same path
+	paths := map[string]string{}
+	for token, saved := range index {
+		paths[saved.Path] = token
+	}
+	for _, record := range records {
+		recordId := ""
+		if record.BaseRecord != nil && record.RecordId != nil {
+			recordId = *record.RecordId
+		}
+		for i, attachment := range RecordAttachments(record, fieldName) {
+			if attachment.Token == nil {
+				continue
+			}
+			file := &DownloadedFile{RecordId: recordId, Token: *attachment.Token}
+			if attachment.Name != nil {
+				file.Name = *attachment.Name
+			}
+			manifest.Files = append(manifest.Files, file)
+			if owner, ok := owners[file.Token]; ok {
+				file.Skipped = true
+				pending = append(pending, &pendingDownload{file: file, owner: owner})
+				continue
+			}
+			owners[file.Token] = file
+			if saved, ok := index[file.Token]; ok && saved.exists(destDir) {
+				file.Path, file.Size, file.Sha256, file.Skipped = saved.Path, saved.Size, saved.Sha256, true
+				continue
+			}
+			file.Path, err = attachmentPath(opts.NameTemplate, file, i)
+			if err != nil {
+				return nil, err
+			}
+			file.Path = uniquePath(file.Path, file.Token, paths)
+			if attachment.Url == nil || *attachment.Url == "" {
+				file.Error = "the attachment has no url"
+				continue
+			}
+			pending = append(pending, &pendingDownload{file: file, attachment: attachment})
+		}
+	}
+	downloads := make(chan *pendingDownload)
+	var wg sync.WaitGroup
+	for i := 0; i < opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for d := range downloads {
+				if err := downloadWithRetry(&opts, d.attachment, destDir, d.file); err != nil {
+					d.file.Error = err.Error()
+				}
+			}
+		}()
+	}
+	for _, d := range pending {
+		if d.owner == nil {
+			downloads <- d
+		}
+	}
+	close(downloads)
+	wg.Wait()
+	for _, d := range pending {
+		if d.owner != nil {
+			d.file.Path, d.file.Size, d.file.Sha256, d.file.Error = d.owner.Path, d.owner.Size, d.owner.Sha256, d.owner.Error
+		}
+	}
+	for _, file := range manifest.Files {
+		switch {
+		case file.Error != "":
+			manifest.Failed++
+			continue
+		case file.Skipped:
+			manifest.Skipped++
+		default:
+			manifest.Downloaded++
+			index[file.Token] = &DownloadedFile{Token: file.Token, Name: file.Name, Path: file.Path, Size: file.Size, Sha256: file.Sha256}
+		}
+		manifest.Records[file.RecordId] = append(manifest.Records[file.RecordId], file.Path)
+	}
+	if err = writeDownloadIndex(destDir, index); err != nil {
+		return manifest, err
+	}
+	if manifest.Failed > 0 {
+		msg := fmt.Sprintf("Fail to download %d attachments, see the errors of the manifest", manifest.Failed)
+		return manifest, aterror.NewSDKError(500, msg, "ClientError.DownloadError")
+	}
+	return manifest, nil
+}
+
+// pendingDownload the file to download, or the file sharing the download of the owner
+type pendingDownload struct {
+	file       *DownloadedFile
+	attachment *Attachment
+	owner      *DownloadedFile
+}
+
+func (f *DownloadedFile) exists(destDir string) bool {
+	info, err := os.Stat(filepath.Join(destDir, filepath.FromSlash(f.Path)))
+	return err == nil && !info.IsDir() && info.Size() == f.Size
+}
+
+func readDownloadIndex(destDir string) (map[string]*DownloadedFile, error) {
+	index := map[string]*DownloadedFile{}
+	b, err := ioutil.ReadFile(filepath.Join(destDir, DownloadIndexFile))
+	if os.IsNotExist(err) {
+		return index, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(b, &index); err != nil {
+		msg := fmt.Sprintf("Invalid download index %s because %s", DownloadIndexFile, err)
+		return nil, aterror.NewSDKError(400, msg, "ClientError.InvalidArgument")
+	}
+	return index, nil
+}
+
+func writeDownloadIndex(destDir string, index map[string]*DownloadedFile) error {
+	b, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(destDir, DownloadIndexFile), b, 0644)
+}
+
+// attachmentPath render the name template, the rendered path never leaves the destination directory
+func attachmentPath(template string, file *DownloadedFile, index int) (string, error) {
+	ext := path.Ext(file.Name)
+	replacer := strings.NewReplacer(
+		"{recordId}", safeFileName(file.RecordId),
+		"{token}", safeFileName(file.Token),
+		"{name}", safeFileName(file.Name),
+		"{base}", safeFileName(strings.TrimSuffix(file.Name, ext)),
+		"{ext}", strings.TrimPrefix(safeFileName(ext), "."),
+		"{index}", strconv.Itoa(index),
+	)
+	p := path.Clean(replacer.Replace(filepath.ToSlash(template)))
+	if p == "." || path.IsAbs(p) || p == ".." || strings.HasPrefix(p, "../") || p == DownloadIndexFile {
+		msg := fmt.Sprintf("Invalid name template %s, the path is %s", template, p)
+		return "", aterror.NewSDKError(400, msg, "ClientError.InvalidArgument")
+	}
+	return p, nil
+}
+
+// uniquePath suffix the path if it's used by another token, such as: rec1/a-1.txt
+func uniquePath(p string, token string, paths map[string]string) string {
+	ext := path.Ext(p)
+	base := strings.TrimSuffix(p, ext)
+	unique := p
+	for i := 1; ; i++ {
+		if owner, ok := paths[unique]; !ok || owner == token {
+			break
+		}
+		unique = fmt.Sprintf("%s-%d%s", base, i, ext)
+	}
+	paths[unique] = token
+	return unique
+}
+
+// safeFileName replace the path separators of the name
+func safeFileName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r < ' ' {
+			return '_'
+		}
+		return r
+	}, name)
+	if name == "." || name == ".." {
+		return "_"
+	}
+	return name
+}
+
+// downloadWithRetry download the attachment, retry the network errors and the server errors
+func downloadWithRetry(opts *DownloadOptions, attachment *Attachment, destDir string, file *DownloadedFile) (err error) {
+	delay := opts.RetryDelay
+	for attempt := 0; ; attempt++ {
+		var retry bool
+		retry, err = download(opts.HTTPClient, attachment, destDir, file)
+		if err == nil || !retry || attempt >= opts.Retries {
+			return err
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// download fetch the attachment to the `.part` file, and rename it after the size is verified.
+// the part file is kept after a failure, so the next attempt resumes from it.
+func download(client *http.Client, attachment *Attachment, destDir string, file *DownloadedFile) (retry bool, err error) {
+	target := filepath.Join(destDir, filepath.FromSlash(file.Path))
+	part := target + ".part"
+	if err = os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return false, err
+	}
+	var offset int64
+	if info, err := os.Stat(part); err == nil {
+		offset = info.Size()
+	}
+	request, err := http.NewRequest(http.MethodGet, *attachment.Url, nil)
+	if err != nil {
+		return false, err
+	}
+	if offset > 0 {
+		request.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return true, err
+	}
+	defer response.Body.Close()
+	flags := os.O_CREATE | os.O_WRONLY
+	switch response.StatusCode {
+	case http.StatusPartialContent:
+		flags |= os.O_APPEND
+	case http.StatusOK:
+		flags |= os.O_TRUNC
+	case http.StatusRequestedRangeNotSatisfiable:
+		// the part file is broken, start over
+		_ = os.Remove(part)
+		return true, fmt.Errorf("unexpected status %s", response.Status)
+	default:
+		retry = response.StatusCode >= 500 || response.StatusCode == http.StatusTooManyRequests
+		return retry, fmt.Errorf("unexpected status %s", response.Status)
+	}
+	f, err := os.OpenFile(part, flags, 0644)
+	if err != nil {
+		return false, err
+	}
+	_, err = io.Copy(f, response.Body)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return true, err
+	}
+	size, checksum, err := fileChecksum(part)
+	if err != nil {
+		return false, err
+	}
+	if attachment.Size != nil && *attachment.Size > 0 && size != *attachment.Size {
+		_ = os.Remove(part)
+		return true, fmt.Errorf("size mismatch, expect %d but got %d", *attachment.Size, size)
+	}
+	if err = os.Rename(part, target); err != nil {
+		return false, err
+	}
+	file.Size, file.Sha256 = size, checksum
+	return false, nil
+}
+
+func fileChecksum(filePath string) (size int64, checksum string, err error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return 0, "", err
+	}
+	defer f.Close()
+	hash := sha256.New()
+	if size, err = io.Copy(hash, f); err != nil {
+		return 0, "", err
+	}
+	return size, hex.EncodeToString(hash.Sum(nil)), nil
+}
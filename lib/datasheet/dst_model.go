@@ -360,6 +360,12 @@ type DescribeRecordResponse struct {
 	Data *RecordPagination `json:"data"`
 }
 
+type DeleteRecordsResponse struct {
+	*athttp.BaseResponse
+	// api response data, such as: true
+	Data interface{} `json:"data"`
+}
+
 type UploadResponse struct {
 	*athttp.BaseResponse
 	// api response data
@@ -385,3 +391,50 @@ type DescribeViewsResponse struct {
 	// api response data
 	Data *ViewsResponse `json:"data"`
 }
+
+type CreateFieldRequest struct {
+	*athttp.BaseRequest
+	// field type. required: yes.
+	Type *FieldType `json:"type,omitempty" name:"type"`
+	// field name. required: yes.
+	Name *string `json:"name,omitempty" name:"name"`
+	// field property, such as: *SelectFieldProperty. required: depends on the field type.
+	Property interface{} `json:"property,omitempty" name:"property"`
+}
+
+type UpdateFieldRequest struct {
+	*athttp.BaseRequest
+	// such as: `fld*****`. required: yes.
+	FieldId *string `json:"-"`
+	// field type. required: yes.
+	Type *FieldType `json:"type,omitempty" name:"type"`
+	// field name. required: no.
+	Name *string `json:"name,omitempty" name:"name"`
+	// field property, such as: *SelectFieldProperty. required: depends on the field type.
+	Property interface{} `json:"property,omitempty" name:"property"`
+}
+
+type DeleteFieldRequest struct {
+	*athttp.BaseRequest
+	// such as: `fld*****`. required: yes.
+	FieldId *string `json:"-"`
+}
+
+type FieldBaseInfo struct {
+	// field id
+	Id *string `json:"id,omitempty" name:"id"`
+	// field name
+	Name *string `json:"name,omitempty" name:"name"`
+}
+
+type FieldResponse struct {
+	*athttp.BaseResponse
+	// api response data
+	Data *FieldBaseInfo `json:"data"`
+}
+
+type DeleteFieldResponse struct {
+	*athttp.BaseResponse
+	// api response data, such as: true
+	Data interface{} `json:"data"`
+}
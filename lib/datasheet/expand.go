@@ -0,0 +1,207 @@
+package datasheet
+
+import (
+	"fmt"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/common"
+	aterror "github.com/apitable/apitable-sdks/apitable.go/lib/common/error"
+)
+
+// the max count of the record ids of a query
+const maxRecordIds = 100
+
+// ExpandedRecord the record with the linked records of the link fields
+type ExpandedRecord struct {
+	*Record
+	// the linked records by the link field name, in the order of the link cell
+	Links map[string][]*ExpandedRecord `json:"links,omitempty"`
+	// the record is expanded by an ancestor already, its links are not expanded again
+	Cycle bool `json:"cycle,omitempty"`
+}
+
+// linkedField the link field and its foreign datasheet
+type linkedField struct {
+	name      string
+	datasheet string
+}
+
+// expander fetch the linked records, the fields and the records are cached during one call
+type expander struct {
+	source  *Datasheet
+	fields  map[string][]*linkedField
+	records map[string]map[string]*Record
+}
+
+// ExpandLinks fetch the records linked by the link field, and attach them to the records.
+//
+// * depth is the levels to expand, the link fields of the linked records are expanded from the second level.
+// * the linked records are queried by `RecordIds` in chunks of 100, and each record is queried once.
+// * the record linked by its ancestor is marked as Cycle and not expanded again.
+func (c *Datasheet) ExpandLinks(records []*Record, linkField string, depth int) (expanded []*ExpandedRecord, err error) {
+	if depth < 1 {
+		depth = 1
+	}
+	e := &expander{source: c, fields: map[string][]*linkedField{}, records: map[string]map[string]*Record{}}
+	links, err := e.linkFields(c.DatasheetId)
+	if err != nil {
+		return nil, err
+	}
+	var link *linkedField
+	for _, field := range links {
+		if field.name == linkField {
+			link = field
+		}
+	}
+	if link == nil {
+		msg := fmt.Sprintf("The field %s is not a link field of the datasheet %s", linkField, c.DatasheetId)
+		return nil, aterror.NewSDKError(400, msg, "ClientError.InvalidArgument")
+	}
+	// prefetch the records level by level, so the ids of a level are queried together
+	level := map[*linkedField][]*Record{link: records}
+	for i := 0; i < depth; i++ {
+		next := map[*linkedField][]*Record{}
+		for field, levelRecords := range level {
+			fetched, err := e.fetch(field.datasheet, linkIds(levelRecords, field.name))
+			if err != nil {
+				return nil, err
+			}
+			if i == depth-1 || len(fetched) == 0 {
+				continue
+			}
+			foreignLinks, err := e.linkFields(field.datasheet)
+			if err != nil {
+				return nil, err
+			}
+			for _, foreignLink := range foreignLinks {
+				next[foreignLink] = append(next[foreignLink], fetched...)
+			}
+		}
+		level = next
+	}
+	expanded = make([]*ExpandedRecord, 0, len(records))
+	for _, record := range records {
+		path := map[string]bool{}
+		if record.BaseRecord != nil && record.RecordId != nil {
+			path[c.DatasheetId+"/"+*record.RecordId] = true
+		}
+		expanded = append(expanded, e.expand(record, []*linkedField{link}, depth, path))
+	}
+	return expanded, nil
+}
+
+// linkFields get the link fields of the datasheet
+func (e *expander) linkFields(datasheetId string) ([]*linkedField, error) {
+	if links, ok := e.fields[datasheetId]; ok {
+		return links, nil
+	}
+	fields, err := e.datasheet(datasheetId).DescribeFields(nil)
+	if err != nil {
+		return nil, err
+	}
+	links := []*linkedField{}
+	for _, field := range fields {
+		if fieldTypeValue(field.Type) != FieldType_MagicLink || field.Name == nil || field.Property == nil {
+			continue
+		}
+		property := field.MagicLinkFieldProperty()
+		if property == nil || property.ForeignDatasheetId == nil {
+			continue
+		}
+		links = append(links, &linkedField{name: *field.Name, datasheet: *property.ForeignDatasheetId})
+	}
+	e.fields[datasheetId] = links
+	return links, nil
+}
+
+func (e *expander) datasheet(datasheetId string) *Datasheet {
+	return &Datasheet{Client: e.source.Client, DatasheetId: datasheetId, SpaceId: e.source.SpaceId}
+}
+
+// fetch query the records not cached, and return the records of the ids
+func (e *expander) fetch(datasheetId string, ids []string) ([]*Record, error) {
+	cached, ok := e.records[datasheetId]
+	if !ok {
+		cached = map[string]*Record{}
+		e.records[datasheetId] = cached
+	}
+	missing := []*string{}
+	for _, id := range ids {
+		if _, ok := cached[id]; !ok {
+			// mark the id as fetched, the deleted records are not returned
+			cached[id] = nil
+			missing = append(missing, common.StringPtr(id))
+		}
+	}
+	dst := e.datasheet(datasheetId)
+	for start := 0; start < len(missing); start += maxRecordIds {
+		end := start + maxRecordIds
+		if end > len(missing) {
+			end = len(missing)
+		}
+		request := NewDescribeRecordRequest()
+		request.RecordIds = missing[start:end]
+		request.PageSize = common.Int64Ptr(maxRecordIds)
+		pagination, err := dst.DescribeRecords(request)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range pagination.Records {
+			if record.BaseRecord != nil && record.RecordId != nil {
+				cached[*record.RecordId] = record
+			}
+		}
+	}
+	records := []*Record{}
+	for _, id := range ids {
+		if record := cached[id]; record != nil {
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+// expand attach the linked records from the cache, path is the records from the root
+func (e *expander) expand(record *Record, links []*linkedField, depth int, path map[string]bool) *ExpandedRecord {
+	expanded := &ExpandedRecord{Record: record, Links: map[string][]*ExpandedRecord{}}
+	for _, link := range links {
+		linked := []*ExpandedRecord{}
+		for _, id := range linkIds([]*Record{record}, link.name) {
+			foreign := e.records[link.datasheet][id]
+			if foreign == nil {
+				continue
+			}
+			key := link.datasheet + "/" + id
+			if path[key] {
+				linked = append(linked, &ExpandedRecord{Record: foreign, Cycle: true})
+				continue
+			}
+			if depth <= 1 {
+				linked = append(linked, &ExpandedRecord{Record: foreign})
+				continue
+			}
+			path[key] = true
+			linked = append(linked, e.expand(foreign, e.fields[link.datasheet], depth-1, path))
+			delete(path, key)
+		}
+		expanded.Links[link.name] = linked
+	}
+	return expanded
+}
+
+// linkIds get the distinct linked record ids of the records
+func linkIds(records []*Record, linkField string) []string {
+	ids := []string{}
+	seen := map[string]bool{}
+	for _, record := range records {
+		if record.BaseRecord == nil || record.Fields == nil {
+			continue
+		}
+		values, _ := (*record.Fields)[linkField].([]interface{})
+		for _, value := range values {
+			if id, ok := value.(string); ok && !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
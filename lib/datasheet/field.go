@@ -3,6 +3,7 @@ package datasheet
 import (
 	"encoding/json"
 	"fmt"
+	aterror "github.com/apitable/apitable-sdks/apitable.go/lib/common/error"
 	athttp "github.com/apitable/apitable-sdks/apitable.go/lib/common/http"
 )
 
@@ -37,6 +38,22 @@ const (
 	FieldType_LastModifiedBy   FieldType = "LastModifiedBy"
 )
 
+// the field types computed by the server
+var computedFieldTypes = map[FieldType]bool{
+	FieldType_MagicLookUp:      true,
+	FieldType_Formula:          true,
+	FieldType_AutoNumber:       true,
+	FieldType_CreatedTime:      true,
+	FieldType_LastModifiedTime: true,
+	FieldType_CreatedBy:        true,
+	FieldType_LastModifiedBy:   true,
+}
+
+// IsComputed the field values are computed by the server, they can't be written by api
+func (t FieldType) IsComputed() bool {
+	return computedFieldTypes[t]
+}
+
 // IDatasheetField define how to obtain the field properties
 type IDatasheetField interface {
 	SingleTextFieldProperty() *SingleTextFieldProperty
@@ -238,3 +255,254 @@ func (c *Datasheet) DescribeFields(request *DescribeFieldsRequest) (fields []*Da
 	}
 	return response.Data.Fields, nil
 }
+
+const spaceFieldPath = "/fusion/v1/spaces/%s/datasheets/%s/fields"
+const spaceFieldDetailPath = "/fusion/v1/spaces/%s/datasheets/%s/fields/%s"
+
+// the max precision of the number, currency and percent field
+const maxFieldPrecision = 4
+
+// the max value of the rating field
+const maxRatingValue = 10
+
+func NewCreateFieldRequest() (request *CreateFieldRequest) {
+	request = &CreateFieldRequest{
+		BaseRequest: &athttp.BaseRequest{},
+	}
+	return
+}
+
+func NewUpdateFieldRequest() (request *UpdateFieldRequest) {
+	request = &UpdateFieldRequest{
+		BaseRequest: &athttp.BaseRequest{},
+	}
+	return
+}
+
+func NewDeleteFieldRequest() (request *DeleteFieldRequest) {
+	request = &DeleteFieldRequest{
+		BaseRequest: &athttp.BaseRequest{},
+	}
+	return
+}
+
+func NewFieldResponse() (response *FieldResponse) {
+	response = &FieldResponse{
+		BaseResponse: &athttp.BaseResponse{},
+	}
+	return
+}
+
+// NewDeleteFieldResponse the data of the delete response is true instead of the field
+func NewDeleteFieldResponse() (response *DeleteFieldResponse) {
+	response = &DeleteFieldResponse{
+		BaseResponse: &athttp.BaseResponse{},
+	}
+	return
+}
+
+func (c *Datasheet) checkSpaceId() error {
+	if c.SpaceId == "" {
+		return aterror.NewSDKError(400, "The SpaceId of the datasheet is required", "ClientError.InvalidArgument")
+	}
+	return nil
+}
+
+// CreateField used to create a field
+//
+// * `SpaceId` of the datasheet is required.
+// * the property is validated by the field type, see `ValidateFieldProperty`.
+func (c *Datasheet) CreateField(request *CreateFieldRequest) (field *FieldBaseInfo, err error) {
+	if request == nil {
+		request = NewCreateFieldRequest()
+	}
+	if err = c.checkSpaceId(); err != nil {
+		return nil, err
+	}
+	if request.Type == nil || request.Name == nil || *request.Name == "" {
+		return nil, aterror.NewSDKError(400, "The type and name of the field are required", "ClientError.InvalidArgument")
+	}
+	if err = ValidateFieldProperty(*request.Type, request.Property); err != nil {
+		return nil, err
+	}
+	request.Init().SetPath(fmt.Sprintf(spaceFieldPath, c.SpaceId, c.DatasheetId))
+	request.SetContentType(athttp.JsonContent)
+	request.SetHttpMethod(athttp.POST)
+	response := NewFieldResponse()
+	err = c.Send(request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response.Data, nil
+}
+
+// UpdateField used to update the name, type or property of a field
+//
+// * `SpaceId` of the datasheet is required.
+// * the property is validated by the field type, see `ValidateFieldProperty`.
+func (c *Datasheet) UpdateField(request *UpdateFieldRequest) (field *FieldBaseInfo, err error) {
+	if request == nil {
+		request = NewUpdateFieldRequest()
+	}
+	if err = c.checkSpaceId(); err != nil {
+		return nil, err
+	}
+	if request.FieldId == nil || request.Type == nil {
+		return nil, aterror.NewSDKError(400, "The id and type of the field are required", "ClientError.InvalidArgument")
+	}
+	if err = ValidateFieldProperty(*request.Type, request.Property); err != nil {
+		return nil, err
+	}
+	request.Init().SetPath(fmt.Sprintf(spaceFieldDetailPath, c.SpaceId, c.DatasheetId, *request.FieldId))
+	request.SetContentType(athttp.JsonContent)
+	request.SetHttpMethod(athttp.PATCH)
+	response := NewFieldResponse()
+	err = c.Send(request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response.Data, nil
+}
+
+// DeleteField used to delete a field
+//
+// * `SpaceId` of the datasheet is required.
+func (c *Datasheet) DeleteField(request *DeleteFieldRequest) (err error) {
+	if request == nil {
+		request = NewDeleteFieldRequest()
+	}
+	if err = c.checkSpaceId(); err != nil {
+		return err
+	}
+	if request.FieldId == nil {
+		return aterror.NewSDKError(400, "The id of the field is required", "ClientError.InvalidArgument")
+	}
+	request.Init().SetPath(fmt.Sprintf(spaceFieldDetailPath, c.SpaceId, c.DatasheetId, *request.FieldId))
+	request.SetHttpMethod(athttp.DELETE)
+	response := NewDeleteFieldResponse()
+	err = c.Send(request, response)
+	return
+}
+
+func invalidProperty(fieldType FieldType, reason string) error {
+	msg := fmt.Sprintf("Invalid property of %s field: %s", fieldType, reason)
+	return aterror.NewSDKError(400, msg, "ClientError.InvalidFieldProperty")
+}
+
+func validPrecision(precision *int) bool {
+	return precision == nil || (*precision >= 0 && *precision <= maxFieldPrecision)
+}
+
+// ValidateFieldProperty check the property matches the field type
+//
+// * the property must be the typed property struct of the field type, such as: *SelectFieldProperty for SingleSelect.
+// * the field types without property, such as: Text, require a nil property.
+func ValidateFieldProperty(fieldType FieldType, property interface{}) error {
+	switch fieldType {
+	case FieldType_Text, FieldType_URL, FieldType_Phone, FieldType_Attachment:
+		if property != nil {
+			return invalidProperty(fieldType, "the field has no property")
+		}
+	case FieldType_SingleText:
+		if _, ok := property.(*SingleTextFieldProperty); property != nil && !ok {
+			return invalidProperty(fieldType, "expect *SingleTextFieldProperty")
+		}
+	case FieldType_SingleSelect, FieldType_MultiSelect:
+		p, ok := property.(*SelectFieldProperty)
+		if !ok || p == nil {
+			return invalidProperty(fieldType, "expect *SelectFieldProperty")
+		}
+		names := map[string]bool{}
+		for _, option := range p.Options {
+			if option == nil || option.Name == nil || *option.Name == "" {
+				return invalidProperty(fieldType, "the option name is required")
+			}
+			if names[*option.Name] {
+				return invalidProperty(fieldType, "duplicate option "+*option.Name)
+			}
+			names[*option.Name] = true
+		}
+	case FieldType_Number:
+		p, ok := property.(*NumberFieldProperty)
+		if !ok || p == nil {
+			return invalidProperty(fieldType, "expect *NumberFieldProperty")
+		}
+		if !validPrecision(p.Precision) {
+			return invalidProperty(fieldType, "the precision is out of range")
+		}
+	case FieldType_Currency:
+		p, ok := property.(*CurrencyFieldProperty)
+		if !ok || p == nil {
+			return invalidProperty(fieldType, "expect *CurrencyFieldProperty")
+		}
+		if !validPrecision(p.Precision) {
+			return invalidProperty(fieldType, "the precision is out of range")
+		}
+	case FieldType_Percent:
+		p, ok := property.(*PercentFieldProperty)
+		if !ok || p == nil {
+			return invalidProperty(fieldType, "expect *PercentFieldProperty")
+		}
+		if !validPrecision(p.Precision) {
+			return invalidProperty(fieldType, "the precision is out of range")
+		}
+	case FieldType_DateTime, FieldType_CreatedTime, FieldType_LastModifiedTime:
+		p, ok := property.(*DateTimeFieldProperty)
+		if !ok || p == nil {
+			return invalidProperty(fieldType, "expect *DateTimeFieldProperty")
+		}
+	case FieldType_Member:
+		p, ok := property.(*MemberFieldProperty)
+		if !ok || p == nil {
+			return invalidProperty(fieldType, "expect *MemberFieldProperty")
+		}
+	case FieldType_Checkbox:
+		p, ok := property.(*CheckboxFieldProperty)
+		if !ok || p == nil {
+			return invalidProperty(fieldType, "expect *CheckboxFieldProperty")
+		}
+		if p.Icon == nil || *p.Icon == "" {
+			return invalidProperty(fieldType, "the icon is required")
+		}
+	case FieldType_Rating:
+		p, ok := property.(*RatingFieldProperty)
+		if !ok || p == nil {
+			return invalidProperty(fieldType, "expect *RatingFieldProperty")
+		}
+		if p.Icon == nil || *p.Icon == "" {
+			return invalidProperty(fieldType, "the icon is required")
+		}
+		if p.Max == nil || *p.Max < 1 || *p.Max > maxRatingValue {
+			return invalidProperty(fieldType, "the max is out of range")
+		}
+	case FieldType_MagicLink:
+		p, ok := property.(*MagicLinkFieldProperty)
+		if !ok || p == nil {
+			return invalidProperty(fieldType, "expect *MagicLinkFieldProperty")
+		}
+		if p.ForeignDatasheetId == nil || *p.ForeignDatasheetId == "" {
+			return invalidProperty(fieldType, "the foreign datasheet id is required")
+		}
+	case FieldType_MagicLookUp:
+		p, ok := property.(*MagicLookUpFieldProperty)
+		if !ok || p == nil {
+			return invalidProperty(fieldType, "expect *MagicLookUpFieldProperty")
+		}
+		if p.RelatedLinkFieldId == nil || p.TargetFieldId == nil {
+			return invalidProperty(fieldType, "the related link field id and target field id are required")
+		}
+	case FieldType_Formula:
+		p, ok := property.(*FormulaFieldProperty)
+		if !ok || p == nil {
+			return invalidProperty(fieldType, "expect *FormulaFieldProperty")
+		}
+		if p.Expression == nil || *p.Expression == "" {
+			return invalidProperty(fieldType, "the expression is required")
+		}
+	case FieldType_AutoNumber, FieldType_CreatedBy, FieldType_LastModifiedBy:
+		// the properties are maintained by the datasheet.
+	default:
+		return invalidProperty(fieldType, "unknown field type")
+	}
+	return nil
+}
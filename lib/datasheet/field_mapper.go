@@ -0,0 +1,340 @@
+package datasheet
+
+import (
+	"fmt"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/common"
+	aterror "github.com/apitable/apitable-sdks/apitable.go/lib/common/error"
+	"strings"
+	"sync"
+)
+
+// FieldMapper translate the field key between field name and field id.
+//
+// * the mapping is loaded by `DescribeFields`, and refreshed automatically once when a lookup misses.
+// * the keys still unknown after the refresh are cached and never refresh again until Refresh is called.
+// * in strict mode, an unknown field returns an error, otherwise the key is kept as it is.
+type FieldMapper struct {
+	datasheet *Datasheet
+	strict    bool
+	mu        sync.RWMutex
+	nameToId  map[string]string
+	idToName  map[string]string
+	// the unknown keys after the automatic refresh
+	misses map[string]bool
+}
+
+// NewFieldMapper init field mapper instance, and load the fields of the datasheet
+func NewFieldMapper(datasheet *Datasheet, strict bool) (mapper *FieldMapper, err error) {
+	mapper = &FieldMapper{
+		datasheet: datasheet,
+		strict:    strict,
+	}
+	err = mapper.Refresh()
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// NewFieldMapperFromFields init field mapper instance from the fields already queried, it will never refresh.
+func NewFieldMapperFromFields(fields []*DatasheetField, strict bool) (mapper *FieldMapper) {
+	mapper = &FieldMapper{
+		strict: strict,
+	}
+	mapper.load(fields)
+	return
+}
+
+// Refresh reload the field name and id mapping from the datasheet, and forget the unknown keys
+func (m *FieldMapper) Refresh() (err error) {
+	if err = m.reload(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	m.misses = nil
+	m.mu.Unlock()
+	return nil
+}
+
+func (m *FieldMapper) reload() (err error) {
+	if m.datasheet == nil {
+		return nil
+	}
+	fields, err := m.datasheet.DescribeFields(nil)
+	if err != nil {
+		return err
+	}
+	m.load(fields)
+	return nil
+}
+
+func (m *FieldMapper) load(fields []*DatasheetField) {
+	nameToId := make(map[string]string, len(fields))
+	idToName := make(map[string]string, len(fields))
+	for _, field := range fields {
+		if field.Id == nil || field.Name == nil {
+			continue
+		}
+		nameToId[*field.Name] = *field.Id
+		idToName[*field.Id] = *field.Name
+	}
+	m.mu.Lock()
+	m.nameToId = nameToId
+	m.idToName = idToName
+	m.mu.Unlock()
+}
+
+// lookup find the field id and name by the field name or id
+func (m *FieldMapper) lookup(key string) (id string, name string, ok bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if name, ok = m.idToName[key]; ok {
+		return key, name, true
+	}
+	if id, ok = m.nameToId[key]; ok {
+		return id, key, true
+	}
+	return "", "", false
+}
+
+// missed the key is still unknown after the last automatic refresh
+func (m *FieldMapper) missed(key string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.misses[key]
+}
+
+func (m *FieldMapper) resolve(key string) (id string, name string, err error) {
+	id, name, ok := m.lookup(key)
+	if !ok && m.datasheet != nil && !m.missed(key) {
+		// the field may be renamed or created after loading, refresh once.
+		if err = m.reload(); err != nil {
+			return "", "", err
+		}
+		if id, name, ok = m.lookup(key); !ok {
+			m.mu.Lock()
+			if m.misses == nil {
+				m.misses = map[string]bool{}
+			}
+			m.misses[key] = true
+			m.mu.Unlock()
+		}
+	}
+	if !ok {
+		if m.strict {
+			msg := fmt.Sprintf("Unknown field: %s", key)
+			return "", "", aterror.NewSDKError(400, msg, "ClientError.UnknownField")
+		}
+		return key, key, nil
+	}
+	return id, name, nil
+}
+
+// FieldId get the field id by the field name or id
+func (m *FieldMapper) FieldId(key string) (id string, err error) {
+	id, _, err = m.resolve(key)
+	return
+}
+
+// FieldName get the field name by the field name or id
+func (m *FieldMapper) FieldName(key string) (name string, err error) {
+	_, name, err = m.resolve(key)
+	return
+}
+
+// FieldToIds translate the keys of the field map to field ids
+func (m *FieldMapper) FieldToIds(field *Field) (*Field, error) {
+	return m.translateField(field, m.FieldId)
+}
+
+// FieldToNames translate the keys of the field map to field names
+func (m *FieldMapper) FieldToNames(field *Field) (*Field, error) {
+	return m.translateField(field, m.FieldName)
+}
+
+func (m *FieldMapper) translateField(field *Field, translate func(string) (string, error)) (*Field, error) {
+	if field == nil {
+		return nil, nil
+	}
+	result := make(Field, len(*field))
+	for key, value := range *field {
+		newKey, err := translate(key)
+		if err != nil {
+			return nil, err
+		}
+		result[newKey] = value
+	}
+	return &result, nil
+}
+
+// SortToIds translate the sorted fields to field ids
+func (m *FieldMapper) SortToIds(sorts []*Sort) ([]*Sort, error) {
+	return m.translateSort(sorts, m.FieldId)
+}
+
+// SortToNames translate the sorted fields to field names
+func (m *FieldMapper) SortToNames(sorts []*Sort) ([]*Sort, error) {
+	return m.translateSort(sorts, m.FieldName)
+}
+
+func (m *FieldMapper) translateSort(sorts []*Sort, translate func(string) (string, error)) ([]*Sort, error) {
+	if sorts == nil {
+		return nil, nil
+	}
+	result := make([]*Sort, len(sorts))
+	for i, sort := range sorts {
+		if sort == nil || sort.Field == nil {
+			result[i] = sort
+			continue
+		}
+		key, err := translate(*sort.Field)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = &Sort{Field: common.StringPtr(key), Order: sort.Order}
+	}
+	return result, nil
+}
+
+// FieldsToIds translate the field list to field ids
+func (m *FieldMapper) FieldsToIds(fields []*string) ([]*string, error) {
+	return m.translateFields(fields, m.FieldId)
+}
+
+// FieldsToNames translate the field list to field names
+func (m *FieldMapper) FieldsToNames(fields []*string) ([]*string, error) {
+	return m.translateFields(fields, m.FieldName)
+}
+
+func (m *FieldMapper) translateFields(fields []*string, translate func(string) (string, error)) ([]*string, error) {
+	if fields == nil {
+		return nil, nil
+	}
+	result := make([]*string, len(fields))
+	for i, field := range fields {
+		if field == nil {
+			continue
+		}
+		key, err := translate(*field)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = common.StringPtr(key)
+	}
+	return result, nil
+}
+
+// FormulaToIds translate the field references of the formula to field ids, such as: {name} => {fld*****}
+func (m *FieldMapper) FormulaToIds(expression string) (string, error) {
+	return m.translateFormula(expression, m.FieldId)
+}
+
+// FormulaToNames translate the field references of the formula to field names, such as: {fld*****} => {name}
+func (m *FieldMapper) FormulaToNames(expression string) (string, error) {
+	return m.translateFormula(expression, m.FieldName)
+}
+
+// translateFormula translate the field references of a formula, such as: {field name} or {fld*****}
+//
+// * the braces in the string literals quoted by ' or " are kept, such as: "{literal}"
+func (m *FieldMapper) translateFormula(expression string, translate func(string) (string, error)) (string, error) {
+	var result strings.Builder
+	var quote byte
+	for i := 0; i < len(expression); i++ {
+		c := expression[i]
+		if quote != 0 {
+			result.WriteByte(c)
+			if c == '\\' && i+1 < len(expression) {
+				i++
+				result.WriteByte(expression[i])
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '\'', '"':
+			quote = c
+		case '{':
+			end := strings.IndexAny(expression[i+1:], "{}")
+			if end > 0 && expression[i+1+end] == '}' {
+				key, err := translate(expression[i+1 : i+1+end])
+				if err != nil {
+					return "", err
+				}
+				result.WriteString("{" + key + "}")
+				i += end + 1
+				continue
+			}
+		}
+		result.WriteByte(c)
+	}
+	return result.String(), nil
+}
+
+// RequestToIds translate the fields, sort and formula of the request to field ids, and return the field key as id.
+func (m *FieldMapper) RequestToIds(request *DescribeRecordRequest) (err error) {
+	err = m.translateRequest(request, m.FieldsToIds, m.SortToIds, m.FormulaToIds)
+	if err == nil {
+		request.FieldKey = common.StringPtr(common.FieldKeyId)
+	}
+	return
+}
+
+// RequestToNames translate the fields, sort and formula of the request to field names, and return the field key as name.
+func (m *FieldMapper) RequestToNames(request *DescribeRecordRequest) (err error) {
+	err = m.translateRequest(request, m.FieldsToNames, m.SortToNames, m.FormulaToNames)
+	if err == nil {
+		request.FieldKey = common.StringPtr(common.FieldKeyName)
+	}
+	return
+}
+
+func (m *FieldMapper) translateRequest(request *DescribeRecordRequest,
+	fields func([]*string) ([]*string, error),
+	sorts func([]*Sort) ([]*Sort, error),
+	formula func(string) (string, error)) (err error) {
+	if request == nil {
+		return nil
+	}
+	if request.Fields, err = fields(request.Fields); err != nil {
+		return
+	}
+	if request.Sort, err = sorts(request.Sort); err != nil {
+		return
+	}
+	if request.FilterByFormula != nil {
+		expression, err := formula(*request.FilterByFormula)
+		if err != nil {
+			return err
+		}
+		request.FilterByFormula = common.StringPtr(expression)
+	}
+	return nil
+}
+
+// RecordsToIds translate the field keys of the records to field ids
+func (m *FieldMapper) RecordsToIds(records []*Record) (err error) {
+	for _, record := range records {
+		if record == nil || record.BaseRecord == nil {
+			continue
+		}
+		if record.Fields, err = m.FieldToIds(record.Fields); err != nil {
+			return
+		}
+	}
+	return nil
+}
+
+// RecordsToNames translate the field keys of the records to field names
+func (m *FieldMapper) RecordsToNames(records []*Record) (err error) {
+	for _, record := range records {
+		if record == nil || record.BaseRecord == nil {
+			continue
+		}
+		if record.Fields, err = m.FieldToNames(record.Fields); err != nil {
+			return
+		}
+	}
+	return nil
+}
@@ -0,0 +1,440 @@
+package datasheet
+
+import (
+	"encoding/csv"
+	"fmt"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/common"
+	aterror "github.com/apitable/apitable-sdks/apitable.go/lib/common/error"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// RejectReasonColumn the column appended to the reject csv, describing why the row is rejected
+const RejectReasonColumn = "reason"
+
+// the default layouts to parse the date cells, tried in order
+var defaultDateLayouts = []string{
+	"2006-01-02",
+	"2006-01-02 15:04",
+	"2006-01-02 15:04:05",
+	"2006/01/02",
+	"2006/01/02 15:04",
+	"2006/01/02 15:04:05",
+	time.RFC3339,
+}
+
+// ImportOptions the options of the csv import
+type ImportOptions struct {
+	// map the header to the field name or id, the others are matched by field name or id. required: no.
+	Columns map[string]string
+	// the fields of the datasheet, queried by `DescribeFields` if empty. required: no.
+	Fields []*DatasheetField
+	// the csv separator, the default is comma
+	Comma rune
+	// the layouts to parse the date cells, tried in order. the default is such as: 2006-01-02, 2006-01-02 15:04:05
+	DateLayouts []string
+	// the location of the dates without time zone, the default is UTC
+	Location *time.Location
+	// the decimal separator of the numbers, the default is "."
+	DecimalSeparator string
+	// the thousands separator of the numbers, the default is "," or "." if the decimal separator is ","
+	ThousandsSeparator string
+	// the delimiter of the multi select, member and link cells, the default is ","
+	ListDelimiter string
+	// resolve the member by name if it's not found in the field options. required: no.
+	// such as: the unit field value of `Space.FindMemberByName`.
+	ResolveMember func(name string) (*UnitFieldValue, error)
+	// the count of records per create request, the default and max is 10
+	BatchSize int
+	// the writer of the rejected rows, with the original header and the reason column. required: no.
+	Rejects io.Writer
+	// called after each batch is written. required: no.
+	Progress func(progress ImportProgress)
+}
+
+// ImportProgress the progress of the import
+type ImportProgress struct {
+	// the count of the rows read
+	Rows int
+	// the count of the records created
+	Imported int
+	// the count of the rows rejected
+	Rejected int
+}
+
+// ImportResult the result of the import
+type ImportResult struct {
+	ImportProgress
+	// the ids of the created records, in row order
+	RecordIds []string
+	// the headers not matching any field
+	IgnoredColumns []string
+}
+
+// importColumn the field of a csv column
+type importColumn struct {
+	index int
+	field *DatasheetField
+	name  string
+	// the select option names by lower case, for the select fields
+	options map[string]string
+	// the member values by name, for the member fields
+	members map[string]*UnitFieldValue
+}
+
+// importRow the converted row waiting to be written
+type importRow struct {
+	cells  []string
+	fields Field
+}
+
+// ImportCSV create the records from the csv, the first row is the header.
+//
+// * the headers are matched to the fields by name or id, the cells are converted by the field type.
+// * the rows failed to convert or write are written to Rejects with the reason, the import goes on.
+// * the error is returned only if the header or the fields can't be read.
+func (c *Datasheet) ImportCSV(reader io.Reader, options *ImportOptions) (result *ImportResult, err error) {
+	opts := ImportOptions{}
+	if options != nil {
+		opts = *options
+	}
+	if len(opts.DateLayouts) == 0 {
+		opts.DateLayouts = defaultDateLayouts
+	}
+	if opts.Location == nil {
+		opts.Location = time.UTC
+	}
+	if opts.DecimalSeparator == "" {
+		opts.DecimalSeparator = "."
+	}
+	if opts.ThousandsSeparator == "" {
+		opts.ThousandsSeparator = ","
+		if opts.DecimalSeparator == "," {
+			opts.ThousandsSeparator = "."
+		}
+	}
+	if opts.ThousandsSeparator == opts.DecimalSeparator {
+		msg := fmt.Sprintf("The decimal separator and the thousands separator are the same: %s", opts.DecimalSeparator)
+		return nil, aterror.NewSDKError(400, msg, "ClientError.InvalidArgument")
+	}
+	if opts.ListDelimiter == "" {
+		opts.ListDelimiter = ","
+	}
+	if opts.BatchSize <= 0 || opts.BatchSize > common.MaxWriteRecords {
+		opts.BatchSize = common.MaxWriteRecords
+	}
+	if opts.Fields == nil {
+		if opts.Fields, err = c.DescribeFields(nil); err != nil {
+			return nil, err
+		}
+	}
+	csvReader := csv.NewReader(reader)
+	if opts.Comma != 0 {
+		csvReader.Comma = opts.Comma
+	}
+	csvReader.FieldsPerRecord = -1
+	header, err := csvReader.Read()
+	if err != nil {
+		msg := fmt.Sprintf("Fail to read csv header because %s", err)
+		return nil, aterror.NewSDKError(400, msg, "ClientError.InvalidCSV")
+	}
+	result = &ImportResult{RecordIds: []string{}, IgnoredColumns: []string{}}
+	columns, err := importColumns(header, &opts, result)
+	if err != nil {
+		return nil, err
+	}
+	var rejects *csv.Writer
+	if opts.Rejects != nil {
+		rejects = csv.NewWriter(opts.Rejects)
+		if opts.Comma != 0 {
+			rejects.Comma = opts.Comma
+		}
+		_ = rejects.Write(append(append([]string{}, header...), RejectReasonColumn))
+		defer rejects.Flush()
+	}
+	reject := func(cells []string, reason string) {
+		result.Rejected++
+		if rejects != nil {
+			_ = rejects.Write(append(append([]string{}, cells...), reason))
+		}
+	}
+	batch := []*importRow{}
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		request := NewCreateRecordsRequest()
+		for _, row := range batch {
+			fields := row.fields
+			request.Records = append(request.Records, &Fields{Fields: &fields})
+		}
+		records, err := c.CreateRecords(request)
+		if err != nil {
+			for _, row := range batch {
+				reject(row.cells, err.Error())
+			}
+		} else {
+			result.Imported += len(batch)
+			for _, record := range records {
+				if record.BaseRecord != nil && record.RecordId != nil {
+					result.RecordIds = append(result.RecordIds, *record.RecordId)
+				}
+			}
+		}
+		batch = batch[:0]
+		if opts.Progress != nil {
+			opts.Progress(result.ImportProgress)
+		}
+	}
+	for {
+		cells, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		result.Rows++
+		if err != nil {
+			reject(cells, err.Error())
+			continue
+		}
+		fields, err := convertRow(cells, columns, &opts)
+		if err != nil {
+			reject(cells, err.Error())
+			continue
+		}
+		if len(fields) == 0 {
+			reject(cells, "empty row")
+			continue
+		}
+		batch = append(batch, &importRow{cells: cells, fields: fields})
+		if len(batch) >= opts.BatchSize {
+			flush()
+		}
+	}
+	flush()
+	return result, nil
+}
+
+func importColumns(header []string, opts *ImportOptions, result *ImportResult) ([]*importColumn, error) {
+	byKey := map[string]*DatasheetField{}
+	byLowerName := map[string]*DatasheetField{}
+	for _, field := range opts.Fields {
+		if field.Id != nil {
+			byKey[*field.Id] = field
+		}
+		if field.Name != nil {
+			byKey[*field.Name] = field
+			byLowerName[strings.ToLower(*field.Name)] = field
+		}
+	}
+	columns := []*importColumn{}
+	for i, name := range header {
+		key := strings.TrimSpace(name)
+		if i == 0 {
+			// remove the utf-8 bom written by the spreadsheet applications
+			key = strings.TrimPrefix(key, "\ufeff")
+		}
+		if mapped, ok := opts.Columns[key]; ok {
+			key = mapped
+		}
+		field, ok := byKey[key]
+		if !ok {
+			field, ok = byLowerName[strings.ToLower(key)]
+		}
+		if !ok {
+			result.IgnoredColumns = append(result.IgnoredColumns, name)
+			continue
+		}
+		fieldType := fieldTypeValue(field.Type)
+		// the attachments can't be imported from the csv text
+		if fieldType.IsComputed() || fieldType == FieldType_Attachment {
+			msg := fmt.Sprintf("The column %s can't be imported to the %s field", name, fieldType)
+			return nil, aterror.NewSDKError(400, msg, "ClientError.UnsupportedField")
+		}
+		column := &importColumn{index: i, field: field, name: stringValue(field.Name)}
+		switch fieldType {
+		case FieldType_SingleSelect, FieldType_MultiSelect:
+			if field.Property != nil {
+				column.options = map[string]string{}
+				if property := field.SelectFieldProperty(); property != nil {
+					for _, option := range property.Options {
+						if option.Name != nil {
+							column.options[strings.ToLower(*option.Name)] = *option.Name
+						}
+					}
+				}
+			}
+		case FieldType_Member:
+			column.members = map[string]*UnitFieldValue{}
+			if field.Property != nil {
+				if property := field.MemberFieldProperty(); property != nil {
+					for _, option := range property.Options {
+						if option.Id == nil || option.Name == nil {
+							continue
+						}
+						value := &UnitFieldValue{UnitId: *option.Id, UnitName: *option.Name}
+						if option.Type != nil {
+							value.UnitType = string(*option.Type)
+						}
+						column.members[*option.Name] = value
+					}
+				}
+			}
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+func convertRow(cells []string, columns []*importColumn, opts *ImportOptions) (Field, error) {
+	fields := Field{}
+	for _, column := range columns {
+		if column.index >= len(cells) {
+			continue
+		}
+		text := strings.TrimSpace(cells[column.index])
+		if text == "" {
+			continue
+		}
+		value, err := convertCell(text, column, opts)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", column.name, err)
+		}
+		fields[column.name] = value
+	}
+	return fields, nil
+}
+
+// convertCell convert the cell text by the field type
+func convertCell(text string, column *importColumn, opts *ImportOptions) (FieldValue, error) {
+	field := column.field
+	switch fieldTypeValue(field.Type) {
+	case FieldType_Number, FieldType_Currency:
+		return parseNumber(text, opts)
+	case FieldType_Percent:
+		if strings.HasSuffix(text, "%") {
+			number, err := parseNumber(strings.TrimSuffix(text, "%"), opts)
+			if err != nil {
+				return nil, err
+			}
+			return number / 100, nil
+		}
+		return parseNumber(text, opts)
+	case FieldType_Rating:
+		number, err := parseNumber(text, opts)
+		if err != nil {
+			return nil, err
+		}
+		max := 5
+		if field.Property != nil {
+			if property := field.RatingFieldProperty(); property != nil && property.Max != nil {
+				max = *property.Max
+			}
+		}
+		if number != float64(int(number)) || number < 0 || int(number) > max {
+			return nil, fmt.Errorf("invalid rating %s, the max is %d", text, max)
+		}
+		return int(number), nil
+	case FieldType_DateTime:
+		for _, layout := range opts.DateLayouts {
+			if t, err := time.ParseInLocation(layout, text, opts.Location); err == nil {
+				return t.UnixNano() / int64(time.Millisecond), nil
+			}
+		}
+		return nil, fmt.Errorf("invalid date %s", text)
+	case FieldType_Checkbox:
+		switch strings.ToLower(text) {
+		case "true", "yes", "y", "1", "checked", "on", "是", "✓", "✔":
+			return true, nil
+		case "false", "no", "n", "0", "unchecked", "off", "否":
+			return false, nil
+		}
+		return nil, fmt.Errorf("invalid checkbox %s", text)
+	case FieldType_SingleSelect:
+		return selectOption(text, column)
+	case FieldType_MultiSelect:
+		values := []string{}
+		for _, item := range splitList(text, opts.ListDelimiter) {
+			option, err := selectOption(item, column)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, option)
+		}
+		return values, nil
+	case FieldType_Member:
+		values := []*UnitFieldValue{}
+		for _, name := range splitList(text, opts.ListDelimiter) {
+			member, ok := column.members[name]
+			if !ok && opts.ResolveMember != nil {
+				resolved, err := opts.ResolveMember(name)
+				if err != nil {
+					return nil, err
+				}
+				// remember the resolved member, so it's resolved once.
+				member, ok = resolved, resolved != nil
+				column.members[name] = resolved
+			}
+			if !ok || member == nil {
+				return nil, fmt.Errorf("unknown member %s", name)
+			}
+			values = append(values, member)
+		}
+		return values, nil
+	case FieldType_MagicLink:
+		return splitList(text, opts.ListDelimiter), nil
+	}
+	return text, nil
+}
+
+// isSpaceOrCurrency the whitespace or the currency symbol around the number, such as: $1,000 or 1.000 €
+func isSpaceOrCurrency(r rune) bool {
+	return unicode.IsSpace(r) || unicode.Is(unicode.Sc, r)
+}
+
+// parseNumber parse the number with the locale separators and the currency symbol, the other characters are rejected
+func parseNumber(text string, opts *ImportOptions) (float64, error) {
+	s := strings.TrimFunc(text, isSpaceOrCurrency)
+	// the sign can be before the currency symbol, such as: -$5
+	sign := ""
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		sign, s = s[:1], strings.TrimFunc(s[1:], isSpaceOrCurrency)
+	}
+	s = strings.Replace(s, " ", "", -1)
+	s = strings.Replace(s, opts.ThousandsSeparator, "", -1)
+	s = strings.Replace(s, opts.DecimalSeparator, ".", -1)
+	// strconv accepts inf, nan and the hex numbers, which are not the numbers of the csv
+	if s == "" || strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= '0' && r <= '9') && r != '.' && r != 'e' && r != 'E' && r != '-' && r != '+'
+	}) >= 0 {
+		return 0, fmt.Errorf("invalid number %s", text)
+	}
+	number, err := strconv.ParseFloat(sign+s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %s", text)
+	}
+	return number, nil
+}
+
+func selectOption(text string, column *importColumn) (string, error) {
+	if column.options == nil {
+		// the options are unknown, let the server check it.
+		return text, nil
+	}
+	if option, ok := column.options[strings.ToLower(text)]; ok {
+		return option, nil
+	}
+	return "", fmt.Errorf("unknown option %s", text)
+}
+
+func splitList(text string, delimiter string) []string {
+	values := []string{}
+	for _, item := range strings.Split(text, delimiter) {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+	return values
+}
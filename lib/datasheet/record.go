@@ -16,6 +16,10 @@ const attachPath = "/fusion/v1/datasheets/%s/attachments"
 type Datasheet struct {
 	common.Client
 	DatasheetId string
+	// the space of the datasheet, required to manage fields. such as: `spc*****`
+	SpaceId string
+	// the cache of the uploaded files by content hash, see AttachFiles. required: no, nothing is cached across the calls if it's nil.
+	UploadCache *UploadCache
 }
 
 // NewDatasheet init datasheet instance
@@ -70,6 +74,14 @@ func NewDescribeRecordResponse() (response *DescribeRecordResponse) {
 	return
 }
 
+// NewDeleteRecordsResponse the data of the delete response is true instead of the records
+func NewDeleteRecordsResponse() (response *DeleteRecordsResponse) {
+	response = &DeleteRecordsResponse{
+		BaseResponse: &athttp.BaseResponse{},
+	}
+	return
+}
+
 func NewUploadResponse() (response *UploadResponse) {
 	response = &UploadResponse{
 		BaseResponse: &athttp.BaseResponse{},
@@ -192,7 +204,7 @@ func (c *Datasheet) DeleteRecords(request *DeleteRecordsRequest) (err error) {
 	}
 	request.Init().SetPath(fmt.Sprintf(recordPath, c.DatasheetId))
 	request.SetHttpMethod(athttp.DELETE)
-	response := NewDescribeRecordResponse()
+	response := NewDeleteRecordsResponse()
 	err = c.Send(request, response)
 	return
 }
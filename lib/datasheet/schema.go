@@ -0,0 +1,289 @@
+package datasheet
+
+import (
+	"encoding/json"
+	"fmt"
+	aterror "github.com/apitable/apitable-sdks/apitable.go/lib/common/error"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SchemaVersion the version of the schema snapshot file format
+const SchemaVersion = 1
+
+// SchemaChangeType the type of the difference between two schemas
+type SchemaChangeType string
+
+// all schema change types
+const (
+	SchemaChange_FieldAdded           SchemaChangeType = "FieldAdded"
+	SchemaChange_FieldRemoved         SchemaChangeType = "FieldRemoved"
+	SchemaChange_FieldRenamed         SchemaChangeType = "FieldRenamed"
+	SchemaChange_FieldTypeChanged     SchemaChangeType = "FieldTypeChanged"
+	SchemaChange_SelectOptionsChanged SchemaChangeType = "SelectOptionsChanged"
+	SchemaChange_LinkTargetChanged    SchemaChangeType = "LinkTargetChanged"
+	SchemaChange_ViewAdded            SchemaChangeType = "ViewAdded"
+	SchemaChange_ViewRemoved          SchemaChangeType = "ViewRemoved"
+	SchemaChange_ViewRenamed          SchemaChangeType = "ViewRenamed"
+)
+
+// Schema describe the snapshot of the datasheet fields and views
+type Schema struct {
+	// the version of the snapshot file format
+	Version int `json:"version"`
+	// such as: `dst*****`
+	DatasheetId string `json:"datasheetId"`
+	// snapshot creation time. such as: timestamp
+	CreatedAt int64 `json:"createdAt"`
+	// fields with properties
+	Fields []*DatasheetField `json:"fields"`
+	// views of the datasheet
+	Views []*DatasheetView `json:"views"`
+}
+
+// SchemaChange describe one difference between two schemas
+type SchemaChange struct {
+	Type SchemaChangeType `json:"type"`
+	// field id or view id
+	Id string `json:"id"`
+	// the name of the field or view in the latest schema
+	Name string `json:"name"`
+	// the value before changed, such as the old name or type
+	Before string `json:"before,omitempty"`
+	// the value after changed, such as the new name or type
+	After string `json:"after,omitempty"`
+}
+
+func (c *SchemaChange) String() string {
+	if c.Before == "" && c.After == "" {
+		return fmt.Sprintf("%s %s(%s)", c.Type, c.Name, c.Id)
+	}
+	return fmt.Sprintf("%s %s(%s): %s => %s", c.Type, c.Name, c.Id, c.Before, c.After)
+}
+
+// SchemaDiff describe all differences between two schemas
+type SchemaDiff struct {
+	Changes []*SchemaChange `json:"changes"`
+}
+
+// HasDrift whether the schemas are different
+func (d *SchemaDiff) HasDrift() bool {
+	return len(d.Changes) > 0
+}
+
+func (d *SchemaDiff) String() string {
+	lines := make([]string, len(d.Changes))
+	for i, change := range d.Changes {
+		lines[i] = change.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// DescribeSchema query the fields and views of the datasheet as a schema snapshot
+func (c *Datasheet) DescribeSchema() (schema *Schema, err error) {
+	fields, err := c.DescribeFields(nil)
+	if err != nil {
+		return nil, err
+	}
+	views, err := c.DescribeViews(nil)
+	if err != nil {
+		return nil, err
+	}
+	schema = &Schema{
+		Version:     SchemaVersion,
+		DatasheetId: c.DatasheetId,
+		CreatedAt:   time.Now().UnixNano() / int64(time.Millisecond),
+		Fields:      fields,
+		Views:       views,
+	}
+	return
+}
+
+// SaveSchema query the schema of the datasheet and write it to the json file
+func (c *Datasheet) SaveSchema(filePath string) (schema *Schema, err error) {
+	schema, err = c.DescribeSchema()
+	if err != nil {
+		return nil, err
+	}
+	err = WriteSchema(filePath, schema)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// CheckSchema compare the live schema of the datasheet with the snapshot file
+func (c *Datasheet) CheckSchema(filePath string) (diff *SchemaDiff, err error) {
+	saved, err := ReadSchema(filePath)
+	if err != nil {
+		return nil, err
+	}
+	live, err := c.DescribeSchema()
+	if err != nil {
+		return nil, err
+	}
+	return DiffSchema(saved, live), nil
+}
+
+// WriteSchema write the schema snapshot to the json file
+func WriteSchema(filePath string, schema *Schema) error {
+	b, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = ioutil.WriteFile(filePath, b, 0644)
+	if err != nil {
+		msg := fmt.Sprintf("Fail to write schema because %s", err)
+		return aterror.NewSDKError(500, msg, "ClientError.FileWriteError")
+	}
+	return nil
+}
+
+// ReadSchema read the schema snapshot from the json file
+func ReadSchema(filePath string) (schema *Schema, err error) {
+	b, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		msg := fmt.Sprintf("Fail to read schema because %s", err)
+		return nil, aterror.NewSDKError(500, msg, "ClientError.FileReadError")
+	}
+	schema = &Schema{}
+	err = json.Unmarshal(b, schema)
+	if err != nil {
+		msg := fmt.Sprintf("Fail to parse schema because %s", err)
+		return nil, aterror.NewSDKError(500, msg, "ClientError.ParseJsonError")
+	}
+	if schema.Version > SchemaVersion {
+		msg := fmt.Sprintf("Unsupported schema version: %d", schema.Version)
+		return nil, aterror.NewSDKError(500, msg, "ClientError.SchemaVersionError")
+	}
+	return schema, nil
+}
+
+// DiffSchema compare two schemas, fields and views are matched by id.
+func DiffSchema(before *Schema, after *Schema) *SchemaDiff {
+	diff := &SchemaDiff{Changes: []*SchemaChange{}}
+	beforeFields := make(map[string]*DatasheetField)
+	for _, field := range before.Fields {
+		beforeFields[stringValue(field.Id)] = field
+	}
+	afterFields := make(map[string]*DatasheetField)
+	for _, field := range after.Fields {
+		id := stringValue(field.Id)
+		afterFields[id] = field
+		old, ok := beforeFields[id]
+		if !ok {
+			diff.add(SchemaChange_FieldAdded, id, stringValue(field.Name), "", "")
+			continue
+		}
+		diff.diffField(old, field)
+	}
+	for _, field := range before.Fields {
+		id := stringValue(field.Id)
+		if _, ok := afterFields[id]; !ok {
+			diff.add(SchemaChange_FieldRemoved, id, stringValue(field.Name), "", "")
+		}
+	}
+
+	beforeViews := make(map[string]*DatasheetView)
+	for _, view := range before.Views {
+		beforeViews[stringValue(view.Id)] = view
+	}
+	afterViews := make(map[string]*DatasheetView)
+	for _, view := range after.Views {
+		id := stringValue(view.Id)
+		afterViews[id] = view
+		old, ok := beforeViews[id]
+		if !ok {
+			diff.add(SchemaChange_ViewAdded, id, stringValue(view.Name), "", "")
+			continue
+		}
+		if stringValue(old.Name) != stringValue(view.Name) {
+			diff.add(SchemaChange_ViewRenamed, id, stringValue(view.Name), stringValue(old.Name), stringValue(view.Name))
+		}
+	}
+	for _, view := range before.Views {
+		id := stringValue(view.Id)
+		if _, ok := afterViews[id]; !ok {
+			diff.add(SchemaChange_ViewRemoved, id, stringValue(view.Name), "", "")
+		}
+	}
+	return diff
+}
+
+func (d *SchemaDiff) add(changeType SchemaChangeType, id string, name string, before string, after string) {
+	d.Changes = append(d.Changes, &SchemaChange{
+		Type:   changeType,
+		Id:     id,
+		Name:   name,
+		Before: before,
+		After:  after,
+	})
+}
+
+func (d *SchemaDiff) diffField(before *DatasheetField, after *DatasheetField) {
+	id := stringValue(after.Id)
+	name := stringValue(after.Name)
+	if stringValue(before.Name) != name {
+		d.add(SchemaChange_FieldRenamed, id, name, stringValue(before.Name), name)
+	}
+	beforeType := fieldTypeValue(before.Type)
+	afterType := fieldTypeValue(after.Type)
+	if beforeType != afterType {
+		// the properties of different types are not comparable.
+		d.add(SchemaChange_FieldTypeChanged, id, name, string(beforeType), string(afterType))
+		return
+	}
+	if before.Property == nil || after.Property == nil {
+		return
+	}
+	switch afterType {
+	case FieldType_SingleSelect, FieldType_MultiSelect:
+		beforeOptions := selectOptionNames(before.SelectFieldProperty())
+		afterOptions := selectOptionNames(after.SelectFieldProperty())
+		if beforeOptions != afterOptions {
+			d.add(SchemaChange_SelectOptionsChanged, id, name, beforeOptions, afterOptions)
+		}
+	case FieldType_MagicLink:
+		beforeTarget := linkTarget(before.MagicLinkFieldProperty())
+		afterTarget := linkTarget(after.MagicLinkFieldProperty())
+		if beforeTarget != afterTarget {
+			d.add(SchemaChange_LinkTargetChanged, id, name, beforeTarget, afterTarget)
+		}
+	}
+}
+
+// selectOptionNames format the options as a sorted list, such as: `id1:name1,id2:name2`
+func selectOptionNames(property *SelectFieldProperty) string {
+	if property == nil {
+		return ""
+	}
+	options := make([]string, 0, len(property.Options))
+	for _, option := range property.Options {
+		options = append(options, stringValue(option.Id)+":"+stringValue(option.Name))
+	}
+	sort.Strings(options)
+	return strings.Join(options, ",")
+}
+
+func linkTarget(property *MagicLinkFieldProperty) string {
+	if property == nil {
+		return ""
+	}
+	return stringValue(property.ForeignDatasheetId)
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func fieldTypeValue(t *FieldType) FieldType {
+	if t == nil {
+		return ""
+	}
+	return *t
+}
@@ -0,0 +1,178 @@
+package datasheet
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/common"
+	aterror "github.com/apitable/apitable-sdks/apitable.go/lib/common/error"
+	"reflect"
+	"strings"
+)
+
+// StructTag the struct tag used to bind struct fields to datasheet fields.
+//
+// * the format is `vika:"name[,id][,options]"`, such as: `vika:"Amount,fld*****"`, the id is optional.
+// * the comma and the backslash in the name are escaped by the backslash, such as: `vika:"Amount\\, USD,fld*****"`, see EscapeTagName.
+// * the reserved name `@recordId` binds the record id, such as: `vika:"@recordId"`.
+// * readonly fields are decoded from records but never written, such as formula fields.
+// * the zero values are not written, so the partially filled struct updates the filled fields only.
+// the nil pointers are not written too, but the pointers to the zero values are written.
+// the option `omitempty=false` writes the zero value of the field, such as: `vika:"Done,fld*****,omitempty=false"`.
+const StructTag = "vika"
+
+// the option of the struct tag to write the zero value
+const tagOptionKeepZero = "omitempty=false"
+
+// recordIdTag the reserved tag name to bind the record id
+const recordIdTag = "@recordId"
+
+type structField struct {
+	index    int
+	name     string
+	id       string
+	readonly bool
+	keepZero bool
+}
+
+// EscapeTagName escape the comma and the backslash of the field name in the struct tag
+func EscapeTagName(name string) string {
+	return strings.NewReplacer(`\`, `\\`, ",", `\,`).Replace(name)
+}
+
+// splitTag split the struct tag by the comma, the escaped comma is kept in the part
+func splitTag(tag string) []string {
+	parts := []string{}
+	var b strings.Builder
+	for i := 0; i < len(tag); i++ {
+		switch {
+		case tag[i] == '\\' && i+1 < len(tag):
+			i++
+			b.WriteByte(tag[i])
+		case tag[i] == ',':
+			parts = append(parts, b.String())
+			b.Reset()
+		default:
+			b.WriteByte(tag[i])
+		}
+	}
+	return append(parts, b.String())
+}
+
+func parseStructFields(t reflect.Type) (fields []*structField, recordId int) {
+	recordId = -1
+	for i := 0; i < t.NumField(); i++ {
+		tag, ok := t.Field(i).Tag.Lookup(StructTag)
+		if !ok || tag == "-" {
+			continue
+		}
+		parts := splitTag(tag)
+		if parts[0] == recordIdTag {
+			recordId = i
+			continue
+		}
+		field := &structField{index: i, name: parts[0]}
+		if len(parts) > 1 {
+			field.id = parts[1]
+		}
+		if len(parts) > 2 {
+			for _, option := range parts[2:] {
+				switch option {
+				case "readonly":
+					field.readonly = true
+				case tagOptionKeepZero:
+					field.keepZero = true
+				}
+			}
+		}
+		fields = append(fields, field)
+	}
+	return
+}
+
+func structValue(v interface{}) (reflect.Value, error) {
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		msg := fmt.Sprintf("Expect a pointer to struct, but got %T", v)
+		return reflect.Value{}, aterror.NewSDKError(400, msg, "ClientError.InvalidArgument")
+	}
+	return value.Elem(), nil
+}
+
+// DecodeRecord decode the record into the struct tagged by `vika`, the record fields can be keyed by field name or id.
+func DecodeRecord(record *Record, out interface{}) error {
+	value, err := structValue(out)
+	if err != nil {
+		return err
+	}
+	fields, recordId := parseStructFields(value.Type())
+	if recordId >= 0 && record.BaseRecord != nil && record.RecordId != nil {
+		value.Field(recordId).SetString(*record.RecordId)
+	}
+	if record.BaseRecord == nil || record.Fields == nil {
+		return nil
+	}
+	for _, field := range fields {
+		cell, ok := (*record.Fields)[field.name]
+		if !ok && field.id != "" {
+			cell, ok = (*record.Fields)[field.id]
+		}
+		if !ok || cell == nil {
+			continue
+		}
+		target := value.Field(field.index)
+		// convert the cell value by json, because the cell value is the json decoded value.
+		b, err := json.Marshal(cell)
+		if err != nil {
+			return err
+		}
+		ptr := reflect.New(target.Type())
+		if err = json.Unmarshal(b, ptr.Interface()); err != nil {
+			msg := fmt.Sprintf("Fail to decode field %s because %s", field.name, err)
+			return aterror.NewSDKError(500, msg, "ClientError.ParseJsonError")
+		}
+		target.Set(ptr.Elem())
+	}
+	return nil
+}
+
+// EncodeFields encode the struct tagged by `vika` into record fields keyed by the field key.
+//
+// * readonly fields, zero values and nil pointers are skipped, see StructTag.
+func EncodeFields(in interface{}, fieldKey string) (*Field, error) {
+	value, err := structValue(in)
+	if err != nil {
+		return nil, err
+	}
+	fields, _ := parseStructFields(value.Type())
+	result := Field{}
+	for _, field := range fields {
+		if field.readonly {
+			continue
+		}
+		fieldValue := value.Field(field.index)
+		if !field.keepZero && fieldValue.IsZero() {
+			continue
+		}
+		key := field.name
+		if fieldKey == common.FieldKeyId && field.id != "" {
+			key = field.id
+		}
+		result[key] = fieldValue.Interface()
+	}
+	return &result, nil
+}
+
+// EncodeRecord encode the struct tagged by `vika` into a record to modify, readonly fields are skipped.
+func EncodeRecord(in interface{}, fieldKey string) (*BaseRecord, error) {
+	fields, err := EncodeFields(in, fieldKey)
+	if err != nil {
+		return nil, err
+	}
+	record := &BaseRecord{Fields: fields}
+	value, _ := structValue(in)
+	if _, recordId := parseStructFields(value.Type()); recordId >= 0 {
+		id := value.Field(recordId).String()
+		record.RecordId = &id
+	}
+	return record, nil
+}
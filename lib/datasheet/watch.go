@@ -0,0 +1,326 @@
+package datasheet
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	aterror "github.com/apitable/apitable-sdks/apitable.go/lib/common/error"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// the default interval to poll the records
+const defaultWatchInterval = 30 * time.Second
+
+// RecordEventType the type of the record change
+type RecordEventType string
+
+// all record event types
+const (
+	RecordEvent_Created RecordEventType = "Created"
+	RecordEvent_Updated RecordEventType = "Updated"
+	RecordEvent_Deleted RecordEventType = "Deleted"
+)
+
+// FieldChange the value change of one field
+type FieldChange struct {
+	// the field key, name or id according to the field key of the request
+	Field string `json:"field"`
+	// the value before changed, nil if the field is empty
+	Before FieldValue `json:"before"`
+	// the value after changed, nil if the field is empty
+	After FieldValue `json:"after"`
+}
+
+// RecordEvent describe a record change found by polling
+type RecordEvent struct {
+	Type RecordEventType `json:"type"`
+	// such as: `rec*****`
+	RecordId string `json:"recordId"`
+	// the record after changed, nil for deleted records
+	Record *Record `json:"record,omitempty"`
+	// the fields before changed, nil for created records
+	Previous *Field `json:"previous,omitempty"`
+	// the changed fields, all fields for created and deleted records
+	Changes []*FieldChange `json:"changes"`
+	// the time the change is found
+	DetectedAt time.Time `json:"detectedAt"`
+}
+
+// WatchCheckpoint the snapshot of the records polled last time
+type WatchCheckpoint struct {
+	// the version of the record, the modification time or the content hash
+	Versions map[string]string `json:"versions"`
+	// the fields of the records
+	Records map[string]*Field `json:"records"`
+	// the time of the snapshot. such as: timestamp
+	UpdatedAt int64 `json:"updatedAt"`
+}
+
+// CheckpointStore persist the checkpoint of the watcher
+type CheckpointStore interface {
+	// Load get the saved checkpoint, nil if not saved
+	Load() (*WatchCheckpoint, error)
+	// Save save the checkpoint
+	Save(checkpoint *WatchCheckpoint) error
+}
+
+// FileCheckpointStore persist the checkpoint to a json file
+type FileCheckpointStore struct {
+	FilePath string
+}
+
+// NewFileCheckpointStore init file checkpoint store instance
+func NewFileCheckpointStore(filePath string) *FileCheckpointStore {
+	return &FileCheckpointStore{FilePath: filePath}
+}
+
+func (s *FileCheckpointStore) Load() (*WatchCheckpoint, error) {
+	b, err := ioutil.ReadFile(s.FilePath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		msg := fmt.Sprintf("Fail to read checkpoint because %s", err)
+		return nil, aterror.NewSDKError(500, msg, "ClientError.FileReadError")
+	}
+	checkpoint := &WatchCheckpoint{}
+	if err = json.Unmarshal(b, checkpoint); err != nil {
+		msg := fmt.Sprintf("Fail to parse checkpoint because %s", err)
+		return nil, aterror.NewSDKError(500, msg, "ClientError.ParseJsonError")
+	}
+	return checkpoint, nil
+}
+
+func (s *FileCheckpointStore) Save(checkpoint *WatchCheckpoint) error {
+	b, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+	// write to a temporary file first, so a crash never leaves a broken checkpoint.
+	tmp := filepath.Join(filepath.Dir(s.FilePath), "."+filepath.Base(s.FilePath)+".tmp")
+	if err = ioutil.WriteFile(tmp, b, 0644); err == nil {
+		err = os.Rename(tmp, s.FilePath)
+	}
+	if err != nil {
+		msg := fmt.Sprintf("Fail to write checkpoint because %s", err)
+		return aterror.NewSDKError(500, msg, "ClientError.FileWriteError")
+	}
+	return nil
+}
+
+// WatchOptions the options of the watcher
+type WatchOptions struct {
+	// the interval to poll the records, the default is 30 seconds
+	Interval time.Duration
+	// the request to query the records, such as filter by view or formula. required: no.
+	Request *DescribeRecordRequest
+	// the name or id of the LastModifiedTime field to detect updates.
+	// if it's empty, the content hash of the fields is used.
+	ModifiedTimeField string
+	// persist the checkpoint, so restarts don't replay history. required: no.
+	Checkpoint CheckpointStore
+	// emit Created events for all records on the first poll without a saved checkpoint
+	EmitInitial bool
+	// the buffer size of the event channel
+	BufferSize int
+	// called when polling or saving checkpoint fails, the watcher keeps polling.
+	OnError func(err error)
+}
+
+// Watch poll the records on every interval, and deliver the created, updated and deleted records on the channel.
+//
+// * the changes are computed by diffing against the previous snapshot by record id and version.
+// * the channel is closed when the context is done.
+func (c *Datasheet) Watch(ctx context.Context, options *WatchOptions) (<-chan *RecordEvent, error) {
+	opts := WatchOptions{}
+	if options != nil {
+		opts = *options
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = defaultWatchInterval
+	}
+	var checkpoint *WatchCheckpoint
+	if opts.Checkpoint != nil {
+		var err error
+		if checkpoint, err = opts.Checkpoint.Load(); err != nil {
+			return nil, err
+		}
+	}
+	events := make(chan *RecordEvent, opts.BufferSize)
+	go func() {
+		defer close(events)
+		ticker := time.NewTicker(opts.Interval)
+		defer ticker.Stop()
+		for {
+			next, err := c.poll(ctx, &opts, checkpoint, events)
+			if err != nil && ctx.Err() == nil && opts.OnError != nil {
+				opts.OnError(err)
+			}
+			if next != nil {
+				checkpoint = next
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return events, nil
+}
+
+func (c *Datasheet) poll(ctx context.Context, opts *WatchOptions, previous *WatchCheckpoint, events chan<- *RecordEvent) (*WatchCheckpoint, error) {
+	request := opts.Request
+	if request == nil {
+		request = NewDescribeRecordRequest()
+	}
+	records, err := c.DescribeAllRecords(request)
+	if err != nil {
+		return nil, err
+	}
+	next := &WatchCheckpoint{
+		Versions:  make(map[string]string, len(records)),
+		Records:   make(map[string]*Field, len(records)),
+		UpdatedAt: time.Now().UnixNano() / int64(time.Millisecond),
+	}
+	for _, record := range records {
+		if record.BaseRecord == nil || record.RecordId == nil {
+			continue
+		}
+		next.Versions[*record.RecordId] = recordVersion(record, opts.ModifiedTimeField)
+		next.Records[*record.RecordId] = record.Fields
+	}
+
+	if previous != nil || opts.EmitInitial {
+		if previous == nil {
+			previous = &WatchCheckpoint{}
+		}
+		for _, event := range DiffRecords(previous, next, records) {
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				// the events after this one are not delivered, keep the previous checkpoint.
+				return nil, ctx.Err()
+			}
+		}
+	}
+	if opts.Checkpoint != nil {
+		if err = opts.Checkpoint.Save(next); err != nil {
+			return next, err
+		}
+	}
+	return next, nil
+}
+
+// recordVersion get the version of the record by the modification time field or the content hash
+func recordVersion(record *Record, modifiedTimeField string) string {
+	if modifiedTimeField != "" && record.Fields != nil {
+		if v, ok := (*record.Fields)[modifiedTimeField]; ok && v != nil {
+			return fmt.Sprint(v)
+		}
+	}
+	// the keys of the map are sorted by json, so the hash is stable.
+	b, _ := json.Marshal(record.Fields)
+	sum := sha1.Sum(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// DiffRecords compute the record events between two checkpoints, records are the records of the next checkpoint.
+func DiffRecords(previous *WatchCheckpoint, next *WatchCheckpoint, records []*Record) []*RecordEvent {
+	now := time.Now()
+	events := []*RecordEvent{}
+	for _, record := range records {
+		if record.BaseRecord == nil || record.RecordId == nil {
+			continue
+		}
+		id := *record.RecordId
+		oldVersion, existed := previous.Versions[id]
+		if !existed {
+			events = append(events, &RecordEvent{
+				Type:       RecordEvent_Created,
+				RecordId:   id,
+				Record:     record,
+				Changes:    diffFields(nil, record.Fields),
+				DetectedAt: now,
+			})
+			continue
+		}
+		if oldVersion == next.Versions[id] {
+			continue
+		}
+		before := previous.Records[id]
+		changes := diffFields(before, record.Fields)
+		if len(changes) == 0 {
+			// only the modification time changed, such as the changes of hidden fields.
+			continue
+		}
+		events = append(events, &RecordEvent{
+			Type:       RecordEvent_Updated,
+			RecordId:   id,
+			Record:     record,
+			Previous:   before,
+			Changes:    changes,
+			DetectedAt: now,
+		})
+	}
+	deleted := []string{}
+	for id := range previous.Versions {
+		if _, ok := next.Versions[id]; !ok {
+			deleted = append(deleted, id)
+		}
+	}
+	sort.Strings(deleted)
+	for _, id := range deleted {
+		before := previous.Records[id]
+		events = append(events, &RecordEvent{
+			Type:       RecordEvent_Deleted,
+			RecordId:   id,
+			Previous:   before,
+			Changes:    diffFields(before, nil),
+			DetectedAt: now,
+		})
+	}
+	return events
+}
+
+// diffFields compare the fields by json value, the changes are sorted by field key.
+func diffFields(before *Field, after *Field) []*FieldChange {
+	keys := map[string]bool{}
+	if before != nil {
+		for key := range *before {
+			keys[key] = true
+		}
+	}
+	if after != nil {
+		for key := range *after {
+			keys[key] = true
+		}
+	}
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+	changes := []*FieldChange{}
+	for _, key := range sorted {
+		var oldValue, newValue FieldValue
+		if before != nil {
+			oldValue = (*before)[key]
+		}
+		if after != nil {
+			newValue = (*after)[key]
+		}
+		oldJson, _ := json.Marshal(oldValue)
+		newJson, _ := json.Marshal(newValue)
+		if string(oldJson) != string(newJson) {
+			changes = append(changes, &FieldChange{Field: key, Before: oldValue, After: newValue})
+		}
+	}
+	return changes
+}
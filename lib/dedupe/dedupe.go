@@ -0,0 +1,702 @@
+// Package dedupe provides the duplicate detection and merge of the records
+package dedupe
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/common"
+	aterror "github.com/apitable/apitable-sdks/apitable.go/lib/common/error"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/common/text"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/datasheet"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Key the field compared to find the duplicates, the records are duplicates if all keys match
+type Key struct {
+	// the field name. required: yes.
+	Field string
+	// compare the normalized text, see text.Normalize
+	Fuzzy bool
+	// the max edit distance of the fuzzy match, 0 means the normalized text must be equal.
+	// the records are compared in pairs in the blocks of the other keys, so the comparisons are O(n²)
+	// if all keys have the distance, add an exact key to split the blocks for the large datasheet.
+	MaxDistance int
+}
+
+// SurvivorRule how to choose the surviving record of the duplicates
+type SurvivorRule string
+
+const (
+	// SurvivorRule_First keep the first record in the order of the records
+	SurvivorRule_First SurvivorRule = "first"
+	// SurvivorRule_Oldest keep the record created first
+	SurvivorRule_Oldest SurvivorRule = "oldest"
+	// SurvivorRule_Newest keep the record created last
+	SurvivorRule_Newest SurvivorRule = "newest"
+	// SurvivorRule_MostComplete keep the record with the most non-empty fields
+	SurvivorRule_MostComplete SurvivorRule = "mostComplete"
+)
+
+// MergeRule how to merge the field values of the duplicates into the survivor
+type MergeRule string
+
+const (
+	// MergeRule_KeepSurvivor keep the value of the survivor
+	MergeRule_KeepSurvivor MergeRule = "keepSurvivor"
+	// MergeRule_FillEmpty keep the value of the survivor, or use the first non-empty value if it's empty
+	MergeRule_FillEmpty MergeRule = "fillEmpty"
+	// MergeRule_Longest use the longest text
+	MergeRule_Longest MergeRule = "longest"
+	// MergeRule_Max use the max number
+	MergeRule_Max MergeRule = "max"
+	// MergeRule_Min use the min number
+	MergeRule_Min MergeRule = "min"
+	// MergeRule_Union merge the list values, such as: multi select, links, members and attachments
+	MergeRule_Union MergeRule = "union"
+	// MergeRule_Concat join the distinct texts by line
+	MergeRule_Concat MergeRule = "concat"
+)
+
+// LinkRef the link field referencing the deduplicated datasheet, the references to the removed records are rewritten
+type LinkRef struct {
+	// the datasheet of the link field, nil for the deduplicated datasheet itself
+	Datasheet *datasheet.Datasheet
+	// the link field name. required: yes.
+	Field string
+}
+
+// Options the options of the deduplication
+type Options struct {
+	// the fields to find the duplicates. required: yes.
+	Keys []*Key
+	// the default is SurvivorRule_First
+	Survivor SurvivorRule
+	// the merge rules by field name
+	Rules map[string]MergeRule
+	// the merge rule of the fields not in Rules, the default is MergeRule_FillEmpty
+	DefaultRule MergeRule
+	// the link fields to rewrite before the duplicates are deleted
+	Links []*LinkRef
+	// only report the changes
+	DryRun bool
+}
+
+// Group the duplicate records
+type Group struct {
+	// the key values of the survivor
+	Key []string `json:"key"`
+	// the surviving record id
+	Survivor string `json:"survivor"`
+	// the record ids to delete
+	Duplicates []string `json:"duplicates"`
+	// the field changes of the survivor
+	Changes []*datasheet.FieldChange `json:"changes"`
+}
+
+// LinkRewrite the link field changed to reference the survivors
+type LinkRewrite struct {
+	DatasheetId string   `json:"datasheetId"`
+	RecordId    string   `json:"recordId"`
+	Field       string   `json:"field"`
+	Before      []string `json:"before"`
+	After       []string `json:"after"`
+}
+
+// Report the result of the deduplication
+type Report struct {
+	DryRun       bool           `json:"dryRun"`
+	Groups       []*Group       `json:"groups"`
+	LinkRewrites []*LinkRewrite `json:"linkRewrites"`
+	// the count of the deleted records, 0 in dry run
+	Deleted int `json:"deleted"`
+}
+
+// String describe the report for people
+func (r *Report) String() string {
+	var b strings.Builder
+	duplicates := 0
+	for _, group := range r.Groups {
+		duplicates += len(group.Duplicates)
+	}
+	if r.DryRun {
+		b.WriteString("dry run: ")
+	}
+	fmt.Fprintf(&b, "%d duplicate groups, %d records to delete, %d links to rewrite\n", len(r.Groups), duplicates, len(r.LinkRewrites))
+	for _, group := range r.Groups {
+		fmt.Fprintf(&b, "group %q: keep %s, delete %s\n", strings.Join(group.Key, " / "), group.Survivor, strings.Join(group.Duplicates, ", "))
+		for _, change := range group.Changes {
+			fmt.Fprintf(&b, "  %s: %s => %s\n", change.Field, jsonText(change.Before), jsonText(change.After))
+		}
+	}
+	for _, rewrite := range r.LinkRewrites {
+		fmt.Fprintf(&b, "link %s %s %s: %s => %s\n", rewrite.DatasheetId, rewrite.RecordId, rewrite.Field,
+			strings.Join(rewrite.Before, ", "), strings.Join(rewrite.After, ", "))
+	}
+	return b.String()
+}
+
+func jsonText(value interface{}) string {
+	if value == nil {
+		return "(empty)"
+	}
+	b, _ := json.Marshal(value)
+	return string(b)
+}
+
+func checkOptions(options *Options) (Options, error) {
+	opts := Options{}
+	if options != nil {
+		opts = *options
+	}
+	if len(opts.Keys) == 0 {
+		return opts, aterror.NewSDKError(400, "The key fields are required", "ClientError.InvalidArgument")
+	}
+	for _, key := range opts.Keys {
+		if key == nil || key.Field == "" {
+			return opts, aterror.NewSDKError(400, "The field of the key is required", "ClientError.InvalidArgument")
+		}
+	}
+	if opts.Survivor == "" {
+		opts.Survivor = SurvivorRule_First
+	}
+	if opts.DefaultRule == "" {
+		opts.DefaultRule = MergeRule_FillEmpty
+	}
+	return opts, nil
+}
+
+// Find group the duplicate records, and merge the fields of each group into the survivor.
+// fields are the writable fields of the datasheet to merge, all fields of the records are merged if it's nil.
+func Find(records []*datasheet.Record, fields []*datasheet.DatasheetField, options *Options) ([]*Group, error) {
+	opts, err := checkOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	groups, _ := find(records, fields, &opts)
+	return groups, nil
+}
+
+// find return the groups and the merged fields of the survivors
+func find(records []*datasheet.Record, fields []*datasheet.DatasheetField, opts *Options) ([]*Group, map[string]datasheet.Field) {
+	keyed := []*keyedRecord{}
+	for _, record := range records {
+		if record.BaseRecord == nil || record.RecordId == nil {
+			continue
+		}
+		k := &keyedRecord{record: record}
+		empty := true
+		for _, key := range opts.Keys {
+			s := textOf(value(record, key.Field))
+			if key.Fuzzy {
+				s = text.Normalize(s)
+			}
+			empty = empty && s == ""
+			k.keys = append(k.keys, s)
+		}
+		if !empty {
+			keyed = append(keyed, k)
+		}
+	}
+	// the records are compared in the blocks of the exact keys
+	blocks := map[string][]int{}
+	blockOrder := []string{}
+	for i, k := range keyed {
+		exact := []string{}
+		for j, key := range opts.Keys {
+			if key.MaxDistance <= 0 {
+				exact = append(exact, k.keys[j])
+			}
+		}
+		b, _ := json.Marshal(exact)
+		if _, ok := blocks[string(b)]; !ok {
+			blockOrder = append(blockOrder, string(b))
+		}
+		blocks[string(b)] = append(blocks[string(b)], i)
+	}
+	parents := make([]int, len(keyed))
+	for i := range parents {
+		parents[i] = i
+	}
+	var root func(i int) int
+	root = func(i int) int {
+		for parents[i] != i {
+			parents[i] = parents[parents[i]]
+			i = parents[i]
+		}
+		return i
+	}
+	for _, block := range blockOrder {
+		members := blocks[block]
+		for x := 0; x < len(members); x++ {
+			for y := x + 1; y < len(members); y++ {
+				if matches(opts.Keys, keyed[members[x]].keys, keyed[members[y]].keys) {
+					a, b := root(members[x]), root(members[y])
+					if a > b {
+						a, b = b, a
+					}
+					parents[b] = a
+				}
+			}
+		}
+	}
+	clusters := map[int][]*keyedRecord{}
+	order := []int{}
+	for i, k := range keyed {
+		r := root(i)
+		if _, ok := clusters[r]; !ok {
+			order = append(order, r)
+		}
+		clusters[r] = append(clusters[r], k)
+	}
+	writable := map[string]datasheet.FieldType{}
+	for _, field := range fields {
+		if field.Name != nil && field.Type != nil && !field.Type.IsComputed() {
+			writable[*field.Name] = *field.Type
+		}
+	}
+	// the chained matches are split, every duplicate must match the survivor itself.
+	// such as: a matches b, b matches c, but c is too far from a, then c is not the duplicate of a.
+	confirmed := [][]*keyedRecord{}
+	for _, r := range order {
+		for remaining := clusters[r]; len(remaining) > 1; {
+			survivor := chooseSurvivor(remaining, opts.Survivor)
+			cluster := []*keyedRecord{}
+			rest := []*keyedRecord{}
+			for i, k := range remaining {
+				if i == survivor || matches(opts.Keys, remaining[survivor].keys, k.keys) {
+					cluster = append(cluster, k)
+				} else {
+					rest = append(rest, k)
+				}
+			}
+			if len(cluster) > 1 {
+				confirmed = append(confirmed, cluster)
+			}
+			remaining = rest
+		}
+	}
+	groups := []*Group{}
+	merged := map[string]datasheet.Field{}
+	for _, cluster := range confirmed {
+		survivor := chooseSurvivor(cluster, opts.Survivor)
+		group := &Group{Key: cluster[survivor].keys, Survivor: *cluster[survivor].record.RecordId, Changes: []*datasheet.FieldChange{}}
+		ordered := []*datasheet.Record{cluster[survivor].record}
+		for i, k := range cluster {
+			if i != survivor {
+				group.Duplicates = append(group.Duplicates, *k.record.RecordId)
+				ordered = append(ordered, k.record)
+			}
+		}
+		fieldsOfGroup := map[string]datasheet.FieldType{}
+		if fields != nil {
+			fieldsOfGroup = writable
+		} else {
+			for _, record := range ordered {
+				if record.Fields != nil {
+					for name := range *record.Fields {
+						fieldsOfGroup[name] = ""
+					}
+				}
+			}
+		}
+		names := make([]string, 0, len(fieldsOfGroup))
+		for name := range fieldsOfGroup {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		updates := datasheet.Field{}
+		for _, name := range names {
+			rule, ok := opts.Rules[name]
+			if !ok {
+				rule = opts.DefaultRule
+			}
+			before := value(ordered[0], name)
+			after := mergeValues(rule, name, ordered)
+			if sameValue(before, after) {
+				continue
+			}
+			group.Changes = append(group.Changes, &datasheet.FieldChange{Field: name, Before: before, After: after})
+			updates[name] = writeValue(fieldsOfGroup[name], after)
+		}
+		if len(updates) > 0 {
+			merged[group.Survivor] = updates
+		}
+		groups = append(groups, group)
+	}
+	return groups, merged
+}
+
+type keyedRecord struct {
+	record *datasheet.Record
+	keys   []string
+}
+
+func matches(keys []*Key, a []string, b []string) bool {
+	for i, key := range keys {
+		if key.MaxDistance > 0 {
+			if text.Distance(a[i], b[i]) > key.MaxDistance {
+				return false
+			}
+		} else if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func chooseSurvivor(cluster []*keyedRecord, rule SurvivorRule) int {
+	survivor := 0
+	for i := 1; i < len(cluster); i++ {
+		a, b := cluster[survivor].record, cluster[i].record
+		switch rule {
+		case SurvivorRule_Oldest:
+			if createdAt(b) < createdAt(a) {
+				survivor = i
+			}
+		case SurvivorRule_Newest:
+			if createdAt(b) > createdAt(a) {
+				survivor = i
+			}
+		case SurvivorRule_MostComplete:
+			if completeness(b) > completeness(a) {
+				survivor = i
+			}
+		}
+	}
+	return survivor
+}
+
+func createdAt(record *datasheet.Record) int64 {
+	if record.CreatedAt == nil {
+		return 0
+	}
+	return *record.CreatedAt
+}
+
+func completeness(record *datasheet.Record) int {
+	count := 0
+	if record.Fields != nil {
+		for _, v := range *record.Fields {
+			if !isEmpty(v) {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+func value(record *datasheet.Record, field string) interface{} {
+	if record.BaseRecord == nil || record.Fields == nil {
+		return nil
+	}
+	return (*record.Fields)[field]
+}
+
+func isEmpty(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+func sameValue(a interface{}, b interface{}) bool {
+	if isEmpty(a) && isEmpty(b) {
+		return true
+	}
+	x, _ := json.Marshal(a)
+	y, _ := json.Marshal(b)
+	return string(x) == string(y)
+}
+
+// textOf get the text of the value to compare, the list items are joined by comma
+func textOf(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []interface{}:
+		texts := make([]string, 0, len(v))
+		for _, item := range v {
+			texts = append(texts, textOf(item))
+		}
+		return strings.Join(texts, ", ")
+	case map[string]interface{}:
+		for _, key := range []string{"text", "name", "unitName", "title"} {
+			if text, ok := v[key].(string); ok {
+				return text
+			}
+		}
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+// itemId identify the list item, such as the member id, the attachment token or the link record id
+func itemId(item interface{}) string {
+	if m, ok := item.(map[string]interface{}); ok {
+		for _, key := range []string{"id", "unitId", "token"} {
+			if id, ok := m[key].(string); ok && id != "" {
+				return id
+			}
+		}
+	}
+	b, _ := json.Marshal(item)
+	return string(b)
+}
+
+func number(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// mergeValues merge the values of the records, the survivor is the first record
+func mergeValues(rule MergeRule, field string, records []*datasheet.Record) interface{} {
+	survivor := value(records[0], field)
+	switch rule {
+	case MergeRule_KeepSurvivor:
+		return survivor
+	case MergeRule_Longest:
+		longest := survivor
+		for _, record := range records[1:] {
+			v := value(record, field)
+			if len([]rune(textOf(v))) > len([]rune(textOf(longest))) {
+				longest = v
+			}
+		}
+		return longest
+	case MergeRule_Max, MergeRule_Min:
+		var result interface{}
+		var best float64
+		for _, record := range records {
+			v := value(record, field)
+			n, ok := number(v)
+			if !ok {
+				continue
+			}
+			if result == nil || rule == MergeRule_Max && n > best || rule == MergeRule_Min && n < best {
+				result, best = v, n
+			}
+		}
+		if result == nil {
+			return survivor
+		}
+		return result
+	case MergeRule_Union:
+		items := []interface{}{}
+		seen := map[string]bool{}
+		for _, record := range records {
+			v := value(record, field)
+			list, ok := v.([]interface{})
+			if !ok {
+				if isEmpty(v) {
+					continue
+				}
+				list = []interface{}{v}
+			}
+			for _, item := range list {
+				if id := itemId(item); !seen[id] {
+					seen[id] = true
+					items = append(items, item)
+				}
+			}
+		}
+		return items
+	case MergeRule_Concat:
+		texts := []string{}
+		seen := map[string]bool{}
+		for _, record := range records {
+			text := textOf(value(record, field))
+			if text != "" && !seen[text] {
+				seen[text] = true
+				texts = append(texts, text)
+			}
+		}
+		return strings.Join(texts, "\n")
+	}
+	// MergeRule_FillEmpty
+	for _, record := range records {
+		if v := value(record, field); !isEmpty(v) {
+			return v
+		}
+	}
+	return survivor
+}
+
+// writeValue convert the merged value to the value of the write request
+func writeValue(fieldType datasheet.FieldType, v interface{}) interface{} {
+	if isEmpty(v) {
+		return nil
+	}
+	if fieldType == datasheet.FieldType_Attachment {
+		values := []*datasheet.AttachmentValue{}
+		record := &datasheet.Record{BaseRecord: &datasheet.BaseRecord{Fields: &datasheet.Field{"": v}}}
+		for _, attachment := range datasheet.RecordAttachments(record, "") {
+			if attachment.Token == nil {
+				continue
+			}
+			value := &datasheet.AttachmentValue{Token: *attachment.Token}
+			if attachment.Name != nil {
+				value.Name = *attachment.Name
+			}
+			values = append(values, value)
+		}
+		return values
+	}
+	return v
+}
+
+// Run find the duplicate records of the datasheet, merge them into the survivors,
+// rewrite the links referencing the duplicates, and delete the duplicates.
+//
+// * run with DryRun first to check the report.
+// * the survivors are modified first, then the links, the duplicates are deleted last.
+func Run(dst *datasheet.Datasheet, options *Options) (report *Report, err error) {
+	opts, err := checkOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := dst.DescribeFields(nil)
+	if err != nil {
+		return nil, err
+	}
+	records, err := dst.DescribeAllRecords(nil)
+	if err != nil {
+		return nil, err
+	}
+	groups, merged := find(records, fields, &opts)
+	report = &Report{DryRun: opts.DryRun, Groups: groups, LinkRewrites: []*LinkRewrite{}}
+	survivors := map[string]string{}
+	for _, group := range groups {
+		for _, id := range group.Duplicates {
+			survivors[id] = group.Survivor
+		}
+	}
+	// the link updates by datasheet, the self links are written with the merged fields
+	updates := map[*datasheet.Datasheet]map[string]datasheet.Field{dst: merged}
+	targets := []*datasheet.Datasheet{dst}
+	for _, link := range opts.Links {
+		target, linkRecords := dst, records
+		if link.Datasheet != nil && link.Datasheet.DatasheetId != dst.DatasheetId {
+			target = link.Datasheet
+			if linkRecords, err = target.DescribeAllRecords(nil); err != nil {
+				return nil, err
+			}
+			if _, ok := updates[target]; !ok {
+				updates[target] = map[string]datasheet.Field{}
+				targets = append(targets, target)
+			}
+		}
+		for _, record := range linkRecords {
+			if record.BaseRecord == nil || record.RecordId == nil {
+				continue
+			}
+			recordId := *record.RecordId
+			if target == dst && survivors[recordId] != "" {
+				continue
+			}
+			rewrite := rewriteLinks(value(record, link.Field), survivors)
+			if rewrite == nil {
+				continue
+			}
+			rewrite.DatasheetId, rewrite.RecordId, rewrite.Field = target.DatasheetId, recordId, link.Field
+			report.LinkRewrites = append(report.LinkRewrites, rewrite)
+			if updates[target][recordId] == nil {
+				updates[target][recordId] = datasheet.Field{}
+			}
+			updates[target][recordId][link.Field] = rewrite.After
+		}
+	}
+	if opts.DryRun {
+		return report, nil
+	}
+	for _, target := range targets {
+		if err = modify(target, updates[target]); err != nil {
+			return report, err
+		}
+	}
+	duplicates := []*string{}
+	for _, group := range groups {
+		for _, id := range group.Duplicates {
+			duplicates = append(duplicates, common.StringPtr(id))
+		}
+	}
+	for start := 0; start < len(duplicates); start += common.MaxWriteRecords {
+		end := start + common.MaxWriteRecords
+		if end > len(duplicates) {
+			end = len(duplicates)
+		}
+		request := datasheet.NewDeleteRecordsRequest()
+		request.RecordIds = duplicates[start:end]
+		if err = dst.DeleteRecords(request); err != nil {
+			return report, err
+		}
+		report.Deleted += end - start
+	}
+	return report, nil
+}
+
+// rewriteLinks replace the duplicate record ids with the survivors, nil if nothing changes
+func rewriteLinks(v interface{}, survivors map[string]string) *LinkRewrite {
+	list, _ := v.([]interface{})
+	rewrite := &LinkRewrite{Before: []string{}, After: []string{}}
+	changed := false
+	seen := map[string]bool{}
+	for _, item := range list {
+		id, ok := item.(string)
+		if !ok {
+			continue
+		}
+		rewrite.Before = append(rewrite.Before, id)
+		if survivor, ok := survivors[id]; ok {
+			id, changed = survivor, true
+		}
+		if !seen[id] {
+			seen[id] = true
+			rewrite.After = append(rewrite.After, id)
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return rewrite
+}
+
+// modify write the fields of the records in batches
+func modify(dst *datasheet.Datasheet, updates map[string]datasheet.Field) error {
+	ids := make([]string, 0, len(updates))
+	for id := range updates {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for start := 0; start < len(ids); start += common.MaxWriteRecords {
+		end := start + common.MaxWriteRecords
+		if end > len(ids) {
+			end = len(ids)
+		}
+		request := datasheet.NewModifyRecordsRequest()
+		for _, id := range ids[start:end] {
+			fields := updates[id]
+			request.Records = append(request.Records, &datasheet.BaseRecord{RecordId: common.StringPtr(id), Fields: &fields})
+		}
+		if _, err := dst.ModifyRecords(request); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,282 @@
+// Package export provides the exporters writing the records to csv, json lines and xlsx
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/common"
+	aterror "github.com/apitable/apitable-sdks/apitable.go/lib/common/error"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/datasheet"
+	"io"
+)
+
+// RecordIdColumn the header of the record id column
+const RecordIdColumn = "recordId"
+
+// Format the export file format
+type Format string
+
+const (
+	Format_CSV    Format = "csv"
+	Format_NDJSON Format = "ndjson"
+	Format_XLSX   Format = "xlsx"
+)
+
+// RecordIterator iterate the records, Next returns io.EOF after the last record
+type RecordIterator interface {
+	Next() (*datasheet.Record, error)
+}
+
+// sliceIterator iterate the records in memory
+type sliceIterator struct {
+	records []*datasheet.Record
+}
+
+// NewSliceIterator init the iterator of the records in memory
+func NewSliceIterator(records []*datasheet.Record) RecordIterator {
+	return &sliceIterator{records: records}
+}
+
+func (i *sliceIterator) Next() (*datasheet.Record, error) {
+	if len(i.records) == 0 {
+		return nil, io.EOF
+	}
+	record := i.records[0]
+	i.records = i.records[1:]
+	return record, nil
+}
+
+// pageIterator iterate the records of the datasheet page by page
+type pageIterator struct {
+	datasheet *datasheet.Datasheet
+	request   *datasheet.DescribeRecordRequest
+	records   []*datasheet.Record
+	pageNum   int64
+	fetched   int64
+	total     int64
+}
+
+// NewPageIterator init the iterator querying the records page by page, only one page is kept in memory.
+// the request is used to filter and sort the records. required: no.
+func NewPageIterator(dst *datasheet.Datasheet, request *datasheet.DescribeRecordRequest) RecordIterator {
+	if request == nil {
+		request = datasheet.NewDescribeRecordRequest()
+	}
+	return &pageIterator{datasheet: dst, request: request, total: -1}
+}
+
+func (i *pageIterator) Next() (*datasheet.Record, error) {
+	for len(i.records) == 0 {
+		if i.total >= 0 && i.fetched >= i.total {
+			return nil, io.EOF
+		}
+		i.pageNum++
+		i.request.PageNum = common.Int64Ptr(i.pageNum)
+		i.request.PageSize = common.Int64Ptr(int64(common.MaxPageSize))
+		pagination, err := i.datasheet.DescribeRecords(i.request)
+		if err != nil {
+			return nil, err
+		}
+		if pagination.Total != nil {
+			i.total = *pagination.Total
+		}
+		if len(pagination.Records) == 0 {
+			i.total = i.fetched
+			return nil, io.EOF
+		}
+		i.records = pagination.Records
+		i.fetched += int64(len(pagination.Records))
+	}
+	record := i.records[0]
+	i.records = i.records[1:]
+	return record, nil
+}
+
+// Options the options of the exporters
+type Options struct {
+	// the exported fields in column order. required: yes.
+	// use ViewFields to follow the field order of a view.
+	Fields []*datasheet.DatasheetField
+	// add the record id as the first column
+	IncludeRecordId bool
+	// write the json values instead of the rendered strings, only for json lines
+	RawValues bool
+	// the sheet name of the xlsx, the default is Sheet1
+	SheetName string
+	// render the cell values
+	Renderer Renderer
+}
+
+// ViewFields get the fields in the order of the view, the hidden fields of the view are not returned.
+func ViewFields(dst *datasheet.Datasheet, viewId string) ([]*datasheet.DatasheetField, error) {
+	request := datasheet.NewDescribeFieldsRequest()
+	if viewId != "" {
+		request.ViewId = common.StringPtr(viewId)
+	}
+	return dst.DescribeFields(request)
+}
+
+func checkOptions(options *Options) error {
+	if options == nil || len(options.Fields) == 0 {
+		return aterror.NewSDKError(400, "The exported fields are required", "ClientError.InvalidArgument")
+	}
+	return nil
+}
+
+// Export write the records in the format, return the count of the records written.
+func Export(w io.Writer, format Format, records RecordIterator, options *Options) (int, error) {
+	switch format {
+	case Format_CSV:
+		return WriteCSV(w, records, options)
+	case Format_NDJSON:
+		return WriteNDJSON(w, records, options)
+	case Format_XLSX:
+		return WriteXLSX(w, records, options)
+	}
+	msg := fmt.Sprintf("Unsupported export format: %s", format)
+	return 0, aterror.NewSDKError(400, msg, "ClientError.InvalidArgument")
+}
+
+// header the column names
+func header(options *Options) []string {
+	columns := []string{}
+	if options.IncludeRecordId {
+		columns = append(columns, RecordIdColumn)
+	}
+	for _, field := range options.Fields {
+		name := ""
+		if field.Name != nil {
+			name = *field.Name
+		}
+		columns = append(columns, name)
+	}
+	return columns
+}
+
+// cellValue get the value of the field, the record fields may be keyed by field name or id.
+func cellValue(record *datasheet.Record, field *datasheet.DatasheetField) interface{} {
+	if record.BaseRecord == nil || record.Fields == nil {
+		return nil
+	}
+	if field.Name != nil {
+		if value, ok := (*record.Fields)[*field.Name]; ok {
+			return value
+		}
+	}
+	if field.Id != nil {
+		return (*record.Fields)[*field.Id]
+	}
+	return nil
+}
+
+func recordId(record *datasheet.Record) string {
+	if record.BaseRecord == nil || record.RecordId == nil {
+		return ""
+	}
+	return *record.RecordId
+}
+
+// eachRow render the records to the rows of strings
+func eachRow(records RecordIterator, options *Options, fn func(row []string) error) (count int, err error) {
+	for {
+		record, err := records.Next()
+		if err == io.EOF {
+			return count, nil
+		}
+		if err != nil {
+			return count, err
+		}
+		row := make([]string, 0, len(options.Fields)+1)
+		if options.IncludeRecordId {
+			row = append(row, recordId(record))
+		}
+		for _, field := range options.Fields {
+			row = append(row, options.Renderer.Render(field, cellValue(record, field)))
+		}
+		if err = fn(row); err != nil {
+			return count, err
+		}
+		count++
+	}
+}
+
+// WriteCSV write the header and the rendered records as csv
+func WriteCSV(w io.Writer, records RecordIterator, options *Options) (int, error) {
+	if err := checkOptions(options); err != nil {
+		return 0, err
+	}
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header(options)); err != nil {
+		return 0, err
+	}
+	count, err := eachRow(records, options, writer.Write)
+	writer.Flush()
+	if err == nil {
+		err = writer.Error()
+	}
+	return count, err
+}
+
+// WriteNDJSON write one json object per line, keyed by field name.
+// the values are the rendered strings, or the json values if RawValues is true.
+func WriteNDJSON(w io.Writer, records RecordIterator, options *Options) (int, error) {
+	if err := checkOptions(options); err != nil {
+		return 0, err
+	}
+	encoder := json.NewEncoder(w)
+	columns := header(options)
+	count := 0
+	for {
+		record, err := records.Next()
+		if err == io.EOF {
+			return count, nil
+		}
+		if err != nil {
+			return count, err
+		}
+		// keep the column order in the object
+		object := orderedObject{}
+		if options.IncludeRecordId {
+			object = append(object, objectEntry{RecordIdColumn, recordId(record)})
+		}
+		for _, field := range options.Fields {
+			var value interface{} = options.Renderer.Render(field, cellValue(record, field))
+			if options.RawValues {
+				value = cellValue(record, field)
+			}
+			object = append(object, objectEntry{columns[len(object)], value})
+		}
+		if err = encoder.Encode(object); err != nil {
+			return count, err
+		}
+		count++
+	}
+}
+
+type objectEntry struct {
+	key   string
+	value interface{}
+}
+
+// orderedObject the json object keeping the key order
+type orderedObject []objectEntry
+
+func (o orderedObject) MarshalJSON() ([]byte, error) {
+	b := []byte{'{'}
+	for i, entry := range o {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		key, err := json.Marshal(entry.key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(entry.value)
+		if err != nil {
+			return nil, err
+		}
+		b = append(append(append(b, key...), ':'), value...)
+	}
+	return append(b, '}'), nil
+}
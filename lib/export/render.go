@@ -0,0 +1,286 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/datasheet"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// the default date format of the date time fields
+const defaultDateFormat = "YYYY/MM/DD"
+
+// the default time format of the date time fields
+const defaultTimeFormat = "HH:mm"
+
+// the date format tokens to the go layout, the longer tokens first
+var dateFormatTokens = []struct {
+	token  string
+	layout string
+}{
+	{"YYYY", "2006"},
+	{"YY", "06"},
+	{"MM", "01"},
+	{"M", "1"},
+	{"DD", "02"},
+	{"D", "2"},
+	{"HH", "15"},
+	{"hh", "03"},
+	{"h", "3"},
+	{"mm", "04"},
+	{"ss", "05"},
+	{"A", "PM"},
+	{"a", "pm"},
+}
+
+// DateLayout convert the date format of the field property to the go layout, such as: YYYY/MM/DD HH:mm -> 2006/01/02 15:04
+func DateLayout(format string) string {
+	var b strings.Builder
+	for i := 0; i < len(format); {
+		matched := false
+		for _, t := range dateFormatTokens {
+			if strings.HasPrefix(format[i:], t.token) {
+				b.WriteString(t.layout)
+				i += len(t.token)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			b.WriteByte(format[i])
+			i++
+		}
+	}
+	return b.String()
+}
+
+// formatProperty the common format of the number, date time, formula and lookup fields
+type formatProperty struct {
+	Precision *int `json:"precision"`
+	// the currency symbol, such as: $
+	Symbol      interface{} `json:"symbol"`
+	DateFormat  *string     `json:"dateFormat"`
+	TimeFormat  *string     `json:"timeFormat"`
+	IncludeTime *bool       `json:"includeTime"`
+	ValueType   *string     `json:"valueType"`
+	// the date format of the date time fields, such as: YYYY/MM/DD
+	Format *string `json:"-"`
+	// the format of the formula and lookup fields
+	Formatting *formatProperty `json:"-"`
+}
+
+func (p *formatProperty) symbol() string {
+	if symbol, ok := p.Symbol.(string); ok {
+		return symbol
+	}
+	return ""
+}
+
+// dateFormat get the date format with the time format if the time is included
+func (p *formatProperty) dateFormat(fallback string) string {
+	format := fallback
+	if p.DateFormat != nil {
+		format = *p.DateFormat
+	} else if p.Format != nil {
+		format = *p.Format
+	}
+	if p.IncludeTime != nil && *p.IncludeTime && !strings.ContainsAny(format, "Hh") {
+		timeFormat := defaultTimeFormat
+		if p.TimeFormat != nil {
+			timeFormat = *p.TimeFormat
+		}
+		format += " " + timeFormat
+	}
+	return format
+}
+
+// Renderer render the cell values as the `CellFormat=string` does
+type Renderer struct {
+	// the location of the date time values, the default is UTC
+	Location *time.Location
+	// render the attachments as urls, the default is file names
+	AttachmentURLs bool
+	// the separator of the multiple values, the default is ", "
+	Separator string
+	// the parsed properties of the fields
+	properties map[*datasheet.DatasheetField]*formatProperty
+}
+
+func (r *Renderer) init() {
+	if r.Location == nil {
+		r.Location = time.UTC
+	}
+	if r.Separator == "" {
+		r.Separator = ", "
+	}
+	if r.properties == nil {
+		r.properties = map[*datasheet.DatasheetField]*formatProperty{}
+	}
+}
+
+func (r *Renderer) property(field *datasheet.DatasheetField) *formatProperty {
+	if property, ok := r.properties[field]; ok {
+		return property
+	}
+	property := &formatProperty{}
+	if field.Property != nil {
+		// the property is decoded leniently, the unknown properties are ignored.
+		raw := map[string]json.RawMessage{}
+		_ = json.Unmarshal(*field.Property, &raw)
+		if format, ok := raw["format"]; ok {
+			delete(raw, "format")
+			formatting := &formatProperty{}
+			if json.Unmarshal(format, formatting) == nil {
+				property.Formatting = formatting
+			} else {
+				// the format of the date time field is a string, such as: YYYY/MM/DD
+				_ = json.Unmarshal(format, &property.Format)
+			}
+		}
+		b, _ := json.Marshal(raw)
+		_ = json.Unmarshal(b, property)
+	}
+	r.properties[field] = property
+	return property
+}
+
+// Render render the cell value of the field, such as: $1.00, 12.50%, 2024/01/31, member names and attachment names.
+func (r *Renderer) Render(field *datasheet.DatasheetField, value interface{}) string {
+	r.init()
+	if value == nil {
+		return ""
+	}
+	fieldType := datasheet.FieldType("")
+	if field.Type != nil {
+		fieldType = *field.Type
+	}
+	property := r.property(field)
+	switch fieldType {
+	case datasheet.FieldType_Number:
+		return formatNumber(value, property.Precision)
+	case datasheet.FieldType_Currency:
+		return property.symbol() + formatNumber(value, property.Precision)
+	case datasheet.FieldType_Percent:
+		if number, ok := value.(float64); ok {
+			return formatNumber(number*100, property.Precision) + "%"
+		}
+	case datasheet.FieldType_DateTime:
+		return r.formatTime(value, property.dateFormat(defaultDateFormat))
+	case datasheet.FieldType_CreatedTime, datasheet.FieldType_LastModifiedTime:
+		return r.formatTime(value, property.dateFormat(defaultDateFormat+" "+defaultTimeFormat))
+	case datasheet.FieldType_Checkbox:
+		if checked, ok := value.(bool); ok && !checked {
+			return ""
+		}
+		return "true"
+	case datasheet.FieldType_Attachment:
+		return r.join(value, func(item interface{}) string {
+			attachment, _ := item.(map[string]interface{})
+			key := "name"
+			if r.AttachmentURLs {
+				key = "url"
+			}
+			if text, ok := attachment[key].(string); ok {
+				return text
+			}
+			return ""
+		})
+	case datasheet.FieldType_Member, datasheet.FieldType_CreatedBy, datasheet.FieldType_LastModifiedBy:
+		return r.join(value, func(item interface{}) string {
+			unit, _ := item.(map[string]interface{})
+			for _, key := range []string{"unitName", "name"} {
+				if name, ok := unit[key].(string); ok {
+					return name
+				}
+			}
+			return ""
+		})
+	case datasheet.FieldType_URL:
+		if link, ok := value.(map[string]interface{}); ok {
+			for _, key := range []string{"text", "title"} {
+				if text, ok := link[key].(string); ok && text != "" {
+					return text
+				}
+			}
+			return ""
+		}
+	case datasheet.FieldType_Formula, datasheet.FieldType_MagicLookUp:
+		if formatting := property.Formatting; formatting != nil && property.ValueType != nil {
+			switch datasheet.ValueType(*property.ValueType) {
+			case datasheet.ValueType_Number:
+				return formatting.symbol() + formatNumber(value, formatting.Precision)
+			case datasheet.ValueType_DateTime:
+				return r.formatTime(value, formatting.dateFormat(defaultDateFormat))
+			}
+		}
+	}
+	return r.join(value, r.plain)
+}
+
+// plain render the value without the field property
+func (r *Renderer) plain(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case map[string]interface{}:
+		// such as: the url object, the unit or the attachment in lookup values
+		for _, key := range []string{"text", "name", "unitName", "title", "url"} {
+			if s, ok := v[key].(string); ok {
+				return s
+			}
+		}
+	}
+	b, _ := json.Marshal(value)
+	return string(b)
+}
+
+// join render the array values separated by Separator
+func (r *Renderer) join(value interface{}, render func(item interface{}) string) string {
+	items, ok := value.([]interface{})
+	if !ok {
+		return render(value)
+	}
+	texts := make([]string, 0, len(items))
+	for _, item := range items {
+		if text := r.join(item, render); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	return strings.Join(texts, r.Separator)
+}
+
+func (r *Renderer) formatTime(value interface{}, format string) string {
+	var t time.Time
+	switch v := value.(type) {
+	case float64:
+		t = time.Unix(0, int64(v)*int64(time.Millisecond))
+	case string:
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return v
+		}
+		t = parsed
+	default:
+		return r.plain(value)
+	}
+	return t.In(r.Location).Format(DateLayout(format))
+}
+
+func formatNumber(value interface{}, precision *int) string {
+	number, ok := value.(float64)
+	if !ok {
+		return fmt.Sprint(value)
+	}
+	if precision == nil {
+		return strconv.FormatFloat(number, 'f', -1, 64)
+	}
+	return strconv.FormatFloat(number, 'f', *precision, 64)
+}
@@ -0,0 +1,146 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// the default sheet name of the xlsx
+const defaultSheetName = "Sheet1"
+
+// the max length of the sheet name
+const maxSheetNameLength = 31
+
+// the static parts of the minimal xlsx package
+var xlsxParts = []struct {
+	name    string
+	content string
+}{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`},
+}
+
+const workbookTemplate = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+	`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+	`<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets></workbook>`
+
+// sheetName remove the characters not allowed in the sheet name, and limit the length
+func sheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if runes := []rune(name); len(runes) > maxSheetNameLength {
+		name = string(runes[:maxSheetNameLength])
+	}
+	if name == "" {
+		return defaultSheetName
+	}
+	return name
+}
+
+// columnName the column letters of the index starts from 0, such as: A, Z, AA
+func columnName(index int) string {
+	name := ""
+	for index++; index > 0; index = (index - 1) / 26 {
+		name = string(rune('A'+(index-1)%26)) + name
+	}
+	return name
+}
+
+func escapeXML(s string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+// writeRow write one row of inline string cells, the rows start from 1
+func writeRow(w io.Writer, rowNum int, cells []string) error {
+	var b strings.Builder
+	b.WriteString(`<row r="` + strconv.Itoa(rowNum) + `">`)
+	for i, cell := range cells {
+		if cell == "" {
+			continue
+		}
+		ref := columnName(i) + strconv.Itoa(rowNum)
+		b.WriteString(`<c r="` + ref + `" t="inlineStr"><is><t xml:space="preserve">`)
+		b.WriteString(escapeXML(cell))
+		b.WriteString(`</t></is></c>`)
+	}
+	b.WriteString(`</row>`)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteXLSX write the header and the rendered records as a xlsx workbook with one sheet.
+//
+// * the workbook is written by archive/zip and the cells are inline strings, the sheet is streamed row by row.
+func WriteXLSX(w io.Writer, records RecordIterator, options *Options) (count int, err error) {
+	if err = checkOptions(options); err != nil {
+		return 0, err
+	}
+	archive := zip.NewWriter(w)
+	for _, part := range xlsxParts {
+		if err = writeZipFile(archive, part.name, part.content); err != nil {
+			return 0, err
+		}
+	}
+	name := sheetName(options.SheetName)
+	if err = writeZipFile(archive, "xl/workbook.xml", fmt.Sprintf(workbookTemplate, escapeXML(name))); err != nil {
+		return 0, err
+	}
+	sheet, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return 0, err
+	}
+	_, err = io.WriteString(sheet, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	if err == nil {
+		err = writeRow(sheet, 1, header(options))
+	}
+	if err != nil {
+		return 0, err
+	}
+	rowNum := 1
+	count, err = eachRow(records, options, func(row []string) error {
+		rowNum++
+		return writeRow(sheet, rowNum, row)
+	})
+	if err != nil {
+		return count, err
+	}
+	if _, err = io.WriteString(sheet, `</sheetData></worksheet>`); err != nil {
+		return count, err
+	}
+	return count, archive.Close()
+}
+
+func writeZipFile(archive *zip.Writer, name string, content string) error {
+	f, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(f, content)
+	return err
+}
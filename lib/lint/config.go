@@ -0,0 +1,226 @@
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	aterror "github.com/apitable/apitable-sdks/apitable.go/lib/common/error"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/datasheet"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Config the config of the linter, it can be written in Go or loaded from the json or the yaml file by LoadConfig.
+//
+// * the keys of the yaml are the same as the json, such as: staleAfter.
+// * the yaml subset without the anchors, the aliases and the tags is supported, see ParseYAMLConfig.
+type Config struct {
+	// the names of the fields which can't be empty
+	Required []string `json:"required,omitempty"`
+	// the records older than it are stale, such as: 720h, 30d. the stale rule is skipped if it's zero
+	StaleAfter Duration `json:"staleAfter,omitempty"`
+	// the time field name to check the stale records, the creation time of the record by default
+	StaleField string `json:"staleField,omitempty"`
+	// the regexp of the valid phone numbers, defaultPhonePattern by default
+	PhonePattern string `json:"phonePattern,omitempty"`
+	// the names of the disabled built-in rules, such as: stale
+	Disabled []string `json:"disabled,omitempty"`
+	// the severity of the rules by the rule name, such as: {"url": "error"}
+	Severities map[string]Severity `json:"severities,omitempty"`
+	// the custom rules
+	Rules []*RuleConfig `json:"rules,omitempty"`
+}
+
+// Duration the duration of the config, such as: 720h, 30d
+type Duration time.Duration
+
+// UnmarshalJSON parse the duration string, the day unit d is supported
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("the duration should be a string such as 720h, %s", err)
+	}
+	duration, err := parseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(duration)
+	return nil
+}
+
+// MarshalJSON the duration string
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func parseDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasSuffix(s, "d") {
+		days, err := strconv.ParseFloat(strings.TrimSuffix(s, "d"), 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %s", s)
+		}
+		return time.Duration(days * float64(24*time.Hour)), nil
+	}
+	return time.ParseDuration(s)
+}
+
+// RuleConfig the custom rule checking the text of a field, the empty values are only checked by Required.
+//
+// * the array values, such as the multi select, are checked item by item.
+// * the message can contain {field} and {value}, such as: {field} should be an email
+type RuleConfig struct {
+	// the unique rule name
+	Name     string   `json:"name"`
+	Field    string   `json:"field"`
+	Severity Severity `json:"severity,omitempty"`
+	Message  string   `json:"message,omitempty"`
+	Required bool     `json:"required,omitempty"`
+	// the regexp the text should match, such as: ^[^@]+@[^@]+$
+	Pattern string `json:"pattern,omitempty"`
+	// the allowed texts
+	Values []string `json:"values,omitempty"`
+	// the range of the number
+	Min *float64 `json:"min,omitempty"`
+	Max *float64 `json:"max,omitempty"`
+	// the range of the text length in characters
+	MinLength *int `json:"minLength,omitempty"`
+	MaxLength *int `json:"maxLength,omitempty"`
+}
+
+// ParseConfig parse the json config, the unknown keys are rejected to find the typos
+func ParseConfig(b []byte) (*Config, error) {
+	config := &Config{}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(config); err != nil {
+		msg := fmt.Sprintf("Invalid lint config because %s", err)
+		return nil, aterror.NewSDKError(400, msg, "ClientError.DecodeError")
+	}
+	return config, nil
+}
+
+// ParseYAMLConfig parse the yaml config, it's converted to json and parsed by ParseConfig.
+//
+// * the block and the flow collections, the quoted and the plain scalars, and the | and > block scalars are supported.
+// * the anchors, the aliases, the tags and the multiple documents are not supported.
+func ParseYAMLConfig(b []byte) (*Config, error) {
+	value, err := parseYAML(b)
+	if err != nil {
+		msg := fmt.Sprintf("Invalid lint config because %s", err)
+		return nil, aterror.NewSDKError(400, msg, "ClientError.DecodeError")
+	}
+	if _, ok := value.(map[string]interface{}); !ok {
+		return nil, aterror.NewSDKError(400, "Invalid lint config because it's not a mapping", "ClientError.DecodeError")
+	}
+	b, err = json.Marshal(value)
+	if err != nil {
+		return nil, aterror.NewSDKError(400, err.Error(), "ClientError.DecodeError")
+	}
+	return ParseConfig(b)
+}
+
+// LoadConfig read the config file, the file of the .yaml or the .yml extension is parsed as yaml, others as json
+func LoadConfig(filePath string) (*Config, error) {
+	b, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		msg := fmt.Sprintf("Fail to read lint config because %s", err)
+		return nil, aterror.NewSDKError(500, msg, "ClientError.FileReadError")
+	}
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".yaml", ".yml":
+		return ParseYAMLConfig(b)
+	}
+	return ParseConfig(b)
+}
+
+// compile validate the rule config and init the rule
+func (c *RuleConfig) compile() (Rule, error) {
+	if c.Name == "" || c.Field == "" {
+		msg := fmt.Sprintf("The name and the field of the rule are required: %q, %q", c.Name, c.Field)
+		return nil, aterror.NewSDKError(400, msg, "ClientError.InvalidArgument")
+	}
+	if _, ok := defaultSeverities[c.Name]; ok {
+		msg := fmt.Sprintf("The rule name %s is used by the built-in rule", c.Name)
+		return nil, aterror.NewSDKError(400, msg, "ClientError.InvalidArgument")
+	}
+	if c.Severity != "" {
+		if err := c.Severity.validate(); err != nil {
+			return nil, err
+		}
+	}
+	var pattern *regexp.Regexp
+	if c.Pattern != "" {
+		var err error
+		if pattern, err = regexp.Compile(c.Pattern); err != nil {
+			msg := fmt.Sprintf("Invalid pattern of the rule %s because %s", c.Name, err)
+			return nil, aterror.NewSDKError(400, msg, "ClientError.InvalidArgument")
+		}
+	}
+	values := map[string]bool{}
+	for _, value := range c.Values {
+		values[value] = true
+	}
+	return NewRule(c.Name, func(ctx *Context, record *datasheet.Record) []*Issue {
+		if record == nil {
+			if ctx.Field(c.Field) == nil {
+				return []*Issue{{Field: c.Field, Message: fmt.Sprintf("The field %s of the rule %s is not found", c.Field, c.Name)}}
+			}
+			return nil
+		}
+		if ctx.Field(c.Field) == nil {
+			return nil
+		}
+		value := Value(record, c.Field)
+		if isEmpty(value) {
+			if c.Required {
+				return []*Issue{c.issue("{field} is required", nil)}
+			}
+			return nil
+		}
+		items, ok := value.([]interface{})
+		if !ok {
+			items = []interface{}{value}
+		}
+		issues := []*Issue{}
+		for _, item := range items {
+			s := text(item)
+			if number, ok := item.(float64); ok {
+				if c.Min != nil && number < *c.Min {
+					issues = append(issues, c.issue(fmt.Sprintf("{field} {value} is less than %v", *c.Min), number))
+				}
+				if c.Max != nil && number > *c.Max {
+					issues = append(issues, c.issue(fmt.Sprintf("{field} {value} is greater than %v", *c.Max), number))
+				}
+			}
+			length := utf8.RuneCountInString(s)
+			if c.MinLength != nil && length < *c.MinLength {
+				issues = append(issues, c.issue(fmt.Sprintf("{field} is shorter than %d characters", *c.MinLength), s))
+			}
+			if c.MaxLength != nil && length > *c.MaxLength {
+				issues = append(issues, c.issue(fmt.Sprintf("{field} is longer than %d characters", *c.MaxLength), s))
+			}
+			if pattern != nil && !pattern.MatchString(s) {
+				issues = append(issues, c.issue("{field} doesn't match the pattern: {value}", s))
+			}
+			if len(values) > 0 && !values[s] {
+				issues = append(issues, c.issue("{field} is not allowed: {value}", s))
+			}
+		}
+		return issues
+	}), nil
+}
+
+// issue the issue with the custom message, or the default message if it's not configured
+func (c *RuleConfig) issue(message string, value interface{}) *Issue {
+	if c.Message != "" {
+		message = c.Message
+	}
+	message = strings.NewReplacer("{field}", c.Field, "{value}", text(value)).Replace(message)
+	return &Issue{Field: c.Field, Value: value, Message: message}
+}
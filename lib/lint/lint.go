@@ -0,0 +1,259 @@
+// Package lint provides the data quality checks of the datasheet records against the schema
+package lint
+
+import (
+	"fmt"
+	aterror "github.com/apitable/apitable-sdks/apitable.go/lib/common/error"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/datasheet"
+	"time"
+)
+
+// Severity the severity of the issue
+type Severity string
+
+const (
+	Severity_Error   Severity = "error"
+	Severity_Warning Severity = "warning"
+	Severity_Info    Severity = "info"
+)
+
+// validate the severity is one of the known severities
+func (s Severity) validate() error {
+	switch s {
+	case Severity_Error, Severity_Warning, Severity_Info:
+		return nil
+	}
+	msg := fmt.Sprintf("Unknown severity %q, it should be error, warning or info", string(s))
+	return aterror.NewSDKError(400, msg, "ClientError.InvalidArgument")
+}
+
+// Issue the problem of a record or a field
+type Issue struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	// empty for the issues of the field, such as the formula error
+	RecordId string      `json:"recordId,omitempty"`
+	Field    string      `json:"field,omitempty"`
+	Value    interface{} `json:"value,omitempty"`
+	Message  string      `json:"message"`
+}
+
+// Context the schema and the time of the lint run, shared by the rules
+type Context struct {
+	// the fields of the datasheet in order
+	Fields []*datasheet.DatasheetField
+	// the time to check the stale records
+	Now time.Time
+	// the existing record ids linked by the link field name, the link fields not in the map are not checked
+	LinkedRecords map[string]map[string]bool
+	fields        map[string]*datasheet.DatasheetField
+}
+
+// Field get the field by name, nil if not found
+func (c *Context) Field(name string) *datasheet.DatasheetField {
+	if c.fields == nil {
+		c.fields = map[string]*datasheet.DatasheetField{}
+		for _, field := range c.Fields {
+			if field.Name != nil {
+				c.fields[*field.Name] = field
+			}
+		}
+	}
+	return c.fields[name]
+}
+
+// Value get the cell value of the record
+func Value(record *datasheet.Record, field string) interface{} {
+	if record == nil || record.BaseRecord == nil || record.Fields == nil {
+		return nil
+	}
+	return (*record.Fields)[field]
+}
+
+// Rule check the records, the issues are returned without Rule and Severity, they are filled by the linter
+type Rule interface {
+	// the unique rule name, such as: required
+	Name() string
+	// check the record, the schema rules are called once with the nil record
+	Check(ctx *Context, record *datasheet.Record) []*Issue
+}
+
+// RuleFunc the function of the custom rule
+type RuleFunc func(ctx *Context, record *datasheet.Record) []*Issue
+
+type funcRule struct {
+	name string
+	fn   RuleFunc
+}
+
+func (r *funcRule) Name() string {
+	return r.name
+}
+
+func (r *funcRule) Check(ctx *Context, record *datasheet.Record) []*Issue {
+	return r.fn(ctx, record)
+}
+
+// NewRule init the custom rule of the function
+func NewRule(name string, fn RuleFunc) Rule {
+	return &funcRule{name: name, fn: fn}
+}
+
+// Linter check the records by the built-in rules of the config and the custom rules
+type Linter struct {
+	config *Config
+	rules  []Rule
+	// the severity of the rules by the rule name
+	severities map[string]Severity
+}
+
+// New init the linter, the config is optional, and the rules are added after the rules of the config.
+func New(config *Config, rules ...Rule) (*Linter, error) {
+	if config == nil {
+		config = &Config{}
+	}
+	l := &Linter{config: config, severities: map[string]Severity{}}
+	disabled := map[string]bool{}
+	for _, name := range config.Disabled {
+		disabled[name] = true
+	}
+	builtins, err := config.builtinRules()
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range builtins {
+		if !disabled[rule.Name()] {
+			l.rules = append(l.rules, rule)
+		}
+	}
+	names := map[string]bool{}
+	for i, ruleConfig := range config.Rules {
+		if ruleConfig == nil {
+			msg := fmt.Sprintf("The rule %d of the config is null", i)
+			return nil, aterror.NewSDKError(400, msg, "ClientError.InvalidArgument")
+		}
+		rule, err := ruleConfig.compile()
+		if err != nil {
+			return nil, err
+		}
+		if names[ruleConfig.Name] {
+			msg := fmt.Sprintf("The rule name %s is duplicated", ruleConfig.Name)
+			return nil, aterror.NewSDKError(400, msg, "ClientError.InvalidArgument")
+		}
+		names[ruleConfig.Name] = true
+		l.rules = append(l.rules, rule)
+		if ruleConfig.Severity != "" {
+			l.severities[ruleConfig.Name] = ruleConfig.Severity
+		}
+	}
+	l.rules = append(l.rules, rules...)
+	for name, severity := range config.Severities {
+		if err = severity.validate(); err != nil {
+			return nil, err
+		}
+		l.severities[name] = severity
+	}
+	return l, nil
+}
+
+// Rules the names of the enabled rules
+func (l *Linter) Rules() []string {
+	names := make([]string, len(l.rules))
+	for i, rule := range l.rules {
+		names[i] = rule.Name()
+	}
+	return names
+}
+
+func (l *Linter) severity(rule string) Severity {
+	if severity, ok := l.severities[rule]; ok {
+		return severity
+	}
+	if severity, ok := defaultSeverities[rule]; ok {
+		return severity
+	}
+	return Severity_Warning
+}
+
+// Lint check the records, the schema rules are checked once.
+//
+// * the issues of the schema are the first, then the issues in the order of the records.
+func (l *Linter) Lint(ctx *Context, records []*datasheet.Record) *Report {
+	if ctx.Now.IsZero() {
+		ctx.Now = time.Now()
+	}
+	report := &Report{Records: len(records), Issues: []*Issue{}, Counts: map[string]int{}, CheckedAt: ctx.Now.UnixNano() / int64(time.Millisecond)}
+	add := func(rule Rule, issues []*Issue, recordId string) {
+		for _, issue := range issues {
+			issue.Rule = rule.Name()
+			if issue.Severity == "" {
+				issue.Severity = l.severity(rule.Name())
+			}
+			if issue.RecordId == "" {
+				issue.RecordId = recordId
+			}
+			report.Issues = append(report.Issues, issue)
+			report.Counts[issue.Rule]++
+		}
+	}
+	for _, rule := range l.rules {
+		add(rule, rule.Check(ctx, nil), "")
+	}
+	for _, record := range records {
+		if record == nil || record.BaseRecord == nil || record.RecordId == nil {
+			continue
+		}
+		for _, rule := range l.rules {
+			add(rule, rule.Check(ctx, record), *record.RecordId)
+		}
+	}
+	return report
+}
+
+// Run check all records of the datasheet.
+//
+// * the fields and the records are queried, and the linked records are queried by the record ids to find the dangling links.
+// * the link check is skipped if the link rule is disabled.
+func (l *Linter) Run(dst *datasheet.Datasheet) (*Report, error) {
+	fields, err := dst.DescribeFields(nil)
+	if err != nil {
+		return nil, err
+	}
+	records, err := dst.DescribeAllRecords(nil)
+	if err != nil {
+		return nil, err
+	}
+	ctx := &Context{Fields: fields, LinkedRecords: map[string]map[string]bool{}}
+	if l.enabled(Rule_DanglingLink) {
+		for _, field := range fields {
+			if field.Name == nil || field.Type == nil || *field.Type != datasheet.FieldType_MagicLink {
+				continue
+			}
+			expanded, err := dst.ExpandLinks(records, *field.Name, 1)
+			if err != nil {
+				return nil, err
+			}
+			existing := map[string]bool{}
+			for _, record := range expanded {
+				for _, linked := range record.Links[*field.Name] {
+					if linked.BaseRecord != nil && linked.RecordId != nil {
+						existing[*linked.RecordId] = true
+					}
+				}
+			}
+			ctx.LinkedRecords[*field.Name] = existing
+		}
+	}
+	report := l.Lint(ctx, records)
+	report.DatasheetId = dst.DatasheetId
+	return report, nil
+}
+
+func (l *Linter) enabled(name string) bool {
+	for _, rule := range l.rules {
+		if rule.Name() == name {
+			return true
+		}
+	}
+	return false
+}
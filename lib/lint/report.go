@@ -0,0 +1,75 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Report the issues of the lint run
+type Report struct {
+	DatasheetId string `json:"datasheetId,omitempty"`
+	// the count of the checked records
+	Records int      `json:"records"`
+	Issues  []*Issue `json:"issues"`
+	// the count of the issues by the rule name
+	Counts map[string]int `json:"counts"`
+	// the timestamp of the lint run in milliseconds
+	CheckedAt int64 `json:"checkedAt"`
+}
+
+// HasErrors the report has the issues of the error severity
+func (r *Report) HasErrors() bool {
+	for _, issue := range r.Issues {
+		if issue.Severity == Severity_Error {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteJSON write the machine readable report
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// String the human readable report, the issues are grouped by the severity and the rule
+func (r *Report) String() string {
+	var b strings.Builder
+	if r.DatasheetId != "" {
+		fmt.Fprintf(&b, "%s: ", r.DatasheetId)
+	}
+	severities := map[Severity]int{}
+	for _, issue := range r.Issues {
+		severities[issue.Severity]++
+	}
+	fmt.Fprintf(&b, "%d records, %d issues (%d errors, %d warnings, %d infos)\n", r.Records, len(r.Issues),
+		severities[Severity_Error], severities[Severity_Warning], severities[Severity_Info])
+	for _, severity := range []Severity{Severity_Error, Severity_Warning, Severity_Info} {
+		rules := []string{}
+		groups := map[string][]*Issue{}
+		for _, issue := range r.Issues {
+			if issue.Severity != severity {
+				continue
+			}
+			if _, ok := groups[issue.Rule]; !ok {
+				rules = append(rules, issue.Rule)
+			}
+			groups[issue.Rule] = append(groups[issue.Rule], issue)
+		}
+		for _, rule := range rules {
+			fmt.Fprintf(&b, "%s %s (%d):\n", severity, rule, len(groups[rule]))
+			for _, issue := range groups[rule] {
+				recordId := issue.RecordId
+				if recordId == "" {
+					recordId = "-"
+				}
+				fmt.Fprintf(&b, "  %s: %s\n", recordId, issue.Message)
+			}
+		}
+	}
+	return b.String()
+}
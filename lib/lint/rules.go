@@ -0,0 +1,328 @@
+package lint
+
+import (
+	"fmt"
+	aterror "github.com/apitable/apitable-sdks/apitable.go/lib/common/error"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/datasheet"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// the names of the built-in rules
+const (
+	// the required fields are empty
+	Rule_Required = "required"
+	// the url fields are not valid urls
+	Rule_InvalidURL = "url"
+	// the phone fields are not valid phone numbers
+	Rule_InvalidPhone = "phone"
+	// the select values are not the options of the field
+	Rule_UnknownOption = "select"
+	// the linked records are deleted
+	Rule_DanglingLink = "link"
+	// the ratings are above the max of the field
+	Rule_RatingRange = "rating"
+	// the records are older than Config.StaleAfter
+	Rule_Stale = "stale"
+	// the formulas have errors
+	Rule_FormulaError = "formula"
+)
+
+var defaultSeverities = map[string]Severity{
+	Rule_Required:      Severity_Error,
+	Rule_InvalidURL:    Severity_Warning,
+	Rule_InvalidPhone:  Severity_Warning,
+	Rule_UnknownOption: Severity_Error,
+	Rule_DanglingLink:  Severity_Error,
+	Rule_RatingRange:   Severity_Error,
+	Rule_Stale:         Severity_Info,
+	Rule_FormulaError:  Severity_Error,
+}
+
+// the default phone number pattern, such as: +86 138-0000-0000, (021) 1234 5678
+const defaultPhonePattern = `^\+?[0-9(][0-9()\-. ]{3,}[0-9]$`
+
+// the default max of the rating field
+const defaultMaxRating = 5
+
+// builtinRules the built-in rules of the config in the order of the checks
+func (c *Config) builtinRules() ([]Rule, error) {
+	pattern := defaultPhonePattern
+	if c.PhonePattern != "" {
+		pattern = c.PhonePattern
+	}
+	phone, err := regexp.Compile(pattern)
+	if err != nil {
+		msg := fmt.Sprintf("Invalid phone pattern %s because %s", pattern, err)
+		return nil, aterror.NewSDKError(400, msg, "ClientError.InvalidArgument")
+	}
+	return []Rule{
+		NewRule(Rule_FormulaError, checkFormula),
+		NewRule(Rule_Required, func(ctx *Context, record *datasheet.Record) []*Issue {
+			return checkRequired(ctx, record, c.Required)
+		}),
+		NewRule(Rule_InvalidURL, checkURL),
+		NewRule(Rule_InvalidPhone, func(ctx *Context, record *datasheet.Record) []*Issue {
+			return checkPhone(ctx, record, phone)
+		}),
+		NewRule(Rule_UnknownOption, checkOptions),
+		NewRule(Rule_DanglingLink, checkLinks),
+		NewRule(Rule_RatingRange, checkRating),
+		NewRule(Rule_Stale, func(ctx *Context, record *datasheet.Record) []*Issue {
+			return checkStale(ctx, record, time.Duration(c.StaleAfter), c.StaleField)
+		}),
+	}, nil
+}
+
+// fieldsOf the named fields of the type
+func fieldsOf(ctx *Context, fieldTypes ...datasheet.FieldType) []*datasheet.DatasheetField {
+	fields := []*datasheet.DatasheetField{}
+	for _, field := range ctx.Fields {
+		if field.Name == nil || field.Type == nil {
+			continue
+		}
+		for _, fieldType := range fieldTypes {
+			if *field.Type == fieldType {
+				fields = append(fields, field)
+			}
+		}
+	}
+	return fields
+}
+
+// isEmpty the value is nil, the empty string or the empty array
+func isEmpty(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(v) == ""
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+// text the text of the string, number or the object value, such as the url object {"text": "https://..."}
+func text(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case map[string]interface{}:
+		for _, key := range []string{"text", "name", "title"} {
+			if s, ok := v[key].(string); ok {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+func checkFormula(ctx *Context, record *datasheet.Record) []*Issue {
+	if record != nil {
+		return nil
+	}
+	issues := []*Issue{}
+	for _, field := range fieldsOf(ctx, datasheet.FieldType_Formula) {
+		if field.Property == nil {
+			continue
+		}
+		property := field.FormulaFieldProperty()
+		if property != nil && property.HasError != nil && *property.HasError {
+			expression := ""
+			if property.Expression != nil {
+				expression = *property.Expression
+			}
+			issues = append(issues, &Issue{Field: *field.Name, Value: expression, Message: fmt.Sprintf("The formula of %s has errors", *field.Name)})
+		}
+	}
+	return issues
+}
+
+func checkRequired(ctx *Context, record *datasheet.Record, required []string) []*Issue {
+	issues := []*Issue{}
+	for _, name := range required {
+		if ctx.Field(name) == nil {
+			// the missing field is reported once with the schema
+			if record == nil {
+				issues = append(issues, &Issue{Field: name, Message: fmt.Sprintf("The required field %s is not found", name)})
+			}
+			continue
+		}
+		if record != nil && isEmpty(Value(record, name)) {
+			issues = append(issues, &Issue{Field: name, Message: fmt.Sprintf("%s is required", name)})
+		}
+	}
+	return issues
+}
+
+// validURL the url with the host, the scheme is optional, such as: www.example.com/path
+func validURL(s string) bool {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.ContainsAny(s, " \t\n") {
+		return false
+	}
+	if !strings.Contains(s, "://") && !strings.HasPrefix(s, "mailto:") {
+		s = "http://" + s
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return false
+	}
+	if u.Scheme == "mailto" {
+		return strings.Contains(u.Opaque, "@")
+	}
+	host := u.Hostname()
+	return host == "localhost" || (strings.Contains(host, ".") && !strings.HasPrefix(host, ".") && !strings.HasSuffix(host, "."))
+}
+
+func checkURL(ctx *Context, record *datasheet.Record) []*Issue {
+	if record == nil {
+		return nil
+	}
+	issues := []*Issue{}
+	for _, field := range fieldsOf(ctx, datasheet.FieldType_URL) {
+		value := Value(record, *field.Name)
+		if isEmpty(value) {
+			continue
+		}
+		if s := text(value); !validURL(s) {
+			issues = append(issues, &Issue{Field: *field.Name, Value: s, Message: fmt.Sprintf("%s is not a valid url: %s", *field.Name, s)})
+		}
+	}
+	return issues
+}
+
+func checkPhone(ctx *Context, record *datasheet.Record, pattern *regexp.Regexp) []*Issue {
+	if record == nil {
+		return nil
+	}
+	issues := []*Issue{}
+	for _, field := range fieldsOf(ctx, datasheet.FieldType_Phone) {
+		value := Value(record, *field.Name)
+		if isEmpty(value) {
+			continue
+		}
+		if s := text(value); !pattern.MatchString(strings.TrimSpace(s)) {
+			issues = append(issues, &Issue{Field: *field.Name, Value: s, Message: fmt.Sprintf("%s is not a valid phone number: %s", *field.Name, s)})
+		}
+	}
+	return issues
+}
+
+func checkOptions(ctx *Context, record *datasheet.Record) []*Issue {
+	if record == nil {
+		return nil
+	}
+	issues := []*Issue{}
+	for _, field := range fieldsOf(ctx, datasheet.FieldType_SingleSelect, datasheet.FieldType_MultiSelect) {
+		value := Value(record, *field.Name)
+		if isEmpty(value) || field.Property == nil {
+			continue
+		}
+		property := field.SelectFieldProperty()
+		if property == nil {
+			continue
+		}
+		options := map[string]bool{}
+		for _, option := range property.Options {
+			if option.Name != nil {
+				options[*option.Name] = true
+			}
+		}
+		values, ok := value.([]interface{})
+		if !ok {
+			values = []interface{}{value}
+		}
+		for _, item := range values {
+			if s := text(item); !options[s] {
+				issues = append(issues, &Issue{Field: *field.Name, Value: s, Message: fmt.Sprintf("%s is not an option of %s", s, *field.Name)})
+			}
+		}
+	}
+	return issues
+}
+
+func checkLinks(ctx *Context, record *datasheet.Record) []*Issue {
+	if record == nil {
+		return nil
+	}
+	issues := []*Issue{}
+	for _, field := range fieldsOf(ctx, datasheet.FieldType_MagicLink) {
+		existing, ok := ctx.LinkedRecords[*field.Name]
+		if !ok {
+			continue
+		}
+		ids, _ := Value(record, *field.Name).([]interface{})
+		for _, id := range ids {
+			if s, _ := id.(string); s != "" && !existing[s] {
+				issues = append(issues, &Issue{Field: *field.Name, Value: s, Message: fmt.Sprintf("%s links the deleted record %s", *field.Name, s)})
+			}
+		}
+	}
+	return issues
+}
+
+func checkRating(ctx *Context, record *datasheet.Record) []*Issue {
+	if record == nil {
+		return nil
+	}
+	issues := []*Issue{}
+	for _, field := range fieldsOf(ctx, datasheet.FieldType_Rating) {
+		rating, ok := Value(record, *field.Name).(float64)
+		if !ok {
+			continue
+		}
+		max := defaultMaxRating
+		if field.Property != nil {
+			if property := field.RatingFieldProperty(); property != nil && property.Max != nil {
+				max = *property.Max
+			}
+		}
+		if rating > float64(max) || rating < 0 {
+			issues = append(issues, &Issue{Field: *field.Name, Value: rating, Message: fmt.Sprintf("%s %v is out of the range 0-%d", *field.Name, rating, max)})
+		}
+	}
+	return issues
+}
+
+// checkStale check the time field, or the creation time of the record if the field is empty
+func checkStale(ctx *Context, record *datasheet.Record, after time.Duration, field string) []*Issue {
+	if after <= 0 {
+		return nil
+	}
+	if record == nil {
+		if field != "" && ctx.Field(field) == nil {
+			return []*Issue{{Field: field, Message: fmt.Sprintf("The stale field %s is not found", field)}}
+		}
+		return nil
+	}
+	var millis float64
+	if field == "" {
+		if record.CreatedAt == nil {
+			return nil
+		}
+		millis = float64(*record.CreatedAt)
+	} else if value, ok := Value(record, field).(float64); ok {
+		millis = value
+	} else {
+		return nil
+	}
+	at := time.Unix(0, int64(millis)*int64(time.Millisecond))
+	if age := ctx.Now.Sub(at); age > after {
+		name := field
+		if name == "" {
+			name = "createdAt"
+		}
+		days := int(age.Hours() / 24)
+		return []*Issue{{Field: field, Value: at.UTC().Format(time.RFC3339), Message: fmt.Sprintf("The record is stale, %s is %d days ago", name, days)}}
+	}
+	return nil
+}
@@ -0,0 +1,416 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// yamlLine the line of the yaml document without the comment
+type yamlLine struct {
+	number int
+	indent int
+	text   string
+}
+
+// yamlParser the parser of the yaml subset used by the config, so the module needs no yaml dependency, see ParseYAMLConfig
+type yamlParser struct {
+	raw   []string
+	lines []*yamlLine
+	pos   int
+}
+
+// parseYAML parse the yaml document into the json compatible values
+func parseYAML(b []byte) (interface{}, error) {
+	p := &yamlParser{raw: strings.Split(strings.Replace(string(b), "\r\n", "\n", -1), "\n")}
+	for i, raw := range p.raw {
+		text := stripComment(raw)
+		trimmed := strings.TrimSpace(text)
+		if trimmed == "" || (i == 0 && trimmed == "---") {
+			continue
+		}
+		indent := len(text) - len(strings.TrimLeft(text, " "))
+		if strings.HasPrefix(text[indent:], "\t") {
+			return nil, fmt.Errorf("line %d: the tab is not allowed in the indent", i+1)
+		}
+		p.lines = append(p.lines, &yamlLine{number: i + 1, indent: indent, text: strings.TrimRight(text[indent:], " \t")})
+	}
+	if len(p.lines) == 0 {
+		return map[string]interface{}{}, nil
+	}
+	value, err := p.parseBlock(p.lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.lines) {
+		return nil, fmt.Errorf("line %d: unexpected indent", p.lines[p.pos].number)
+	}
+	return value, nil
+}
+
+// stripComment remove the comment starting with # outside the quotes
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+func isSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+func (p *yamlParser) parseBlock(indent int) (interface{}, error) {
+	if isSequenceItem(p.lines[p.pos].text) {
+		return p.parseSequence(indent)
+	}
+	return p.parseMapping(indent)
+}
+
+// parseNested parse the value of the empty key or the empty item, the value is in the next lines of the deeper indent
+func (p *yamlParser) parseNested(indent int, sequenceAllowed bool) (interface{}, error) {
+	if p.pos >= len(p.lines) {
+		return nil, nil
+	}
+	next := p.lines[p.pos]
+	if next.indent > indent || (sequenceAllowed && next.indent == indent && isSequenceItem(next.text)) {
+		return p.parseBlock(next.indent)
+	}
+	return nil, nil
+}
+
+func (p *yamlParser) parseSequence(indent int) (interface{}, error) {
+	items := []interface{}{}
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		if line.indent < indent || (line.indent == indent && !isSequenceItem(line.text)) {
+			break
+		}
+		if line.indent > indent {
+			return nil, fmt.Errorf("line %d: unexpected indent", line.number)
+		}
+		content := strings.TrimLeft(strings.TrimPrefix(line.text, "-"), " ")
+		if content == "" {
+			p.pos++
+			item, err := p.parseNested(indent, false)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+			continue
+		}
+		offset := len(line.text) - len(content)
+		if _, _, ok := splitKey(content); ok || isSequenceItem(content) {
+			// the mapping or the sequence starting in the item, such as: `- name: email`
+			p.lines[p.pos] = &yamlLine{number: line.number, indent: indent + offset, text: content}
+			item, err := p.parseBlock(indent + offset)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+			continue
+		}
+		p.pos++
+		item, err := p.parseValue(content, line, indent)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (p *yamlParser) parseMapping(indent int) (interface{}, error) {
+	mapping := map[string]interface{}{}
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		if line.indent < indent || (line.indent == indent && isSequenceItem(line.text)) {
+			break
+		}
+		if line.indent > indent {
+			return nil, fmt.Errorf("line %d: unexpected indent", line.number)
+		}
+		key, rest, ok := splitKey(line.text)
+		if !ok {
+			return nil, fmt.Errorf("line %d: expect the key: value, but got %s", line.number, line.text)
+		}
+		if _, ok := mapping[key]; ok {
+			return nil, fmt.Errorf("line %d: duplicate key %s", line.number, key)
+		}
+		p.pos++
+		var value interface{}
+		var err error
+		if rest == "" {
+			value, err = p.parseNested(indent, true)
+		} else {
+			value, err = p.parseValue(rest, line, indent)
+		}
+		if err != nil {
+			return nil, err
+		}
+		mapping[key] = value
+	}
+	return mapping, nil
+}
+
+// splitKey split the `key: value` outside the quotes and the flow collections
+func splitKey(text string) (key string, rest string, ok bool) {
+	if strings.HasPrefix(text, "[") || strings.HasPrefix(text, "{") {
+		return "", "", false
+	}
+	end := 0
+	if strings.HasPrefix(text, `"`) || strings.HasPrefix(text, "'") {
+		s := &flowScanner{text: text}
+		value, err := s.quoted()
+		if err != nil {
+			return "", "", false
+		}
+		key, end = value.(string), s.pos
+		if end >= len(text) || text[end] != ':' {
+			return "", "", false
+		}
+	} else {
+		end = strings.Index(text, ": ")
+		if end < 0 {
+			if !strings.HasSuffix(text, ":") {
+				return "", "", false
+			}
+			end = len(text) - 1
+		}
+		key = strings.TrimSpace(text[:end])
+	}
+	if end+1 < len(text) && text[end+1] != ' ' {
+		return "", "", false
+	}
+	return key, strings.TrimSpace(text[end+1:]), true
+}
+
+// parseValue parse the value after the key or the item mark, the block scalar reads the next raw lines
+func (p *yamlParser) parseValue(text string, line *yamlLine, indent int) (interface{}, error) {
+	if strings.HasPrefix(text, "|") || strings.HasPrefix(text, ">") {
+		return p.parseBlockScalar(text, line, indent)
+	}
+	s := &flowScanner{text: text}
+	value, err := s.value(false)
+	if err == nil {
+		s.skipSpaces()
+		if s.pos < len(s.text) {
+			err = fmt.Errorf("unexpected %s", s.text[s.pos:])
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("line %d: %s", line.number, err)
+	}
+	return value, nil
+}
+
+// parseBlockScalar read the literal or the folded text, the trailing line break is kept by default, - strips it
+func (p *yamlParser) parseBlockScalar(header string, line *yamlLine, indent int) (interface{}, error) {
+	chomp := strings.TrimLeft(header[1:], " ")
+	if chomp != "" && chomp != "-" && chomp != "+" {
+		return nil, fmt.Errorf("line %d: unsupported block scalar %s", line.number, header)
+	}
+	texts := []string{}
+	blockIndent := -1
+	end := line.number
+	for i := line.number; i < len(p.raw); i++ {
+		raw := strings.TrimRight(p.raw[i], " \t\r")
+		if raw == "" {
+			texts = append(texts, "")
+			continue
+		}
+		lineIndent := len(raw) - len(strings.TrimLeft(raw, " "))
+		if blockIndent < 0 {
+			blockIndent = lineIndent
+		}
+		if lineIndent <= indent || lineIndent < blockIndent {
+			break
+		}
+		texts = append(texts, raw[blockIndent:])
+		end = i + 1
+	}
+	texts = texts[:end-line.number]
+	for p.pos < len(p.lines) && p.lines[p.pos].number <= end {
+		p.pos++
+	}
+	separator := "\n"
+	if header[0] == '>' {
+		separator = " "
+	}
+	text := strings.Join(texts, separator)
+	switch chomp {
+	case "-":
+		return text, nil
+	case "+":
+		return text + "\n", nil
+	}
+	if text == "" {
+		return "", nil
+	}
+	return strings.TrimRight(text, "\n") + "\n", nil
+}
+
+// flowScanner scan the scalars and the flow collections in one line
+type flowScanner struct {
+	text string
+	pos  int
+}
+
+func (s *flowScanner) skipSpaces() {
+	for s.pos < len(s.text) && s.text[s.pos] == ' ' {
+		s.pos++
+	}
+}
+
+// value scan the value, the plain scalar in the flow collection ends with the comma or the bracket
+func (s *flowScanner) value(inFlow bool) (interface{}, error) {
+	s.skipSpaces()
+	if s.pos >= len(s.text) {
+		return nil, nil
+	}
+	switch s.text[s.pos] {
+	case '[':
+		return s.sequence()
+	case '{':
+		return s.mapping()
+	case '"', '\'':
+		return s.quoted()
+	}
+	start := s.pos
+	for s.pos < len(s.text) {
+		c := s.text[s.pos]
+		if inFlow && (c == ',' || c == ']' || c == '}' || (c == ':' && (s.pos+1 == len(s.text) || s.text[s.pos+1] == ' '))) {
+			break
+		}
+		s.pos++
+	}
+	return plainScalar(strings.TrimSpace(s.text[start:s.pos])), nil
+}
+
+func (s *flowScanner) sequence() (interface{}, error) {
+	s.pos++
+	items := []interface{}{}
+	for {
+		s.skipSpaces()
+		if s.pos < len(s.text) && s.text[s.pos] == ']' {
+			s.pos++
+			return items, nil
+		}
+		item, err := s.value(true)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		if err = s.separator(']'); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (s *flowScanner) mapping() (interface{}, error) {
+	s.pos++
+	mapping := map[string]interface{}{}
+	for {
+		s.skipSpaces()
+		if s.pos < len(s.text) && s.text[s.pos] == '}' {
+			s.pos++
+			return mapping, nil
+		}
+		key, err := s.value(true)
+		if err != nil {
+			return nil, err
+		}
+		s.skipSpaces()
+		if s.pos >= len(s.text) || s.text[s.pos] != ':' {
+			return nil, fmt.Errorf("expect : after the key %v", key)
+		}
+		s.pos++
+		value, err := s.value(true)
+		if err != nil {
+			return nil, err
+		}
+		mapping[fmt.Sprint(key)] = value
+		if err = s.separator('}'); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// separator skip the comma, or stop before the closing bracket
+func (s *flowScanner) separator(closing byte) error {
+	s.skipSpaces()
+	if s.pos >= len(s.text) {
+		return fmt.Errorf("expect %c", closing)
+	}
+	switch s.text[s.pos] {
+	case ',':
+		s.pos++
+		return nil
+	case closing:
+		return nil
+	}
+	return fmt.Errorf("unexpected %s", s.text[s.pos:])
+}
+
+// quoted scan the quoted string, the double quoted string is unescaped as json, and the single quote is doubled in the single quoted string
+func (s *flowScanner) quoted() (interface{}, error) {
+	quote := s.text[s.pos]
+	var b strings.Builder
+	for i := s.pos + 1; i < len(s.text); i++ {
+		c := s.text[i]
+		switch {
+		case quote == '\'' && c == '\'':
+			if i+1 < len(s.text) && s.text[i+1] == '\'' {
+				b.WriteByte('\'')
+				i++
+				continue
+			}
+			s.pos = i + 1
+			return b.String(), nil
+		case quote == '"' && c == '\\':
+			i++
+		case quote == '"' && c == '"':
+			var value string
+			if err := json.Unmarshal([]byte(s.text[s.pos:i+1]), &value); err != nil {
+				return nil, fmt.Errorf("invalid string %s", s.text[s.pos:i+1])
+			}
+			s.pos = i + 1
+			return value, nil
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return nil, fmt.Errorf("unterminated string %s", s.text[s.pos:])
+}
+
+// plainScalar resolve the null, the bool and the number, the others are the strings
+func plainScalar(text string) interface{} {
+	switch text {
+	case "", "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	}
+	if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return n
+	}
+	if f, err := strconv.ParseFloat(text, 64); err == nil && strings.IndexAny(text, "0123456789") >= 0 && !strings.ContainsAny(text, "xXpP_") {
+		return f
+	}
+	return text
+}
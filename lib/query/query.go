@@ -0,0 +1,488 @@
+// Package query provides the in-memory query over the records: filter, project, group by, aggregate and pivot
+package query
+
+import (
+	"fmt"
+	aterror "github.com/apitable/apitable-sdks/apitable.go/lib/common/error"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/datasheet"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/export"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Aggregate the aggregate function of a measure
+type Aggregate string
+
+const (
+	Aggregate_Sum           Aggregate = "sum"
+	Aggregate_Avg           Aggregate = "avg"
+	Aggregate_Min           Aggregate = "min"
+	Aggregate_Max           Aggregate = "max"
+	Aggregate_Count         Aggregate = "count"
+	Aggregate_CountDistinct Aggregate = "countDistinct"
+)
+
+// Bucket the date bucket of the date time group
+type Bucket string
+
+const (
+	// such as: 2024-01-31
+	Bucket_Day Bucket = "day"
+	// the iso week, such as: 2024-W05
+	Bucket_Week Bucket = "week"
+	// such as: 2024-01
+	Bucket_Month Bucket = "month"
+	// such as: 2024-Q1
+	Bucket_Quarter Bucket = "quarter"
+	// such as: 2024
+	Bucket_Year Bucket = "year"
+)
+
+// GroupBy the field to group the records
+type GroupBy struct {
+	// the field name. required: yes.
+	Field string
+	// the date bucket of the date time field. required: no.
+	Bucket Bucket
+}
+
+// Measure the aggregated value of the group
+type Measure struct {
+	// the field name, not required for count
+	Field string
+	// required: yes.
+	Aggregate Aggregate
+	// the column name, the default is such as: sum(Amount)
+	As string
+}
+
+func (m *Measure) column() string {
+	if m.As != "" {
+		return m.As
+	}
+	if m.Field == "" {
+		return string(m.Aggregate)
+	}
+	return fmt.Sprintf("%s(%s)", m.Aggregate, m.Field)
+}
+
+// Engine query the records in memory
+type Engine struct {
+	records []*datasheet.Record
+	fields  map[string]*datasheet.DatasheetField
+	// the location of the date buckets, the default is UTC
+	Location *time.Location
+	// render the grouped and projected values
+	Renderer export.Renderer
+}
+
+// New init the query engine of the records, the fields are the schema of the datasheet
+func New(records []*datasheet.Record, fields []*datasheet.DatasheetField) *Engine {
+	e := &Engine{records: records, fields: map[string]*datasheet.DatasheetField{}, Location: time.UTC}
+	for _, field := range fields {
+		if field.Name != nil {
+			e.fields[*field.Name] = field
+		}
+	}
+	return e
+}
+
+// Records the records of the engine
+func (e *Engine) Records() []*datasheet.Record {
+	return e.records
+}
+
+// Filter return the engine of the records matching the predicate
+func (e *Engine) Filter(predicate func(record *datasheet.Record) bool) *Engine {
+	filtered := &Engine{fields: e.fields, Location: e.Location, Renderer: e.Renderer}
+	for _, record := range e.records {
+		if predicate(record) {
+			filtered.records = append(filtered.records, record)
+		}
+	}
+	return filtered
+}
+
+func (e *Engine) field(name string) (*datasheet.DatasheetField, error) {
+	field, ok := e.fields[name]
+	if !ok {
+		msg := fmt.Sprintf("The field %s is not found", name)
+		return nil, aterror.NewSDKError(404, msg, "ClientError.FieldNotFound")
+	}
+	return field, nil
+}
+
+// Value get the value of the record field
+func Value(record *datasheet.Record, field string) interface{} {
+	if record == nil || record.BaseRecord == nil || record.Fields == nil {
+		return nil
+	}
+	return (*record.Fields)[field]
+}
+
+// Select project the fields of the records to a table, the numbers are kept and the others are rendered as text
+func (e *Engine) Select(fields ...string) (*Table, error) {
+	table := &Table{Columns: fields, Precisions: make([]int, len(fields)), Percents: make([]bool, len(fields))}
+	selected := make([]*datasheet.DatasheetField, len(fields))
+	for i, name := range fields {
+		field, err := e.field(name)
+		if err != nil {
+			return nil, err
+		}
+		selected[i] = field
+		table.Precisions[i] = precision(field)
+		table.Percents[i] = isPercent(field)
+	}
+	for _, record := range e.records {
+		row := make([]interface{}, len(fields))
+		for i, field := range selected {
+			v := Value(record, fields[i])
+			if number, ok := v.(float64); ok && isNumberField(field) {
+				row[i] = number
+			} else if v != nil {
+				row[i] = e.Renderer.Render(field, v)
+			}
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	return table, nil
+}
+
+// group the aggregated records of a group key
+type group struct {
+	keys    []string
+	records []*datasheet.Record
+}
+
+// Aggregate group the records by the fields, and compute the measures of each group.
+//
+// * the groups are sorted by the keys, the numbers and the date buckets are in ascending order.
+// * the record is in the group of each value of a multiple value field, such as the multi select and members.
+// * the numbers follow the precision of the field, the count has no decimal.
+func (e *Engine) Aggregate(groupBy []*GroupBy, measures []*Measure) (*Table, error) {
+	groups, err := e.group(groupBy)
+	if err != nil {
+		return nil, err
+	}
+	table := &Table{}
+	for _, g := range groupBy {
+		table.Columns = append(table.Columns, g.Field)
+		table.Precisions = append(table.Precisions, -1)
+		table.Percents = append(table.Percents, false)
+	}
+	for _, m := range measures {
+		p, percent, err := e.measureFormat(m)
+		if err != nil {
+			return nil, err
+		}
+		table.Columns = append(table.Columns, m.column())
+		table.Precisions = append(table.Precisions, p)
+		table.Percents = append(table.Percents, percent)
+	}
+	for _, g := range groups {
+		row := []interface{}{}
+		for _, key := range g.keys {
+			row = append(row, key)
+		}
+		for _, m := range measures {
+			row = append(row, compute(m, g.records))
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	return table, nil
+}
+
+// Pivot group the records by the row and the column, the cells are the measure of the records in both.
+// the columns are the first column name and the sorted values of the column field.
+func (e *Engine) Pivot(row *GroupBy, column *GroupBy, measure *Measure) (*Table, error) {
+	groups, err := e.group([]*GroupBy{row, column})
+	if err != nil {
+		return nil, err
+	}
+	p, percent, err := e.measureFormat(measure)
+	if err != nil {
+		return nil, err
+	}
+	rowKeys, columnKeys := []string{}, []string{}
+	rowIndex, columnIndex := map[string]int{}, map[string]int{}
+	for _, g := range groups {
+		if _, ok := rowIndex[g.keys[0]]; !ok {
+			rowIndex[g.keys[0]] = len(rowKeys)
+			rowKeys = append(rowKeys, g.keys[0])
+		}
+		if _, ok := columnIndex[g.keys[1]]; !ok {
+			columnKeys = append(columnKeys, g.keys[1])
+			columnIndex[g.keys[1]] = 0
+		}
+	}
+	sort.SliceStable(columnKeys, func(i, j int) bool { return lessKey(columnKeys[i], columnKeys[j]) })
+	table := &Table{Columns: []string{row.Field}, Precisions: []int{-1}, Percents: []bool{false}}
+	for i, key := range columnKeys {
+		columnIndex[key] = i + 1
+		table.Columns = append(table.Columns, key)
+		table.Precisions = append(table.Precisions, p)
+		table.Percents = append(table.Percents, percent)
+	}
+	for _, key := range rowKeys {
+		cells := make([]interface{}, len(table.Columns))
+		cells[0] = key
+		table.Rows = append(table.Rows, cells)
+	}
+	for _, g := range groups {
+		table.Rows[rowIndex[g.keys[0]]][columnIndex[g.keys[1]]] = compute(measure, g.records)
+	}
+	return table, nil
+}
+
+// group split the records by the keys of the fields
+func (e *Engine) group(groupBy []*GroupBy) ([]*group, error) {
+	fields := make([]*datasheet.DatasheetField, len(groupBy))
+	for i, g := range groupBy {
+		if g == nil {
+			return nil, aterror.NewSDKError(400, "The group by field is required", "ClientError.InvalidArgument")
+		}
+		field, err := e.field(g.Field)
+		if err != nil {
+			return nil, err
+		}
+		fields[i] = field
+	}
+	groups := map[string]*group{}
+	for _, record := range e.records {
+		// the combinations of the keys of the multiple value fields
+		combinations := [][]string{{}}
+		for i, g := range groupBy {
+			keys := e.keys(fields[i], g.Bucket, Value(record, g.Field))
+			next := [][]string{}
+			for _, combination := range combinations {
+				for _, key := range keys {
+					next = append(next, append(append([]string{}, combination...), key))
+				}
+			}
+			combinations = next
+		}
+		for _, keys := range combinations {
+			id := strings.Join(keys, "\x00")
+			if _, ok := groups[id]; !ok {
+				groups[id] = &group{keys: keys}
+			}
+			groups[id].records = append(groups[id].records, record)
+		}
+	}
+	sorted := make([]*group, 0, len(groups))
+	for _, g := range groups {
+		sorted = append(sorted, g)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		for k := range sorted[i].keys {
+			if sorted[i].keys[k] != sorted[j].keys[k] {
+				return lessKey(sorted[i].keys[k], sorted[j].keys[k])
+			}
+		}
+		return false
+	})
+	return sorted, nil
+}
+
+// keys get the group keys of the value, the empty value is in the group of empty key
+func (e *Engine) keys(field *datasheet.DatasheetField, bucket Bucket, v interface{}) []string {
+	if bucket != "" {
+		if t, ok := timeOf(v); ok {
+			return []string{bucketKey(t.In(e.Location), bucket)}
+		}
+		return []string{""}
+	}
+	items, ok := v.([]interface{})
+	if !ok || len(items) == 0 {
+		return []string{e.Renderer.Render(field, v)}
+	}
+	keys := []string{}
+	seen := map[string]bool{}
+	for _, item := range items {
+		key := e.Renderer.Render(field, []interface{}{item})
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func timeOf(v interface{}) (time.Time, bool) {
+	switch v := v.(type) {
+	case float64:
+		return time.Unix(0, int64(v)*int64(time.Millisecond)), true
+	case string:
+		t, err := time.Parse(time.RFC3339, v)
+		return t, err == nil
+	}
+	return time.Time{}, false
+}
+
+func bucketKey(t time.Time, bucket Bucket) string {
+	switch bucket {
+	case Bucket_Day:
+		return t.Format("2006-01-02")
+	case Bucket_Week:
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case Bucket_Quarter:
+		return fmt.Sprintf("%d-Q%d", t.Year(), (int(t.Month())-1)/3+1)
+	case Bucket_Year:
+		return strconv.Itoa(t.Year())
+	}
+	return t.Format("2006-01")
+}
+
+// lessKey compare the keys as numbers if both are numbers, the empty key is the last
+func lessKey(a string, b string) bool {
+	if a == "" || b == "" {
+		return b == "" && a != ""
+	}
+	x, errX := strconv.ParseFloat(a, 64)
+	y, errY := strconv.ParseFloat(b, 64)
+	if errX == nil && errY == nil {
+		return x < y
+	}
+	return a < b
+}
+
+func isNumberField(field *datasheet.DatasheetField) bool {
+	if field.Type == nil {
+		return false
+	}
+	switch *field.Type {
+	case datasheet.FieldType_Number, datasheet.FieldType_Currency, datasheet.FieldType_Percent,
+		datasheet.FieldType_Rating, datasheet.FieldType_AutoNumber, datasheet.FieldType_Formula, datasheet.FieldType_MagicLookUp:
+		return true
+	}
+	return false
+}
+
+func isPercent(field *datasheet.DatasheetField) bool {
+	return field.Type != nil && *field.Type == datasheet.FieldType_Percent
+}
+
+// precision the decimal places of the number field, -1 if not fixed
+func precision(field *datasheet.DatasheetField) int {
+	if field.Type == nil || field.Property == nil {
+		return -1
+	}
+	var p *int
+	switch *field.Type {
+	case datasheet.FieldType_Number:
+		if property := field.NumberFieldProperty(); property != nil {
+			p = property.Precision
+		}
+	case datasheet.FieldType_Currency:
+		if property := field.CurrencyFieldProperty(); property != nil {
+			p = property.Precision
+		}
+	case datasheet.FieldType_Percent:
+		if property := field.PercentFieldProperty(); property != nil {
+			p = property.Precision
+		}
+	}
+	if p == nil {
+		return -1
+	}
+	return *p
+}
+
+// measureFormat the precision of the measure, and whether it's the percent of the percent field
+func (e *Engine) measureFormat(m *Measure) (int, bool, error) {
+	if m == nil {
+		return 0, false, aterror.NewSDKError(400, "The measure is required", "ClientError.InvalidArgument")
+	}
+	switch m.Aggregate {
+	case Aggregate_Count, Aggregate_CountDistinct:
+		if m.Field != "" {
+			if _, err := e.field(m.Field); err != nil {
+				return 0, false, err
+			}
+		}
+		return 0, false, nil
+	case Aggregate_Sum, Aggregate_Avg, Aggregate_Min, Aggregate_Max:
+		field, err := e.field(m.Field)
+		if err != nil {
+			return 0, false, err
+		}
+		return precision(field), isPercent(field), nil
+	}
+	msg := fmt.Sprintf("Unsupported aggregate: %s", m.Aggregate)
+	return 0, false, aterror.NewSDKError(400, msg, "ClientError.InvalidArgument")
+}
+
+func number(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case string:
+		n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+// compute the measure of the records, nil if there's no number to aggregate
+func compute(m *Measure, records []*datasheet.Record) interface{} {
+	switch m.Aggregate {
+	case Aggregate_Count:
+		count := 0
+		for _, record := range records {
+			if m.Field == "" || !isEmpty(Value(record, m.Field)) {
+				count++
+			}
+		}
+		return float64(count)
+	case Aggregate_CountDistinct:
+		seen := map[string]bool{}
+		for _, record := range records {
+			if v := Value(record, m.Field); !isEmpty(v) {
+				seen[fmt.Sprint(v)] = true
+			}
+		}
+		return float64(len(seen))
+	}
+	var result float64
+	count := 0
+	for _, record := range records {
+		n, ok := number(Value(record, m.Field))
+		if !ok {
+			continue
+		}
+		switch {
+		case count == 0:
+			result = n
+		case m.Aggregate == Aggregate_Min && n < result, m.Aggregate == Aggregate_Max && n > result:
+			result = n
+		case m.Aggregate == Aggregate_Sum || m.Aggregate == Aggregate_Avg:
+			result += n
+		}
+		count++
+	}
+	if count == 0 {
+		if m.Aggregate == Aggregate_Sum {
+			return float64(0)
+		}
+		return nil
+	}
+	if m.Aggregate == Aggregate_Avg {
+		return result / float64(count)
+	}
+	return result
+}
+
+func isEmpty(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package query
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Table the result of the query
+type Table struct {
+	Columns []string
+	// the cells are the rendered texts, the numbers or nil
+	Rows [][]interface{}
+	// the decimal places of the number columns, -1 if not fixed
+	Precisions []int
+	// the percent columns, the numbers are the fractions and the texts are multiplied by 100 with %
+	Percents []bool
+}
+
+// Text get the text of the cell, the number is formatted by the precision of the column, such as: 12.5% of the percent 0.125
+func (t *Table) Text(row int, column int) string {
+	switch v := t.Rows[row][column].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		p := -1
+		if column < len(t.Precisions) {
+			p = t.Precisions[column]
+		}
+		if column < len(t.Percents) && t.Percents[column] {
+			return strconv.FormatFloat(v*100, 'f', p, 64) + "%"
+		}
+		return strconv.FormatFloat(v, 'f', p, 64)
+	}
+	return ""
+}
+
+func (t *Table) textRow(row int) []string {
+	texts := make([]string, len(t.Columns))
+	for i := range texts {
+		texts[i] = t.Text(row, i)
+	}
+	return texts
+}
+
+// WriteCSV write the table as csv with the header
+func (t *Table) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(t.Columns); err != nil {
+		return err
+	}
+	for i := range t.Rows {
+		if err := writer.Write(t.textRow(i)); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteMarkdown write the table as a markdown table, the number columns are right aligned
+func (t *Table) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	writeMarkdownRow(&b, t.Columns)
+	aligns := make([]string, len(t.Columns))
+	for i := range aligns {
+		aligns[i] = "---"
+		if i < len(t.Precisions) && t.Precisions[i] >= 0 {
+			aligns[i] = "---:"
+		}
+	}
+	b.WriteString("| " + strings.Join(aligns, " | ") + " |\n")
+	for i := range t.Rows {
+		writeMarkdownRow(&b, t.textRow(i))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeMarkdownRow(b *strings.Builder, cells []string) {
+	escaped := make([]string, len(cells))
+	for i, cell := range cells {
+		cell = strings.Replace(cell, "|", `\|`, -1)
+		escaped[i] = strings.Replace(strings.Replace(cell, "\r\n", "<br>", -1), "\n", "<br>", -1)
+	}
+	b.WriteString("| " + strings.Join(escaped, " | ") + " |\n")
+}
@@ -0,0 +1,265 @@
+// Package search provides the local full-text index of the datasheet records with BM25 ranking
+package search
+
+import (
+	"github.com/apitable/apitable-sdks/apitable.go/lib/datasheet"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/export"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// the field types indexed by default, the display values of them are searchable texts
+var defaultFieldTypes = map[datasheet.FieldType]bool{
+	datasheet.FieldType_SingleText:     true,
+	datasheet.FieldType_Text:           true,
+	datasheet.FieldType_SingleSelect:   true,
+	datasheet.FieldType_MultiSelect:    true,
+	datasheet.FieldType_Member:         true,
+	datasheet.FieldType_CreatedBy:      true,
+	datasheet.FieldType_LastModifiedBy: true,
+	datasheet.FieldType_MagicLink:      true,
+	datasheet.FieldType_URL:            true,
+	datasheet.FieldType_Phone:          true,
+}
+
+// the default parameters of BM25
+const (
+	defaultK1 = 1.2
+	defaultB  = 0.75
+)
+
+// Options the options of the index
+type Options struct {
+	// the names of the indexed fields, the default is the text, select, member, link, url and phone fields
+	Fields []string
+	// the display values of the linked records by the record id, see ResolveLinks.
+	// the record ids are indexed if the linked record is not found.
+	LinkDisplay map[string]string
+	// the term frequency saturation of BM25, the default is 1.2
+	K1 float64
+	// the length normalization of BM25, the default is 0.75
+	B float64
+}
+
+// document the terms of a record
+type document struct {
+	// the term frequencies by the field name
+	Terms map[string]map[string]int
+	// the term count by the field name
+	Lengths map[string]int
+	Length  int
+}
+
+// Index the inverted index of the records, it's safe for concurrent use
+type Index struct {
+	mu       sync.RWMutex
+	fields   []*datasheet.DatasheetField
+	options  Options
+	renderer *export.Renderer
+	docs     map[string]*document
+	// the total term frequency of the records by the term
+	postings map[string]map[string]int
+	// the total term count of all records by the field name
+	fieldLengths map[string]int
+	totalLength  int
+	// the sorted terms for the prefix queries, built by the first prefix query after the terms changed
+	termsMu sync.Mutex
+	terms   []string
+}
+
+// NewIndex init the empty index of the datasheet fields, the fields not in the options are ignored
+func NewIndex(fields []*datasheet.DatasheetField, options *Options) *Index {
+	opts := Options{}
+	if options != nil {
+		opts = *options
+	}
+	if opts.K1 <= 0 {
+		opts.K1 = defaultK1
+	}
+	if opts.B <= 0 || opts.B > 1 {
+		opts.B = defaultB
+	}
+	selected := map[string]bool{}
+	for _, name := range opts.Fields {
+		selected[name] = true
+	}
+	x := &Index{
+		options:      opts,
+		renderer:     &export.Renderer{Separator: " "},
+		docs:         map[string]*document{},
+		postings:     map[string]map[string]int{},
+		fieldLengths: map[string]int{},
+	}
+	for _, field := range fields {
+		if field == nil || field.Name == nil || field.Type == nil {
+			continue
+		}
+		if (len(selected) == 0 && defaultFieldTypes[*field.Type]) || selected[*field.Name] {
+			x.fields = append(x.fields, field)
+		}
+	}
+	return x
+}
+
+// Fields the names of the indexed fields
+func (x *Index) Fields() []string {
+	names := make([]string, len(x.fields))
+	for i, field := range x.fields {
+		names[i] = *field.Name
+	}
+	return names
+}
+
+// Len the count of the indexed records
+func (x *Index) Len() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return len(x.docs)
+}
+
+// text the searchable text of the cell, the link values are replaced by the display values
+func (x *Index) text(field *datasheet.DatasheetField, value interface{}) string {
+	if *field.Type != datasheet.FieldType_MagicLink {
+		return x.renderer.Render(field, value)
+	}
+	ids, _ := value.([]interface{})
+	texts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		s, _ := id.(string)
+		if display, ok := x.options.LinkDisplay[s]; ok {
+			s = display
+		}
+		texts = append(texts, s)
+	}
+	return strings.Join(texts, " ")
+}
+
+// Add index the records, the indexed records of the same ids are replaced
+func (x *Index) Add(records ...*datasheet.Record) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	for _, record := range records {
+		if record == nil || record.BaseRecord == nil || record.RecordId == nil {
+			continue
+		}
+		doc := &document{Terms: map[string]map[string]int{}, Lengths: map[string]int{}}
+		if record.Fields != nil {
+			for _, field := range x.fields {
+				value, ok := (*record.Fields)[*field.Name]
+				if !ok {
+					continue
+				}
+				terms := Tokenize(x.text(field, value))
+				if len(terms) == 0 {
+					continue
+				}
+				frequencies := map[string]int{}
+				for _, term := range terms {
+					frequencies[term]++
+				}
+				doc.Terms[*field.Name] = frequencies
+				doc.Lengths[*field.Name] = len(terms)
+				doc.Length += len(terms)
+			}
+		}
+		x.remove(*record.RecordId)
+		x.insert(*record.RecordId, doc)
+	}
+}
+
+// Remove remove the records from the index
+func (x *Index) Remove(recordIds ...string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	for _, id := range recordIds {
+		x.remove(id)
+	}
+}
+
+// Apply update the index by the record events of Datasheet.Watch, the deleted records are removed
+func (x *Index) Apply(events ...*datasheet.RecordEvent) {
+	for _, event := range events {
+		if event == nil {
+			continue
+		}
+		if event.Type == datasheet.RecordEvent_Deleted {
+			x.Remove(event.RecordId)
+		} else if event.Record != nil {
+			x.Add(event.Record)
+		}
+	}
+}
+
+func (x *Index) insert(id string, doc *document) {
+	x.docs[id] = doc
+	for field, frequencies := range doc.Terms {
+		x.fieldLengths[field] += doc.Lengths[field]
+		for term, frequency := range frequencies {
+			posting, ok := x.postings[term]
+			if !ok {
+				posting = map[string]int{}
+				x.postings[term] = posting
+				x.terms = nil
+			}
+			posting[id] += frequency
+		}
+	}
+	x.totalLength += doc.Length
+}
+
+func (x *Index) remove(id string) {
+	doc, ok := x.docs[id]
+	if !ok {
+		return
+	}
+	delete(x.docs, id)
+	for field, frequencies := range doc.Terms {
+		x.fieldLengths[field] -= doc.Lengths[field]
+		for term := range frequencies {
+			posting := x.postings[term]
+			delete(posting, id)
+			if len(posting) == 0 {
+				delete(x.postings, term)
+				x.terms = nil
+			}
+		}
+	}
+	x.totalLength -= doc.Length
+}
+
+// sortedTerms the sorted terms of the index, the caller must hold the read lock
+func (x *Index) sortedTerms() []string {
+	x.termsMu.Lock()
+	defer x.termsMu.Unlock()
+	if x.terms == nil {
+		x.terms = make([]string, 0, len(x.postings))
+		for term := range x.postings {
+			x.terms = append(x.terms, term)
+		}
+		sort.Strings(x.terms)
+	}
+	return x.terms
+}
+
+// expand the terms starting with the prefix
+func (x *Index) expand(prefix string) []string {
+	terms := x.sortedTerms()
+	matched := []string{}
+	for i := sort.SearchStrings(terms, prefix); i < len(terms) && strings.HasPrefix(terms[i], prefix); i++ {
+		matched = append(matched, terms[i])
+	}
+	return matched
+}
+
+// expandCJK the terms containing the CJK character, such as: 析 => 分析, 析表.
+// the terms are scanned, because the character is not the prefix of the bigrams ending with it.
+func (x *Index) expandCJK(char string) []string {
+	matched := []string{}
+	for _, term := range x.sortedTerms() {
+		if strings.Contains(term, char) {
+			matched = append(matched, term)
+		}
+	}
+	return matched
+}
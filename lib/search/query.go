@@ -0,0 +1,207 @@
+package search
+
+import (
+	"fmt"
+	aterror "github.com/apitable/apitable-sdks/apitable.go/lib/common/error"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Hit the record matching the query
+type Hit struct {
+	RecordId string  `json:"recordId"`
+	Score    float64 `json:"score"`
+	// the names of the fields containing the query terms, in the order of the indexed fields
+	Fields []string `json:"fields"`
+}
+
+// termQuery the term must be contained by the matched records
+type termQuery struct {
+	// the field name, empty for all fields
+	field  string
+	term   string
+	prefix bool
+	// the single CJK character matches the bigrams containing it
+	cjk bool
+}
+
+// parseQuery parse the query into the term queries, such as: `Name:"acme corp" status:open app*`
+//
+// * the field name is matched case insensitively, the unknown field name is searched as the text.
+// * the quoted field name is required to be an indexed field, such as: "Company Name":acme
+// * the term ending with * is the prefix query.
+// * the single CJK character matches the bigrams containing it, such as: 析 matches 数据分析.
+func (x *Index) parseQuery(query string) ([]*termQuery, error) {
+	fields := map[string]string{}
+	for _, name := range x.Fields() {
+		fields[strings.ToLower(name)] = name
+	}
+	queries := []*termQuery{}
+	runes := []rune(query)
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+		field := ""
+		value, next, quoted := readClause(runes, i)
+		if next < len(runes) && runes[next] == ':' {
+			name, ok := fields[strings.ToLower(value)]
+			if !ok && quoted {
+				msg := fmt.Sprintf("The field %s is not indexed", value)
+				return nil, aterror.NewSDKError(404, msg, "ClientError.FieldNotFound")
+			}
+			if ok {
+				field = name
+				value, next, _ = readClause(runes, next+1)
+			}
+		}
+		i = next
+		prefix := strings.HasSuffix(value, "*")
+		terms := Tokenize(strings.TrimRight(value, "*"))
+		for j, term := range terms {
+			last := j == len(terms)-1
+			single := utf8.RuneCountInString(term) == 1 && isCJK([]rune(term)[0])
+			queries = append(queries, &termQuery{field: field, term: term, prefix: prefix && last && !single, cjk: single})
+		}
+	}
+	return queries, nil
+}
+
+// readClause read the quoted or the bare text, the bare text ends with the space or the colon
+func readClause(runes []rune, start int) (value string, next int, quoted bool) {
+	if start < len(runes) && runes[start] == '"' {
+		end := start + 1
+		for end < len(runes) && runes[end] != '"' {
+			end++
+		}
+		value = string(runes[start+1 : end])
+		if end < len(runes) {
+			end++
+		}
+		// the prefix mark after the quote, such as: "acme co"*
+		if end < len(runes) && runes[end] == '*' {
+			value += "*"
+			end++
+		}
+		return value, end, true
+	}
+	end := start
+	for end < len(runes) && !unicode.IsSpace(runes[end]) && runes[end] != ':' {
+		end++
+	}
+	if end == start && end < len(runes) {
+		// the colon without the field name
+		end++
+	}
+	return string(runes[start:end]), end, false
+}
+
+// Search find the records containing all terms of the query, ranked by BM25.
+//
+// * the query terms are tokenized the same as the indexed texts, see Tokenize.
+// * field:term searches the field only, and term* matches the terms starting with term.
+// * limit is the max count of the hits, all hits are returned if it's not positive.
+func (x *Index) Search(query string, limit int) ([]*Hit, error) {
+	queries, err := x.parseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	if len(queries) == 0 || len(x.docs) == 0 {
+		return []*Hit{}, nil
+	}
+	var scores map[string]float64
+	matchedFields := map[string]map[string]bool{}
+	for _, q := range queries {
+		termScores := x.score(q, matchedFields)
+		if scores == nil {
+			scores = termScores
+			continue
+		}
+		for id, score := range scores {
+			if termScore, ok := termScores[id]; ok {
+				scores[id] = score + termScore
+			} else {
+				delete(scores, id)
+			}
+		}
+		if len(scores) == 0 {
+			break
+		}
+	}
+	hits := make([]*Hit, 0, len(scores))
+	for id, score := range scores {
+		hit := &Hit{RecordId: id, Score: score, Fields: []string{}}
+		for _, field := range x.fields {
+			if matchedFields[id][*field.Name] {
+				hit.Fields = append(hit.Fields, *field.Name)
+			}
+		}
+		hits = append(hits, hit)
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].RecordId < hits[j].RecordId
+	})
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
+}
+
+// score the BM25 scores of the records matching the term query, and collect the matched fields of the records
+func (x *Index) score(q *termQuery, matchedFields map[string]map[string]bool) map[string]float64 {
+	terms := []string{q.term}
+	if q.prefix {
+		terms = x.expand(q.term)
+	} else if q.cjk {
+		terms = x.expandCJK(q.term)
+	}
+	n := float64(len(x.docs))
+	averageLength := float64(x.totalLength) / n
+	if q.field != "" {
+		averageLength = float64(x.fieldLengths[q.field]) / n
+	}
+	k1, b := x.options.K1, x.options.B
+	scores := map[string]float64{}
+	for _, term := range terms {
+		posting := x.postings[term]
+		frequencies := make(map[string]int, len(posting))
+		for id, frequency := range posting {
+			doc := x.docs[id]
+			if q.field != "" {
+				frequency = doc.Terms[q.field][term]
+			}
+			if frequency > 0 {
+				frequencies[id] = frequency
+			}
+		}
+		df := float64(len(frequencies))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for id, frequency := range frequencies {
+			doc := x.docs[id]
+			length := float64(doc.Length)
+			if q.field != "" {
+				length = float64(doc.Lengths[q.field])
+			}
+			tf := float64(frequency)
+			scores[id] += idf * tf * (k1 + 1) / (tf + k1*(1-b+b*length/averageLength))
+			if matchedFields[id] == nil {
+				matchedFields[id] = map[string]bool{}
+			}
+			for field, fieldTerms := range doc.Terms {
+				if fieldTerms[term] > 0 && (q.field == "" || q.field == field) {
+					matchedFields[id][field] = true
+				}
+			}
+		}
+	}
+	return scores
+}
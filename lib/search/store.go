@@ -0,0 +1,133 @@
+package search
+
+import (
+	"compress/gzip"
+	"encoding/gob"
+	"fmt"
+	aterror "github.com/apitable/apitable-sdks/apitable.go/lib/common/error"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/datasheet"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/export"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// the version of the index file, the file of another version is rebuilt
+const indexVersion = 1
+
+// indexFile the content of the index file, the postings are rebuilt from the documents when loaded
+type indexFile struct {
+	Version int
+	Fields  []string
+	Docs    map[string]*document
+}
+
+// Save write the index to the file with gzip compressed gob, the file is replaced atomically
+func (x *Index) Save(filePath string) error {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return aterror.NewSDKError(500, err.Error(), "ClientError.FileWriteError")
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(filePath), filepath.Base(filePath)+".*.tmp")
+	if err != nil {
+		return aterror.NewSDKError(500, err.Error(), "ClientError.FileWriteError")
+	}
+	defer os.Remove(tmp.Name())
+	w := gzip.NewWriter(tmp)
+	err = gob.NewEncoder(w).Encode(&indexFile{Version: indexVersion, Fields: x.Fields(), Docs: x.docs})
+	if err == nil {
+		err = w.Close()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), filePath)
+	}
+	if err != nil {
+		msg := fmt.Sprintf("Fail to save the index %s because %s", filePath, err)
+		return aterror.NewSDKError(500, msg, "ClientError.FileWriteError")
+	}
+	return nil
+}
+
+// Load init the index and load the records saved by Save, the index is empty if the file doesn't exist.
+//
+// * the index is empty too if the indexed fields or the version of the file are different, so the records should be added again.
+func Load(filePath string, fields []*datasheet.DatasheetField, options *Options) (*Index, error) {
+	x := NewIndex(fields, options)
+	f, err := os.Open(filePath)
+	if os.IsNotExist(err) {
+		return x, nil
+	}
+	if err != nil {
+		return nil, aterror.NewSDKError(500, err.Error(), "ClientError.FileReadError")
+	}
+	defer f.Close()
+	saved := &indexFile{}
+	r, err := gzip.NewReader(f)
+	if err == nil {
+		err = gob.NewDecoder(r).Decode(saved)
+	}
+	if err != nil {
+		msg := fmt.Sprintf("Invalid index file %s because %s", filePath, err)
+		return nil, aterror.NewSDKError(400, msg, "ClientError.DecodeError")
+	}
+	if saved.Version != indexVersion || strings.Join(saved.Fields, "\x00") != strings.Join(x.Fields(), "\x00") {
+		return x, nil
+	}
+	for id, doc := range saved.Docs {
+		x.insert(id, doc)
+	}
+	return x, nil
+}
+
+// ResolveLinks get the display values of the records linked by the link fields, to index the link fields by Options.LinkDisplay.
+//
+// * the display value is the primary field value of the linked record.
+// * all link fields of the datasheet are resolved if linkFields is empty.
+func ResolveLinks(dst *datasheet.Datasheet, records []*datasheet.Record, linkFields ...string) (map[string]string, error) {
+	fields, err := dst.DescribeFields(nil)
+	if err != nil {
+		return nil, err
+	}
+	selected := map[string]bool{}
+	for _, name := range linkFields {
+		selected[name] = true
+	}
+	renderer := &export.Renderer{Separator: " "}
+	display := map[string]string{}
+	for _, field := range fields {
+		if field.Type == nil || *field.Type != datasheet.FieldType_MagicLink || field.Name == nil || (len(selected) > 0 && !selected[*field.Name]) {
+			continue
+		}
+		property := field.MagicLinkFieldProperty()
+		if property == nil || property.ForeignDatasheetId == nil {
+			continue
+		}
+		foreign := &datasheet.Datasheet{Client: dst.Client, DatasheetId: *property.ForeignDatasheetId, SpaceId: dst.SpaceId}
+		foreignFields, err := foreign.DescribeFields(nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(foreignFields) == 0 || foreignFields[0].Name == nil {
+			continue
+		}
+		primary := foreignFields[0]
+		expanded, err := dst.ExpandLinks(records, *field.Name, 1)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range expanded {
+			for _, linked := range record.Links[*field.Name] {
+				if linked.BaseRecord == nil || linked.RecordId == nil || linked.Fields == nil {
+					continue
+				}
+				display[*linked.RecordId] = renderer.Render(primary, (*linked.Fields)[*primary.Name])
+			}
+		}
+	}
+	return display, nil
+}
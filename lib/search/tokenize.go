@@ -0,0 +1,52 @@
+package search
+
+import (
+	"github.com/apitable/apitable-sdks/apitable.go/lib/common/text"
+	"unicode"
+)
+
+// isCJK the han, kana and hangul characters, they are indexed as bigrams because the words are not separated by spaces
+func isCJK(r rune) bool {
+	return r == 'ー' || unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r)
+}
+
+// Tokenize split the text into the terms of the index.
+//
+// * the text is normalized by text.Normalize, so the terms are lower case and half width.
+// * the words are the runs of letters and digits, such as: "Hello, World 2024" => hello, world, 2024.
+// * the CJK runs are split into overlapping bigrams, such as: 数据表 => 数据, 据表. the run of one character is kept as it is.
+func Tokenize(s string) []string {
+	terms := []string{}
+	var word, cjk []rune
+	flushWord := func() {
+		if len(word) > 0 {
+			terms = append(terms, string(word))
+			word = word[:0]
+		}
+	}
+	flushCJK := func() {
+		if len(cjk) == 1 {
+			terms = append(terms, string(cjk))
+		}
+		for i := 0; i+1 < len(cjk); i++ {
+			terms = append(terms, string(cjk[i:i+2]))
+		}
+		cjk = cjk[:0]
+	}
+	for _, r := range text.Normalize(s) {
+		switch {
+		case isCJK(r):
+			flushWord()
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r):
+			flushCJK()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+	flushWord()
+	flushCJK()
+	return terms
+}
@@ -0,0 +1,412 @@
+package space
+
+import (
+	"errors"
+	"fmt"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/common"
+	aterror "github.com/apitable/apitable-sdks/apitable.go/lib/common/error"
+	athttp "github.com/apitable/apitable-sdks/apitable.go/lib/common/http"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/datasheet"
+	"strings"
+	"sync"
+	"time"
+)
+
+const memberDetailPath = "/fusion/v1/spaces/%s/members/%s"
+const teamDetailPath = "/fusion/v1/spaces/%s/teams/%s"
+const teamChildrenPath = "/fusion/v1/spaces/%s/teams/%s/children"
+const teamMembersPath = "/fusion/v1/spaces/%s/teams/%s/members"
+const roleListPath = "/fusion/v1/spaces/%s/roles"
+const roleUnitsPath = "/fusion/v1/spaces/%s/roles/%s/units"
+
+// DefaultMemberCacheTTL the default time to live of the members to search
+const DefaultMemberCacheTTL = 5 * time.Minute
+
+// memberCache the members of the space, loaded once by walking the team hierarchy
+type memberCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	members []*Member
+	expires time.Time
+}
+
+func (c *Space) memberCache() *memberCache {
+	if c.members == nil {
+		c.members = &memberCache{ttl: DefaultMemberCacheTTL}
+	}
+	return c.members
+}
+
+// WithMemberCacheTTL set the time to live of the members to search, 0 disables the cache.
+func (c *Space) WithMemberCacheTTL(ttl time.Duration) *Space {
+	cache := c.memberCache()
+	cache.mu.Lock()
+	cache.ttl = ttl
+	cache.members = nil
+	cache.mu.Unlock()
+	return c
+}
+
+// ClearMemberCache remove the members from the cache
+func (c *Space) ClearMemberCache() {
+	cache := c.memberCache()
+	cache.mu.Lock()
+	cache.members = nil
+	cache.mu.Unlock()
+}
+
+// cachedMembers get all members with the sensitive data, the members are loaded once in the time to live.
+func (c *Space) cachedMembers() ([]*Member, error) {
+	cache := c.memberCache()
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if cache.members != nil && time.Now().Before(cache.expires) {
+		return cache.members, nil
+	}
+	members, err := c.DescribeAllMembers(true)
+	if err != nil {
+		return nil, err
+	}
+	cache.members = nil
+	if cache.ttl > 0 {
+		cache.members = members
+		cache.expires = time.Now().Add(cache.ttl)
+	}
+	return members, nil
+}
+
+// SkipTeam used as a return value from WalkTeamsFunc to skip the sub teams of the team
+var SkipTeam = errors.New("skip this team")
+
+// WalkTeamsFunc the function called for each team, the depth of the top teams is 0.
+//
+// * return SkipTeam to skip the sub teams of the team.
+// * return other error to stop walking.
+type WalkTeamsFunc func(team *Team, depth int) error
+
+func NewDescribeMemberRequest() (request *DescribeMemberRequest) {
+	request = &DescribeMemberRequest{
+		BaseRequest: &athttp.BaseRequest{},
+	}
+	return
+}
+
+func NewDescribeTeamRequest() (request *DescribeTeamRequest) {
+	request = &DescribeTeamRequest{
+		BaseRequest: &athttp.BaseRequest{},
+	}
+	return
+}
+
+func NewDescribeTeamChildrenRequest() (request *DescribeTeamChildrenRequest) {
+	request = &DescribeTeamChildrenRequest{
+		BaseRequest: &athttp.BaseRequest{},
+	}
+	return
+}
+
+func NewDescribeTeamMembersRequest() (request *DescribeTeamMembersRequest) {
+	request = &DescribeTeamMembersRequest{
+		BaseRequest: &athttp.BaseRequest{},
+	}
+	return
+}
+
+func NewDescribeRolesRequest() (request *DescribeRolesRequest) {
+	request = &DescribeRolesRequest{
+		BaseRequest: &athttp.BaseRequest{},
+	}
+	return
+}
+
+func NewDescribeRoleUnitsRequest() (request *DescribeRoleUnitsRequest) {
+	request = &DescribeRoleUnitsRequest{
+		BaseRequest: &athttp.BaseRequest{},
+	}
+	return
+}
+
+func unitIdRequired() error {
+	return aterror.NewSDKError(400, "The unit id is required", "ClientError.InvalidArgument")
+}
+
+// DescribeMember get member detail
+func (c *Space) DescribeMember(request *DescribeMemberRequest) (member *Member, err error) {
+	if request == nil || request.UnitId == nil {
+		return nil, unitIdRequired()
+	}
+	request.Init().SetPath(fmt.Sprintf(memberDetailPath, c.SpaceId, *request.UnitId))
+	request.SetHttpMethod(athttp.GET)
+	response := &DescribeMemberResponse{BaseResponse: &athttp.BaseResponse{}}
+	err = c.Send(request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response.Data.Member, nil
+}
+
+// DescribeTeam get team detail
+func (c *Space) DescribeTeam(request *DescribeTeamRequest) (team *Team, err error) {
+	if request == nil || request.UnitId == nil {
+		return nil, unitIdRequired()
+	}
+	request.Init().SetPath(fmt.Sprintf(teamDetailPath, c.SpaceId, *request.UnitId))
+	request.SetHttpMethod(athttp.GET)
+	response := &DescribeTeamResponse{BaseResponse: &athttp.BaseResponse{}}
+	err = c.Send(request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response.Data.Team, nil
+}
+
+// DescribeTeamChildren get paging sub teams, the unit id of the root team is RootTeamId.
+func (c *Space) DescribeTeamChildren(request *DescribeTeamChildrenRequest) (pagination *TeamPagination, err error) {
+	if request == nil || request.UnitId == nil {
+		return nil, unitIdRequired()
+	}
+	request.Init().SetPath(fmt.Sprintf(teamChildrenPath, c.SpaceId, *request.UnitId))
+	request.SetHttpMethod(athttp.GET)
+	response := &DescribeTeamChildrenResponse{BaseResponse: &athttp.BaseResponse{}}
+	err = c.Send(request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response.Data, nil
+}
+
+// DescribeTeamMembers get paging members of the team
+func (c *Space) DescribeTeamMembers(request *DescribeTeamMembersRequest) (pagination *MemberPagination, err error) {
+	if request == nil || request.UnitId == nil {
+		return nil, unitIdRequired()
+	}
+	request.Init().SetPath(fmt.Sprintf(teamMembersPath, c.SpaceId, *request.UnitId))
+	request.SetHttpMethod(athttp.GET)
+	response := &DescribeTeamMembersResponse{BaseResponse: &athttp.BaseResponse{}}
+	err = c.Send(request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response.Data, nil
+}
+
+// DescribeRoles get paging roles of the space
+func (c *Space) DescribeRoles(request *DescribeRolesRequest) (pagination *RolePagination, err error) {
+	if request == nil {
+		request = NewDescribeRolesRequest()
+	}
+	request.Init().SetPath(fmt.Sprintf(roleListPath, c.SpaceId))
+	request.SetHttpMethod(athttp.GET)
+	response := &DescribeRolesResponse{BaseResponse: &athttp.BaseResponse{}}
+	err = c.Send(request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response.Data, nil
+}
+
+// DescribeRoleUnits get the teams and members of the role
+func (c *Space) DescribeRoleUnits(request *DescribeRoleUnitsRequest) (units *RoleUnits, err error) {
+	if request == nil || request.UnitId == nil {
+		return nil, unitIdRequired()
+	}
+	request.Init().SetPath(fmt.Sprintf(roleUnitsPath, c.SpaceId, *request.UnitId))
+	request.SetHttpMethod(athttp.GET)
+	response := &DescribeRoleUnitsResponse{BaseResponse: &athttp.BaseResponse{}}
+	err = c.Send(request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response.Data, nil
+}
+
+// hasNextPage whether there are more pages after the current page
+func hasNextPage(pagination UnitPagination, count int) bool {
+	if pagination.Total == nil || pagination.PageNum == nil || pagination.PageSize == nil || count == 0 {
+		return false
+	}
+	return *pagination.PageNum**pagination.PageSize < *pagination.Total
+}
+
+// DescribeAllTeamChildren get all sub teams of the team
+func (c *Space) DescribeAllTeamChildren(unitId string) (teams []*Team, err error) {
+	for page := int64(1); ; page++ {
+		request := NewDescribeTeamChildrenRequest()
+		request.UnitId = common.StringPtr(unitId)
+		request.PageNum = common.Int64Ptr(page)
+		request.PageSize = common.Int64Ptr(int64(common.DefaultPageSize))
+		pagination, err := c.DescribeTeamChildren(request)
+		if err != nil {
+			return nil, err
+		}
+		teams = append(teams, pagination.Teams...)
+		if !hasNextPage(pagination.UnitPagination, len(pagination.Teams)) {
+			return teams, nil
+		}
+	}
+}
+
+// DescribeAllTeamMembers get all members of the team
+func (c *Space) DescribeAllTeamMembers(unitId string, sensitiveData bool) (members []*Member, err error) {
+	for page := int64(1); ; page++ {
+		request := NewDescribeTeamMembersRequest()
+		request.UnitId = common.StringPtr(unitId)
+		request.SensitiveData = &sensitiveData
+		request.PageNum = common.Int64Ptr(page)
+		request.PageSize = common.Int64Ptr(int64(common.DefaultPageSize))
+		pagination, err := c.DescribeTeamMembers(request)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, pagination.Members...)
+		if !hasNextPage(pagination.UnitPagination, len(pagination.Members)) {
+			return members, nil
+		}
+	}
+}
+
+// DescribeAllRoles get all roles of the space
+func (c *Space) DescribeAllRoles() (roles []*Role, err error) {
+	for page := int64(1); ; page++ {
+		request := NewDescribeRolesRequest()
+		request.PageNum = common.Int64Ptr(page)
+		request.PageSize = common.Int64Ptr(int64(common.DefaultPageSize))
+		pagination, err := c.DescribeRoles(request)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, pagination.Roles...)
+		if !hasNextPage(pagination.UnitPagination, len(pagination.Roles)) {
+			return roles, nil
+		}
+	}
+}
+
+// WalkTeams walk the team hierarchy from the root team, the parent team is visited before its sub teams.
+func (c *Space) WalkTeams(fn WalkTeamsFunc) error {
+	return c.walkTeams(RootTeamId, 0, fn)
+}
+
+func (c *Space) walkTeams(unitId string, depth int, fn WalkTeamsFunc) error {
+	teams, err := c.DescribeAllTeamChildren(unitId)
+	if err != nil {
+		return err
+	}
+	for _, team := range teams {
+		err = fn(team, depth)
+		if err == SkipTeam {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err = c.walkTeams(*team.UnitId, depth+1, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DescribeAllMembers get all members of the space by walking the team hierarchy, the members are deduplicated by unit id.
+func (c *Space) DescribeAllMembers(sensitiveData bool) (members []*Member, err error) {
+	seen := map[string]bool{}
+	collect := func(unitId string) error {
+		teamMembers, err := c.DescribeAllTeamMembers(unitId, sensitiveData)
+		if err != nil {
+			return err
+		}
+		for _, member := range teamMembers {
+			if member.UnitId == nil || seen[*member.UnitId] {
+				continue
+			}
+			seen[*member.UnitId] = true
+			members = append(members, member)
+		}
+		return nil
+	}
+	if err = collect(RootTeamId); err != nil {
+		return nil, err
+	}
+	err = c.WalkTeams(func(team *Team, depth int) error {
+		return collect(*team.UnitId)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// SearchMembers search members whose name or email contains the keyword, case insensitive.
+//
+// * the members are cached, see WithMemberCacheTTL.
+func (c *Space) SearchMembers(keyword string) (members []*Member, err error) {
+	all, err := c.cachedMembers()
+	if err != nil {
+		return nil, err
+	}
+	keyword = strings.ToLower(strings.TrimSpace(keyword))
+	members = []*Member{}
+	for _, member := range all {
+		if member.Name != nil && strings.Contains(strings.ToLower(*member.Name), keyword) ||
+			member.Email != nil && strings.Contains(strings.ToLower(*member.Email), keyword) {
+			members = append(members, member)
+		}
+	}
+	return members, nil
+}
+
+// FindMemberByEmail find the member by the email, case insensitive, the members are cached as SearchMembers.
+func (c *Space) FindMemberByEmail(email string) (member *Member, err error) {
+	return c.findMember("email", email, func(m *Member) *string { return m.Email })
+}
+
+// FindMemberByName find the member by the name, return an error with code 409 if the name is duplicate.
+func (c *Space) FindMemberByName(name string) (member *Member, err error) {
+	return c.findMember("name", name, func(m *Member) *string { return m.Name })
+}
+
+func (c *Space) findMember(key string, value string, get func(m *Member) *string) (member *Member, err error) {
+	all, err := c.cachedMembers()
+	if err != nil {
+		return nil, err
+	}
+	var matched []*Member
+	for _, m := range all {
+		if v := get(m); v != nil && strings.EqualFold(strings.TrimSpace(*v), strings.TrimSpace(value)) {
+			matched = append(matched, m)
+		}
+	}
+	if len(matched) == 0 {
+		msg := fmt.Sprintf("Member not found by %s: %s", key, value)
+		return nil, aterror.NewSDKError(404, msg, "ClientError.MemberNotFound")
+	}
+	if len(matched) > 1 {
+		msg := fmt.Sprintf("Ambiguous member %s: %s, matched %d members", key, value, len(matched))
+		return nil, aterror.NewSDKError(409, msg, "ClientError.AmbiguousMember")
+	}
+	return matched[0], nil
+}
+
+// UnitFieldValue convert the member to the value of the member field
+func (m *Member) UnitFieldValue() *datasheet.UnitFieldValue {
+	value := &datasheet.UnitFieldValue{UnitType: string(datasheet.MemberType_Member)}
+	if m.UnitId != nil {
+		value.UnitId = *m.UnitId
+	}
+	if m.Name != nil {
+		value.UnitName = *m.Name
+	}
+	return value
+}
+
+// UnitFieldValue convert the team to the value of the member field
+func (t *Team) UnitFieldValue() *datasheet.UnitFieldValue {
+	value := &datasheet.UnitFieldValue{UnitType: string(datasheet.MemberType_Team)}
+	if t.UnitId != nil {
+		value.UnitId = *t.UnitId
+	}
+	if t.Name != nil {
+		value.UnitName = *t.Name
+	}
+	return value
+}
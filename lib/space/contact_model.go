@@ -0,0 +1,211 @@
+package space
+
+import (
+	athttp "github.com/apitable/apitable-sdks/apitable.go/lib/common/http"
+)
+
+// UnitStatus the status of the member
+type UnitStatus int
+
+const (
+	UnitStatus_Inactive UnitStatus = 0
+	UnitStatus_Active   UnitStatus = 1
+	UnitStatus_Invited  UnitStatus = 2
+)
+
+// MemberType the type of the member
+type MemberType string
+
+const (
+	MemberType_Primary MemberType = "Primary"
+	MemberType_Admin   MemberType = "SubAdmin"
+	MemberType_Member  MemberType = "Member"
+)
+
+// RootTeamId the unit id of the root team
+const RootTeamId = "0"
+
+// UnitBaseInfo the base info of the unit, such as: team or role
+type UnitBaseInfo struct {
+	// UnitId unit id
+	UnitId *string `json:"unitId,omitempty" name:"unitId"`
+	// Name unit name
+	Name *string `json:"name,omitempty" name:"name"`
+	// Sequence the order in the parent unit
+	Sequence *int64 `json:"sequence,omitempty" name:"sequence"`
+}
+
+// Member describe the member of the space
+type Member struct {
+	// UnitId member unit id
+	UnitId *string `json:"unitId,omitempty" name:"unitId"`
+	// Name member name
+	Name *string `json:"name,omitempty" name:"name"`
+	// Email member email
+	Email *string `json:"email,omitempty" name:"email"`
+	// Mobile member mobile phone number
+	Mobile *string `json:"mobile,omitempty" name:"mobile"`
+	// Avatar member avatar url
+	Avatar *string `json:"avatar,omitempty" name:"avatar"`
+	// Status member status
+	Status *UnitStatus `json:"status,omitempty" name:"status"`
+	// Type member type
+	Type *MemberType `json:"type,omitempty" name:"type"`
+	// Teams the teams of the member
+	Teams []*UnitBaseInfo `json:"teams,omitempty" name:"teams"`
+	// Roles the roles of the member
+	Roles []*UnitBaseInfo `json:"roles,omitempty" name:"roles"`
+}
+
+// Team describe the team of the space
+type Team struct {
+	UnitBaseInfo
+	// ParentUnitId the parent team unit id
+	ParentUnitId *string `json:"parentUnitId,omitempty" name:"parentUnitId"`
+	// Roles the roles of the team
+	Roles []*UnitBaseInfo `json:"roles,omitempty" name:"roles"`
+}
+
+// Role describe the role of the space
+type Role struct {
+	UnitBaseInfo
+}
+
+// DescribeMemberRequest member detail request
+type DescribeMemberRequest struct {
+	*athttp.BaseRequest
+	UnitId *string
+	// return the email and mobile of the member. required: no.
+	SensitiveData *bool `json:"sensitiveData,omitempty" name:"sensitiveData"`
+}
+
+// DescribeTeamRequest team detail request
+type DescribeTeamRequest struct {
+	*athttp.BaseRequest
+	UnitId *string
+}
+
+// DescribeTeamChildrenRequest sub teams request
+type DescribeTeamChildrenRequest struct {
+	*athttp.BaseRequest
+	UnitId *string
+	// the page number, the default is 1. required: no.
+	PageNum *int64 `json:"pageNum,omitempty" name:"pageNum"`
+	// the page size, the default is 100. required: no.
+	PageSize *int64 `json:"pageSize,omitempty" name:"pageSize"`
+}
+
+// DescribeTeamMembersRequest team members request
+type DescribeTeamMembersRequest struct {
+	*athttp.BaseRequest
+	UnitId *string
+	// return the email and mobile of the members. required: no.
+	SensitiveData *bool `json:"sensitiveData,omitempty" name:"sensitiveData"`
+	// the page number, the default is 1. required: no.
+	PageNum *int64 `json:"pageNum,omitempty" name:"pageNum"`
+	// the page size, the default is 100. required: no.
+	PageSize *int64 `json:"pageSize,omitempty" name:"pageSize"`
+}
+
+// DescribeRolesRequest role list request
+type DescribeRolesRequest struct {
+	*athttp.BaseRequest
+	// the page number, the default is 1. required: no.
+	PageNum *int64 `json:"pageNum,omitempty" name:"pageNum"`
+	// the page size, the default is 100. required: no.
+	PageSize *int64 `json:"pageSize,omitempty" name:"pageSize"`
+}
+
+// DescribeRoleUnitsRequest role units request
+type DescribeRoleUnitsRequest struct {
+	*athttp.BaseRequest
+	UnitId *string
+	// return the email and mobile of the members. required: no.
+	SensitiveData *bool `json:"sensitiveData,omitempty" name:"sensitiveData"`
+}
+
+// MemberResponse member detail response
+type MemberResponse struct {
+	Member *Member `json:"member"`
+}
+
+// TeamResponse team detail response
+type TeamResponse struct {
+	Team *Team `json:"team"`
+}
+
+// UnitPagination the paging info of the units
+type UnitPagination struct {
+	// current number of pages
+	PageNum *int64 `json:"pageNum,omitempty" name:"pageNum"`
+
+	PageSize *int64 `json:"pageSize,omitempty" name:"pageSize"`
+
+	Total *int64 `json:"total,omitempty" name:"total"`
+}
+
+// TeamPagination paging sub teams response
+type TeamPagination struct {
+	UnitPagination
+	Teams []*Team `json:"teams"`
+}
+
+// MemberPagination paging members response
+type MemberPagination struct {
+	UnitPagination
+	Members []*Member `json:"members"`
+}
+
+// RolePagination paging roles response
+type RolePagination struct {
+	UnitPagination
+	Roles []*Role `json:"roles"`
+}
+
+// RoleUnits the teams and members of the role
+type RoleUnits struct {
+	Teams   []*Team   `json:"teams"`
+	Members []*Member `json:"members"`
+}
+
+// DescribeMemberResponse member detail response data
+type DescribeMemberResponse struct {
+	*athttp.BaseResponse
+	// api response data
+	Data *MemberResponse `json:"data"`
+}
+
+// DescribeTeamResponse team detail response data
+type DescribeTeamResponse struct {
+	*athttp.BaseResponse
+	// api response data
+	Data *TeamResponse `json:"data"`
+}
+
+// DescribeTeamChildrenResponse sub teams response data
+type DescribeTeamChildrenResponse struct {
+	*athttp.BaseResponse
+	// api response data
+	Data *TeamPagination `json:"data"`
+}
+
+// DescribeTeamMembersResponse team members response data
+type DescribeTeamMembersResponse struct {
+	*athttp.BaseResponse
+	// api response data
+	Data *MemberPagination `json:"data"`
+}
+
+// DescribeRolesResponse role list response data
+type DescribeRolesResponse struct {
+	*athttp.BaseResponse
+	// api response data
+	Data *RolePagination `json:"data"`
+}
+
+// DescribeRoleUnitsResponse role units response data
+type DescribeRoleUnitsResponse struct {
+	*athttp.BaseResponse
+	// api response data
+	Data *RoleUnits `json:"data"`
+}
@@ -0,0 +1,86 @@
+package space
+
+import (
+	"fmt"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/common"
+	aterror "github.com/apitable/apitable-sdks/apitable.go/lib/common/error"
+	athttp "github.com/apitable/apitable-sdks/apitable.go/lib/common/http"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/datasheet"
+)
+
+const datasheetCreatePath = "/fusion/v1/spaces/%s/datasheets"
+
+// NewCreateDatasheetRequest init create datasheet request instance
+func NewCreateDatasheetRequest() (request *CreateDatasheetRequest) {
+	request = &CreateDatasheetRequest{
+		BaseRequest: &athttp.BaseRequest{},
+	}
+	return
+}
+
+func newCreateDatasheetResponse() (response *CreateDatasheetResponse) {
+	response = &CreateDatasheetResponse{
+		BaseResponse: &athttp.BaseResponse{},
+	}
+	return
+}
+
+// Datasheet get the datasheet instance in the space, sharing the same client config
+func (c *Space) Datasheet(datasheetId string) *datasheet.Datasheet {
+	return &datasheet.Datasheet{
+		Client:      c.Client,
+		DatasheetId: datasheetId,
+		SpaceId:     c.SpaceId,
+	}
+}
+
+// CreateDatasheet create a datasheet in the folder with the initial fields, and return the datasheet instance.
+//
+// * folderId and description are optional, the default folder is the root folder.
+// * the first field is the primary field.
+func (c *Space) CreateDatasheet(name string, folderId string, fields []*DatasheetFieldSchema, description string) (dst *datasheet.Datasheet, err error) {
+	request := NewCreateDatasheetRequest()
+	request.Name = common.StringPtr(name)
+	if folderId != "" {
+		request.FolderId = common.StringPtr(folderId)
+	}
+	if description != "" {
+		request.Description = common.StringPtr(description)
+	}
+	request.Fields = fields
+	created, err := c.CreateDatasheetWithRequest(request)
+	if err != nil {
+		return nil, err
+	}
+	if created == nil || created.Id == nil {
+		return nil, aterror.NewSDKError(500, "The create response has no datasheet id", "ClientError.CreateError")
+	}
+	return c.Datasheet(*created.Id), nil
+}
+
+// CreateDatasheetWithRequest create a datasheet, and return the created datasheet id and fields.
+func (c *Space) CreateDatasheetWithRequest(request *CreateDatasheetRequest) (created *CreatedDatasheet, err error) {
+	if request == nil {
+		request = NewCreateDatasheetRequest()
+	}
+	if request.Name == nil || *request.Name == "" {
+		return nil, aterror.NewSDKError(400, "The name of the datasheet is required", "ClientError.InvalidArgument")
+	}
+	for _, field := range request.Fields {
+		if field == nil || field.Type == nil || field.Name == nil || *field.Name == "" {
+			return nil, aterror.NewSDKError(400, "The type and name of the field are required", "ClientError.InvalidArgument")
+		}
+		if err = datasheet.ValidateFieldProperty(*field.Type, field.Property); err != nil {
+			return nil, err
+		}
+	}
+	request.Init().SetPath(fmt.Sprintf(datasheetCreatePath, c.SpaceId))
+	request.SetContentType(athttp.JsonContent)
+	request.SetHttpMethod(athttp.POST)
+	response := newCreateDatasheetResponse()
+	err = c.Send(request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response.Data, nil
+}
@@ -0,0 +1,173 @@
+package space
+
+import (
+	"fmt"
+	aterror "github.com/apitable/apitable-sdks/apitable.go/lib/common/error"
+	athttp "github.com/apitable/apitable-sdks/apitable.go/lib/common/http"
+)
+
+const embedLinkPath = "/fusion/v1/spaces/%s/nodes/%s/embedlinks"
+const embedLinkDetailPath = "/fusion/v1/spaces/%s/nodes/%s/embedlinks/%s"
+
+// EmbedLinkTheme the theme of the embed page
+type EmbedLinkTheme string
+
+const (
+	EmbedLinkTheme_Light EmbedLinkTheme = "light"
+	EmbedLinkTheme_Dark  EmbedLinkTheme = "dark"
+)
+
+// EmbedLinkPermission the permission of the visitors
+type EmbedLinkPermission string
+
+const (
+	// visitors can only read the node
+	EmbedLinkPermission_ReadOnly EmbedLinkPermission = "readOnly"
+	// visitors can edit the node without login
+	EmbedLinkPermission_PublicEdit EmbedLinkPermission = "publicEdit"
+	// visitors can edit the node after login
+	EmbedLinkPermission_PrivateEdit EmbedLinkPermission = "privateEdit"
+)
+
+// EmbedLinkToolBar the visibility of the tool bar buttons
+type EmbedLinkToolBar struct {
+	BasicTools    *bool `json:"basicTools,omitempty" name:"basicTools"`
+	ShareBtn      *bool `json:"shareBtn,omitempty" name:"shareBtn"`
+	WidgetBtn     *bool `json:"widgetBtn,omitempty" name:"widgetBtn"`
+	ApiBtn        *bool `json:"apiBtn,omitempty" name:"apiBtn"`
+	FormBtn       *bool `json:"formBtn,omitempty" name:"formBtn"`
+	HistoryBtn    *bool `json:"historyBtn,omitempty" name:"historyBtn"`
+	RobotBtn      *bool `json:"robotBtn,omitempty" name:"robotBtn"`
+	AddWidgetBtn  *bool `json:"addWidgetBtn,omitempty" name:"addWidgetBtn"`
+	FullScreenBtn *bool `json:"fullScreenBtn,omitempty" name:"fullScreenBtn"`
+}
+
+// EmbedLinkViewControl the view options of the embed page
+type EmbedLinkViewControl struct {
+	// the view to show, the default is the first view. such as: `viw*****`
+	ViewId *string `json:"viewId,omitempty" name:"viewId"`
+	// show the view tab bar
+	TabBar *bool `json:"tabBar,omitempty" name:"tabBar"`
+	// show the node info bar
+	NodeInfoBar *bool `json:"nodeInfoBar,omitempty" name:"nodeInfoBar"`
+	// collapse the view list
+	Collapsed *bool `json:"collapsed,omitempty" name:"collapsed"`
+	// the visibility of the tool bar buttons
+	ToolBar *EmbedLinkToolBar `json:"toolBar,omitempty" name:"toolBar"`
+}
+
+// EmbedLinkSideBar the options of the primary side bar
+type EmbedLinkSideBar struct {
+	Collapsed *bool `json:"collapsed,omitempty" name:"collapsed"`
+}
+
+// EmbedLinkPayload the options of the embed page
+type EmbedLinkPayload struct {
+	PrimarySideBar *EmbedLinkSideBar     `json:"primarySideBar,omitempty" name:"primarySideBar"`
+	ViewControl    *EmbedLinkViewControl `json:"viewControl,omitempty" name:"viewControl"`
+	// show the logo banner
+	BannerLogo *bool `json:"bannerLogo,omitempty" name:"bannerLogo"`
+	// the permission of the visitors, the default is read only
+	PermissionType *EmbedLinkPermission `json:"permissionType,omitempty" name:"permissionType"`
+}
+
+// EmbedLinkOptions the options to create the embed link
+type EmbedLinkOptions struct {
+	Payload *EmbedLinkPayload `json:"payload,omitempty" name:"payload"`
+	Theme   *EmbedLinkTheme   `json:"theme,omitempty" name:"theme"`
+}
+
+// EmbedLink describe the embed link of the node
+type EmbedLink struct {
+	// such as: `emb*****`
+	LinkId *string `json:"linkId,omitempty" name:"linkId"`
+	// the url of the embed page
+	Url     *string           `json:"url,omitempty" name:"url"`
+	Payload *EmbedLinkPayload `json:"payload,omitempty" name:"payload"`
+	Theme   *EmbedLinkTheme   `json:"theme,omitempty" name:"theme"`
+}
+
+// CreateEmbedLinkRequest create embed link request
+type CreateEmbedLinkRequest struct {
+	*athttp.BaseRequest
+	EmbedLinkOptions
+}
+
+// DescribeEmbedLinksRequest embed link list request
+type DescribeEmbedLinksRequest struct {
+	*athttp.BaseRequest
+}
+
+// DeleteEmbedLinkRequest delete embed link request
+type DeleteEmbedLinkRequest struct {
+	*athttp.BaseRequest
+}
+
+// CreateEmbedLinkResponse create embed link response
+type CreateEmbedLinkResponse struct {
+	*athttp.BaseResponse
+	// api response data
+	Data *EmbedLink `json:"data"`
+}
+
+// DescribeEmbedLinksResponse embed link list response
+type DescribeEmbedLinksResponse struct {
+	*athttp.BaseResponse
+	// api response data
+	Data []*EmbedLink `json:"data"`
+}
+
+func nodeIdRequired() error {
+	return aterror.NewSDKError(400, "The node id is required", "ClientError.InvalidArgument")
+}
+
+// CreateEmbedLink create an embed link for the datasheet, dashboard or other node.
+//
+// * options is optional, the default is a read only page with the light theme.
+func (c *Space) CreateEmbedLink(nodeId string, options *EmbedLinkOptions) (link *EmbedLink, err error) {
+	if nodeId == "" {
+		return nil, nodeIdRequired()
+	}
+	request := &CreateEmbedLinkRequest{BaseRequest: &athttp.BaseRequest{}}
+	if options != nil {
+		request.EmbedLinkOptions = *options
+	}
+	request.Init().SetPath(fmt.Sprintf(embedLinkPath, c.SpaceId, nodeId))
+	request.SetContentType(athttp.JsonContent)
+	request.SetHttpMethod(athttp.POST)
+	response := &CreateEmbedLinkResponse{BaseResponse: &athttp.BaseResponse{}}
+	err = c.Send(request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response.Data, nil
+}
+
+// ListEmbedLinks get all embed links of the node
+func (c *Space) ListEmbedLinks(nodeId string) (links []*EmbedLink, err error) {
+	if nodeId == "" {
+		return nil, nodeIdRequired()
+	}
+	request := &DescribeEmbedLinksRequest{BaseRequest: &athttp.BaseRequest{}}
+	request.Init().SetPath(fmt.Sprintf(embedLinkPath, c.SpaceId, nodeId))
+	request.SetHttpMethod(athttp.GET)
+	response := &DescribeEmbedLinksResponse{BaseResponse: &athttp.BaseResponse{}}
+	err = c.Send(request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response.Data, nil
+}
+
+// DeleteEmbedLink delete the embed link of the node
+func (c *Space) DeleteEmbedLink(nodeId string, linkId string) (err error) {
+	if nodeId == "" || linkId == "" {
+		return aterror.NewSDKError(400, "The node id and link id are required", "ClientError.InvalidArgument")
+	}
+	request := &DeleteEmbedLinkRequest{BaseRequest: &athttp.BaseRequest{}}
+	request.Init().SetPath(fmt.Sprintf(embedLinkDetailPath, c.SpaceId, nodeId, linkId))
+	request.SetHttpMethod(athttp.DELETE)
+	response := &athttp.BaseResponse{}
+	err = c.Send(request, response)
+	return
+}
@@ -0,0 +1,220 @@
+package space
+
+import (
+	"fmt"
+	aterror "github.com/apitable/apitable-sdks/apitable.go/lib/common/error"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/datasheet"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultPathCacheTTL the default time to live of the resolved paths
+const DefaultPathCacheTTL = 5 * time.Minute
+
+type pathCacheEntry struct {
+	node    *NodeInfo
+	expires time.Time
+}
+
+type pathCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]*pathCacheEntry
+}
+
+func (p *pathCache) get(key string) *NodeInfo {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	entry, ok := p.entries[key]
+	if !ok {
+		return nil
+	}
+	if time.Now().After(entry.expires) {
+		delete(p.entries, key)
+		return nil
+	}
+	return entry.node
+}
+
+func (p *pathCache) set(key string, node *NodeInfo) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.ttl <= 0 {
+		return
+	}
+	p.entries[key] = &pathCacheEntry{node: node, expires: time.Now().Add(p.ttl)}
+}
+
+func (c *Space) cache() *pathCache {
+	if c.paths == nil {
+		c.paths = &pathCache{ttl: DefaultPathCacheTTL, entries: map[string]*pathCacheEntry{}}
+	}
+	return c.paths
+}
+
+// WithPathCacheTTL set the time to live of the resolved paths, 0 disables the cache.
+func (c *Space) WithPathCacheTTL(ttl time.Duration) *Space {
+	cache := c.cache()
+	cache.mu.Lock()
+	cache.ttl = ttl
+	cache.entries = map[string]*pathCacheEntry{}
+	cache.mu.Unlock()
+	return c
+}
+
+// ClearPathCache remove all resolved paths from the cache
+func (c *Space) ClearPathCache() {
+	cache := c.cache()
+	cache.mu.Lock()
+	cache.entries = map[string]*pathCacheEntry{}
+	cache.mu.Unlock()
+}
+
+// SplitPath split the path into node names, the escaped separator `\/` and backslash `\\` are kept in the name.
+func SplitPath(path string) []string {
+	names := []string{}
+	var b strings.Builder
+	escaped := false
+	for _, r := range path {
+		switch {
+		case escaped:
+			b.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case string(r) == PathSeparator:
+			if b.Len() > 0 {
+				names = append(names, b.String())
+			}
+			b.Reset()
+		default:
+			b.WriteRune(r)
+		}
+	}
+	if b.Len() > 0 {
+		names = append(names, b.String())
+	}
+	return names
+}
+
+func nodeNotFound(path string) error {
+	msg := fmt.Sprintf("Node not found: %s", path)
+	return aterror.NewSDKError(404, msg, "ClientError.NodeNotFound")
+}
+
+func ambiguousNodes(path string, ids []string) error {
+	msg := fmt.Sprintf("Ambiguous path %s, matched nodes: %s", path, strings.Join(ids, ", "))
+	return aterror.NewSDKError(409, msg, "ClientError.AmbiguousPath")
+}
+
+// ResolveNode find the node by the slash separated path from the root folder, such as: Sales/2024/Leads
+//
+// * return an error with code 404 if any node of the path is not found.
+// * return an error with code 409 if any node name of the path is duplicate in its folder.
+// * the resolved node is cached, see WithPathCacheTTL.
+func (c *Space) ResolveNode(path string) (node *NodeInfo, err error) {
+	names := SplitPath(path)
+	if len(names) == 0 {
+		return nil, aterror.NewSDKError(400, "The path is empty", "ClientError.InvalidArgument")
+	}
+	key := c.SpaceId + ":" + strings.Join(names, "\x00")
+	if node = c.cache().get(key); node != nil {
+		return node, nil
+	}
+
+	nodes, err := c.DescribeNodes(nil)
+	if err != nil {
+		return nil, err
+	}
+	parentId, parentPath := "", ""
+	for depth, name := range names {
+		currentPath := JoinPath(parentPath, name)
+		var matched []*NodeBaseInfo
+		for _, child := range nodes {
+			if child.Name != nil && *child.Name == name {
+				matched = append(matched, child)
+			}
+		}
+		if len(matched) == 0 {
+			return nil, nodeNotFound(currentPath)
+		}
+		if len(matched) > 1 {
+			ids := make([]string, len(matched))
+			for i, m := range matched {
+				ids[i] = *m.Id
+			}
+			return nil, ambiguousNodes(currentPath, ids)
+		}
+		node = &NodeInfo{NodeBaseInfo: matched[0], ParentId: parentId, Path: currentPath, Depth: depth}
+		if depth == len(names)-1 {
+			break
+		}
+		if node.Type == nil || *node.Type != NodeType_Folder {
+			return nil, nodeNotFound(JoinPath(currentPath, names[depth+1]))
+		}
+		request := NewDescribeNodeRequest()
+		request.NodeId = node.Id
+		detail, err := c.DescribeNode(request)
+		if err != nil {
+			return nil, err
+		}
+		nodes = detail.Children
+		parentId, parentPath = *node.Id, currentPath
+	}
+	c.cache().set(key, node)
+	return node, nil
+}
+
+// ResolvePath find the datasheet by the slash separated path from the root folder, such as: Sales/2024/Leads
+//
+// * see ResolveNode for the errors and cache.
+func (c *Space) ResolvePath(path string) (dst *datasheet.Datasheet, err error) {
+	node, err := c.ResolveNode(path)
+	if err != nil {
+		return nil, err
+	}
+	if node.Type == nil || *node.Type != NodeType_Datasheet {
+		msg := fmt.Sprintf("The node %s is not a datasheet", node.Path)
+		return nil, aterror.NewSDKError(400, msg, "ClientError.NotDatasheet")
+	}
+	return c.Datasheet(*node.Id), nil
+}
+
+// ResolveSpace find the space by the space name in the user's spaces, and return the space instance sharing the same client config.
+func (c *Space) ResolveSpace(spaceName string) (space *Space, err error) {
+	spaces, err := c.DescribeSpaces(nil)
+	if err != nil {
+		return nil, err
+	}
+	var matched []*SpaceBaseInfo
+	for _, s := range spaces {
+		if s.Name != nil && *s.Name == spaceName {
+			matched = append(matched, s)
+		}
+	}
+	if len(matched) == 0 {
+		msg := fmt.Sprintf("Space not found: %s", spaceName)
+		return nil, aterror.NewSDKError(404, msg, "ClientError.SpaceNotFound")
+	}
+	if len(matched) > 1 {
+		ids := make([]string, len(matched))
+		for i, m := range matched {
+			ids[i] = *m.Id
+		}
+		msg := fmt.Sprintf("Ambiguous space %s, matched spaces: %s", spaceName, strings.Join(ids, ", "))
+		return nil, aterror.NewSDKError(409, msg, "ClientError.AmbiguousSpace")
+	}
+	// share the path cache, because the cache key contains the space id.
+	space = &Space{Client: c.Client, SpaceId: *matched[0].Id, paths: c.cache()}
+	return space, nil
+}
+
+// OpenByPath find the datasheet by the space name and the path in the space, such as: OpenByPath("Space Name", "Folder/Sheet")
+func (c *Space) OpenByPath(spaceName string, path string) (dst *datasheet.Datasheet, err error) {
+	space, err := c.ResolveSpace(spaceName)
+	if err != nil {
+		return nil, err
+	}
+	return space.ResolvePath(path)
+}
@@ -4,11 +4,16 @@ import (
 	"github.com/apitable/apitable-sdks/apitable.go/lib/common"
 	athttp "github.com/apitable/apitable-sdks/apitable.go/lib/common/http"
 	"github.com/apitable/apitable-sdks/apitable.go/lib/common/profile"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/datasheet"
 )
 
 type Space struct {
 	common.Client
 	SpaceId string
+	// the cache of the resolved paths, see ResolvePath
+	paths *pathCache
+	// the cache of all members, see SearchMembers
+	members *memberCache
 }
 
 type NodeType string
@@ -20,6 +25,16 @@ const (
 	NodeType_Dashboard NodeType = "Dashboard"
 )
 
+// NodePermission the permission of the node
+type NodePermission int
+
+const (
+	NodePermission_Manager  NodePermission = 0
+	NodePermission_Editor   NodePermission = 1
+	NodePermission_Updater  NodePermission = 2
+	NodePermission_ReadOnly NodePermission = 3
+)
+
 // SpaceBaseInfo describe the property of the space
 type SpaceBaseInfo struct {
 	// Id spaceId
@@ -42,6 +57,8 @@ type NodeBaseInfo struct {
 	Icon *string `json:"icon,omitempty" name:"icon"`
 	// IsFav is the node in the favorite folder
 	IsFav *bool `json:"isFav,omitempty" name:"isFav"`
+	// Permission the permission of the node, only returned by searching nodes
+	Permission *NodePermission `json:"permission,omitempty" name:"permission"`
 }
 
 // NodeDetail node detail
@@ -66,6 +83,17 @@ type DescribeNodeRequest struct {
 	NodeId *string
 }
 
+// SearchNodesRequest node search request
+type SearchNodesRequest struct {
+	*athttp.BaseRequest
+	// filter by node type. required: yes.
+	Type *NodeType `json:"type,omitempty" name:"type"`
+	// filter by permissions, separated by comma. such as: 0,1. required: no.
+	Permissions *string `json:"permissions,omitempty" name:"permissions"`
+	// filter by node name. required: no.
+	Query *string `json:"query,omitempty" name:"query"`
+}
+
 // SpaceResponse space list response
 type SpaceResponse struct {
 	Spaces []*SpaceBaseInfo `json:"spaces"`
@@ -100,9 +128,52 @@ type DescribeNodeResponse struct {
 // NewSpace init space instance
 func NewSpace(credential *common.Credential, spaceId string, clientProfile *profile.ClientProfile) (space *Space, err error) {
 	space = &Space{}
+	space.cache()
 	if spaceId != "" {
 		space.SpaceId = spaceId
 	}
 	space.Init().WithCredential(credential).WithProfile(clientProfile)
 	return
 }
+
+// DatasheetFieldSchema the field to create with the datasheet
+type DatasheetFieldSchema struct {
+	// field type. required: yes.
+	Type *datasheet.FieldType `json:"type,omitempty" name:"type"`
+	// field name. required: yes.
+	Name *string `json:"name,omitempty" name:"name"`
+	// field property, such as: *datasheet.SelectFieldProperty. required: depends on the field type.
+	Property interface{} `json:"property,omitempty" name:"property"`
+}
+
+// CreateDatasheetRequest create datasheet request
+type CreateDatasheetRequest struct {
+	*athttp.BaseRequest
+	// datasheet name. required: yes.
+	Name *string `json:"name,omitempty" name:"name"`
+	// datasheet description. required: no.
+	Description *string `json:"description,omitempty" name:"description"`
+	// the parent folder id, the default is the root folder. such as: `fod*****`. required: no.
+	FolderId *string `json:"folderId,omitempty" name:"folderId"`
+	// the previous node id in the same folder. required: no.
+	PreNodeId *string `json:"preNodeId,omitempty" name:"preNodeId"`
+	// the initial fields, the first field is the primary field. required: no.
+	Fields []*DatasheetFieldSchema `json:"fields,omitempty" name:"fields"`
+}
+
+// CreatedDatasheet the created datasheet info
+type CreatedDatasheet struct {
+	// such as: `dst*****`
+	Id *string `json:"id,omitempty" name:"id"`
+	// datasheet creation time. such as: timestamp
+	CreatedAt *int64 `json:"createdAt,omitempty" name:"createdAt"`
+	// the created fields
+	Fields []*datasheet.FieldBaseInfo `json:"fields,omitempty" name:"fields"`
+}
+
+// CreateDatasheetResponse create datasheet response
+type CreateDatasheetResponse struct {
+	*athttp.BaseResponse
+	// api response data
+	Data *CreatedDatasheet `json:"data"`
+}
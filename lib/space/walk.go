@@ -0,0 +1,236 @@
+package space
+
+import (
+	"errors"
+	"fmt"
+	athttp "github.com/apitable/apitable-sdks/apitable.go/lib/common/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const nodeSearchPath = "/fusion/v2/spaces/%s/nodes"
+
+// the default count of concurrent requests to walk nodes
+const defaultWalkConcurrency = 5
+
+// PathSeparator the separator of the folder path
+const PathSeparator = "/"
+
+// SkipFolder used as a return value from WalkFunc to skip the children of the folder
+var SkipFolder = errors.New("skip this folder")
+
+// NodeInfo the node with its position in the node tree
+type NodeInfo struct {
+	*NodeBaseInfo
+	// ParentId the parent folder id, empty for the root level nodes
+	ParentId string
+	// Path the full folder path of the node, such as: Sales/2024/Leads
+	Path string
+	// Depth the depth of the node, 0 for the root level nodes
+	Depth int
+}
+
+// WalkFunc the function called for each node, the calls are serialized.
+//
+// * return SkipFolder to skip the children of the folder.
+// * return other error to stop walking.
+type WalkFunc func(node *NodeInfo) error
+
+// WalkOptions the options to walk nodes
+type WalkOptions struct {
+	// the count of concurrent requests, the default is 5
+	Concurrency int
+}
+
+// NewSearchNodesRequest init search nodes request instance
+func NewSearchNodesRequest() (request *SearchNodesRequest) {
+	request = &SearchNodesRequest{
+		BaseRequest: &athttp.BaseRequest{},
+	}
+	return
+}
+
+// WithPermissions set the permissions to filter by
+func (r *SearchNodesRequest) WithPermissions(permissions ...NodePermission) *SearchNodesRequest {
+	values := make([]string, len(permissions))
+	for i, permission := range permissions {
+		values[i] = strconv.Itoa(int(permission))
+	}
+	s := strings.Join(values, ",")
+	r.Permissions = &s
+	return r
+}
+
+// pathEscaper escape the escape character and the separator in the node name
+var pathEscaper = strings.NewReplacer("\\", "\\\\", PathSeparator, "\\"+PathSeparator)
+
+// JoinPath join the folder path and the node name, the separator and the backslash in the name are escaped.
+func JoinPath(parent string, name string) string {
+	name = pathEscaper.Replace(name)
+	if parent == "" {
+		return name
+	}
+	return parent + PathSeparator + name
+}
+
+// WalkNodes walk the whole node tree, see WalkNodesWithOptions
+func (c *Space) WalkNodes(fn WalkFunc) error {
+	return c.WalkNodesWithOptions(nil, fn)
+}
+
+type walker struct {
+	space *Space
+	fn    WalkFunc
+	sem   chan struct{}
+	wg    sync.WaitGroup
+	// serialize the calls of fn, and protect err.
+	mu  sync.Mutex
+	err error
+}
+
+// WalkNodesWithOptions walk the whole node tree, the folder children are queried with bounded concurrency.
+//
+// * the parent folder is always visited before its children, the order of siblings is not guaranteed.
+func (c *Space) WalkNodesWithOptions(options *WalkOptions, fn WalkFunc) error {
+	concurrency := defaultWalkConcurrency
+	if options != nil && options.Concurrency > 0 {
+		concurrency = options.Concurrency
+	}
+	nodes, err := c.DescribeNodes(nil)
+	if err != nil {
+		return err
+	}
+	w := &walker{
+		space: c,
+		fn:    fn,
+		sem:   make(chan struct{}, concurrency),
+	}
+	w.visit(nodes, "", "", 0)
+	w.wg.Wait()
+	return w.err
+}
+
+func (w *walker) failed() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err != nil
+}
+
+// nodeName the path segment of the node, the node id if the name is missing
+func nodeName(node *NodeBaseInfo) string {
+	if node.Name != nil {
+		return *node.Name
+	}
+	if node.Id != nil {
+		return *node.Id
+	}
+	return ""
+}
+
+func (w *walker) visit(nodes []*NodeBaseInfo, parentId string, parentPath string, depth int) {
+	for _, node := range nodes {
+		info := &NodeInfo{
+			NodeBaseInfo: node,
+			ParentId:     parentId,
+			Path:         JoinPath(parentPath, nodeName(node)),
+			Depth:        depth,
+		}
+		w.mu.Lock()
+		if w.err != nil {
+			w.mu.Unlock()
+			return
+		}
+		err := w.fn(info)
+		if err != nil && err != SkipFolder {
+			w.err = err
+			w.mu.Unlock()
+			return
+		}
+		w.mu.Unlock()
+		if err == SkipFolder || node.Type == nil || *node.Type != NodeType_Folder {
+			continue
+		}
+		w.wg.Add(1)
+		go w.children(info)
+	}
+}
+
+func (w *walker) children(folder *NodeInfo) {
+	defer w.wg.Done()
+	w.sem <- struct{}{}
+	if w.failed() {
+		<-w.sem
+		return
+	}
+	request := NewDescribeNodeRequest()
+	request.NodeId = folder.Id
+	detail, err := w.space.DescribeNode(request)
+	<-w.sem
+	if err != nil {
+		w.mu.Lock()
+		if w.err == nil {
+			w.err = err
+		}
+		w.mu.Unlock()
+		return
+	}
+	w.visit(detail.Children, *folder.Id, folder.Path, folder.Depth+1)
+}
+
+// ListNodes walk the whole node tree, and return all nodes
+func (c *Space) ListNodes() (nodes []*NodeInfo, err error) {
+	err = c.WalkNodes(func(node *NodeInfo) error {
+		nodes = append(nodes, node)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
+
+// SearchNodes search nodes by type, permissions and name in the whole space.
+//
+// * the folder path of the result nodes is resolved by walking the node tree.
+func (c *Space) SearchNodes(request *SearchNodesRequest) (nodes []*NodeInfo, err error) {
+	if request == nil {
+		request = NewSearchNodesRequest()
+	}
+	if request.Type == nil {
+		nodeType := NodeType_Datasheet
+		request.Type = &nodeType
+	}
+	request.Init().SetPath(fmt.Sprintf(nodeSearchPath, c.SpaceId))
+	request.SetHttpMethod(athttp.GET)
+	response := newDescribeNodesResponse()
+	err = c.Send(request, response)
+	if err != nil {
+		return nil, err
+	}
+	if len(response.Data.Nodes) == 0 {
+		return []*NodeInfo{}, nil
+	}
+	all, err := c.ListNodes()
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[string]*NodeInfo, len(all))
+	for _, node := range all {
+		if node.Id != nil {
+			index[*node.Id] = node
+		}
+	}
+	nodes = make([]*NodeInfo, 0, len(response.Data.Nodes))
+	for _, node := range response.Data.Nodes {
+		info := &NodeInfo{NodeBaseInfo: node, Path: JoinPath("", nodeName(node))}
+		if node.Id != nil && index[*node.Id] != nil {
+			found := index[*node.Id]
+			info.ParentId = found.ParentId
+			info.Path = found.Path
+			info.Depth = found.Depth
+		}
+		nodes = append(nodes, info)
+	}
+	return nodes, nil
+}
@@ -0,0 +1,233 @@
+// Package sqldriver provides the database/sql driver to query and modify the datasheets as tables, such as:
+//
+//	db, err := sql.Open("vika", "token=uskXXX&space=spcXXX")
+//	rows, err := db.Query(`SELECT name, amount FROM "Sales/Leads" WHERE status = ? ORDER BY amount DESC LIMIT 50`, "Open")
+//
+// * the table is the datasheet id, or the slash separated path of the datasheet in the space.
+// * the columns are the field names, and the pseudo column _recordId is the record id.
+// * the supported statements are SELECT, INSERT, UPDATE and DELETE, the transactions are not supported.
+package sqldriver
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/common"
+	aterror "github.com/apitable/apitable-sdks/apitable.go/lib/common/error"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/common/profile"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/space"
+	"io"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// DriverName the name of the registered driver
+const DriverName = "vika"
+
+func init() {
+	sql.Register(DriverName, &Driver{})
+}
+
+// Config the connection config parsed from the data source name
+type Config struct {
+	// the api token. required: yes.
+	Token string
+	// the space id, required if the tables are the paths.
+	SpaceId string
+	// the request host, such as: api.vika.cn. required: no.
+	Domain string
+	// HTTP or HTTPS, the default is HTTPS. required: no.
+	Scheme string
+}
+
+// ParseDSN parse the data source name in the url query format, such as: token=uskXXX&space=spcXXX&domain=api.vika.cn
+func ParseDSN(dsn string) (*Config, error) {
+	values, err := url.ParseQuery(strings.TrimPrefix(dsn, "?"))
+	if err != nil {
+		msg := fmt.Sprintf("Invalid data source name: %v", err)
+		return nil, aterror.NewSDKError(400, msg, "ClientError.InvalidArgument")
+	}
+	config := &Config{
+		Token:   values.Get("token"),
+		SpaceId: values.Get("space"),
+		Domain:  values.Get("domain"),
+		Scheme:  strings.ToUpper(values.Get("scheme")),
+	}
+	if config.Token == "" {
+		return nil, aterror.NewSDKError(400, "The token of the data source name is required", "ClientError.InvalidArgument")
+	}
+	return config, nil
+}
+
+// Driver the database/sql driver, registered as "vika"
+type Driver struct{}
+
+// Open open the connection by the data source name, see ParseDSN
+func (d *Driver) Open(dsn string) (driver.Conn, error) {
+	config, err := ParseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	cpf := profile.NewClientProfile()
+	if config.Domain != "" {
+		cpf.HttpProfile.Domain = config.Domain
+	}
+	if config.Scheme != "" {
+		cpf.HttpProfile.Scheme = config.Scheme
+	}
+	s, err := space.NewSpace(common.NewCredential(config.Token), config.SpaceId, cpf)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{space: s}, nil
+}
+
+func notSupported(feature string) error {
+	msg := fmt.Sprintf("%s is not supported by the %s driver", feature, DriverName)
+	return aterror.NewSDKError(400, msg, "ClientError.NotSupported")
+}
+
+// conn the connection sharing the client of the space, it's stateless
+type conn struct {
+	space *space.Space
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	parsed, args, err := parse(query)
+	if err != nil {
+		return nil, err
+	}
+	return &stmt{conn: c, parsed: parsed, args: args}, nil
+}
+
+func (c *conn) Close() error {
+	return nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return nil, notSupported("Transaction")
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	s, err := c.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	return s.(*stmt).QueryContext(ctx, args)
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	s, err := c.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	return s.(*stmt).ExecContext(ctx, args)
+}
+
+// CheckNamedValue accept the values of any type, they are converted by the statements
+func (c *conn) CheckNamedValue(*driver.NamedValue) error {
+	return nil
+}
+
+// stmt the parsed statement
+type stmt struct {
+	conn   *conn
+	parsed interface{}
+	args   int
+}
+
+func (s *stmt) Close() error {
+	return nil
+}
+
+func (s *stmt) NumInput() int {
+	return s.args
+}
+
+func namedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+	return named
+}
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), namedValues(args))
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), namedValues(args))
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	query, ok := s.parsed.(*selectStmt)
+	if !ok {
+		return nil, invalidSQL("Only SELECT statement returns rows")
+	}
+	return s.conn.query(query, args)
+}
+
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	switch parsed := s.parsed.(type) {
+	case *insertStmt:
+		return s.conn.insert(parsed, args)
+	case *updateStmt:
+		return s.conn.update(parsed, args)
+	case *deleteStmt:
+		return s.conn.delete(parsed, args)
+	}
+	return nil, invalidSQL("Use Query to execute SELECT statement")
+}
+
+// result the count of the written records, the record ids are not integers so LastInsertId is not supported
+type result struct {
+	affected int64
+}
+
+func (r *result) LastInsertId() (int64, error) {
+	return 0, notSupported("LastInsertId")
+}
+
+func (r *result) RowsAffected() (int64, error) {
+	return r.affected, nil
+}
+
+// rows the queried rows, all records are loaded before returned
+type rows struct {
+	columns []string
+	types   []string
+	values  [][]interface{}
+	next    int
+}
+
+func (r *rows) Columns() []string {
+	return r.columns
+}
+
+func (r *rows) Close() error {
+	r.next = len(r.values)
+	return nil
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.next >= len(r.values) {
+		return io.EOF
+	}
+	for i, value := range r.values[r.next] {
+		dest[i] = value
+	}
+	r.next++
+	return nil
+}
+
+// ColumnTypeDatabaseTypeName the column type derived from the field type, such as: TEXT, NUMBER, DATETIME
+func (r *rows) ColumnTypeDatabaseTypeName(index int) string {
+	return r.types[index]
+}
+
+func (r *rows) ColumnTypeScanType(index int) reflect.Type {
+	return scanTypes[r.types[index]]
+}
@@ -0,0 +1,411 @@
+package sqldriver
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// normalize convert the argument to the value type of the evaluation: nil, string, float64, bool or time.Time
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint:
+		return float64(v)
+	case uint32:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	case []byte:
+		return string(v)
+	}
+	return value
+}
+
+// evaluator evaluate the expressions with the arguments, row get the column value of the current record
+type evaluator struct {
+	args []driver.NamedValue
+	row  func(column string) interface{}
+}
+
+func (e *evaluator) arg(index int) (interface{}, error) {
+	if index >= len(e.args) {
+		return nil, invalidSQL("Missing argument %d", index+1)
+	}
+	return normalize(e.args[index].Value), nil
+}
+
+// eval get the value of the expression, the condition is true, false or nil if unknown
+func (e *evaluator) eval(ex expr) (interface{}, error) {
+	switch x := ex.(type) {
+	case *literal:
+		return x.value, nil
+	case *placeholder:
+		return e.arg(x.index)
+	case *columnRef:
+		if e.row == nil {
+			return nil, invalidSQL("Column %s is not allowed here", x.name)
+		}
+		return e.row(x.name), nil
+	case *notExpr:
+		v, err := e.eval(x.expr)
+		if b, ok := v.(bool); ok {
+			return !b, err
+		}
+		return nil, err
+	case *isNullExpr:
+		v, err := e.eval(x.expr)
+		return (v == nil) != x.not, err
+	case *likeExpr:
+		return e.like(x)
+	case *inExpr:
+		return e.in(x)
+	case *binaryExpr:
+		if x.op == "AND" || x.op == "OR" {
+			return e.logical(x)
+		}
+		left, err := e.eval(x.left)
+		if err != nil {
+			return nil, err
+		}
+		right, err := e.eval(x.right)
+		if err != nil {
+			return nil, err
+		}
+		c, ok := compare(left, right)
+		if !ok {
+			return nil, nil
+		}
+		switch x.op {
+		case "=":
+			return c == 0, nil
+		case "!=":
+			return c != 0, nil
+		case "<":
+			return c < 0, nil
+		case "<=":
+			return c <= 0, nil
+		case ">":
+			return c > 0, nil
+		case ">=":
+			return c >= 0, nil
+		}
+	}
+	return nil, invalidSQL("Unsupported expression %T", ex)
+}
+
+// logical the three-valued AND and OR
+func (e *evaluator) logical(x *binaryExpr) (interface{}, error) {
+	left, err := e.eval(x.left)
+	if err != nil {
+		return nil, err
+	}
+	right, err := e.eval(x.right)
+	if err != nil {
+		return nil, err
+	}
+	l, lok := left.(bool)
+	r, rok := right.(bool)
+	// the short-circuit value decides the result even if the other side is unknown
+	short := x.op == "OR"
+	if (lok && l == short) || (rok && r == short) {
+		return short, nil
+	}
+	if !lok || !rok {
+		return nil, nil
+	}
+	return !short, nil
+}
+
+func (e *evaluator) like(x *likeExpr) (interface{}, error) {
+	v, err := e.eval(x.expr)
+	if err != nil {
+		return nil, err
+	}
+	p, err := e.eval(x.pattern)
+	if err != nil || v == nil || p == nil {
+		return nil, err
+	}
+	pattern, ok := p.(string)
+	if !ok {
+		return nil, invalidSQL("The LIKE pattern must be a string")
+	}
+	matched := likePattern(pattern).MatchString(fmt.Sprint(v))
+	return matched != x.not, nil
+}
+
+// likePattern the case insensitive regexp of the LIKE pattern, % matches any characters and _ matches one character
+func likePattern(pattern string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("(?is)^")
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			b.WriteString(".*")
+		case '_':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
+
+func (e *evaluator) in(x *inExpr) (interface{}, error) {
+	v, err := e.eval(x.expr)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	unknown := false
+	for _, item := range x.list {
+		value, err := e.eval(item)
+		if err != nil {
+			return nil, err
+		}
+		c, ok := compare(v, value)
+		if !ok {
+			unknown = true
+			continue
+		}
+		if c == 0 {
+			return !x.not, nil
+		}
+	}
+	if unknown {
+		return nil, nil
+	}
+	return x.not, nil
+}
+
+// compare compare the values, ok is false if any value is nil
+//
+// * the string is parsed as the number or the time when compared with them.
+// * the time is compared with the number as the timestamp in milliseconds.
+func compare(a interface{}, b interface{}) (c int, ok bool) {
+	a, b = normalize(a), normalize(b)
+	if a == nil || b == nil {
+		return 0, false
+	}
+	switch x := a.(type) {
+	case float64:
+		switch y := b.(type) {
+		case float64:
+			return compareFloat(x, y), true
+		case string:
+			if n, err := strconv.ParseFloat(strings.TrimSpace(y), 64); err == nil {
+				return compareFloat(x, n), true
+			}
+		case time.Time:
+			return compareFloat(x, float64(y.UnixNano()/int64(time.Millisecond))), true
+		case bool:
+			return compareFloat(x, boolNumber(y)), true
+		}
+	case time.Time:
+		switch y := b.(type) {
+		case time.Time:
+			return compareFloat(float64(x.UnixNano()), float64(y.UnixNano())), true
+		case string:
+			if t, parsed := parseTime(y); parsed {
+				return compareFloat(float64(x.UnixNano()), float64(t.UnixNano())), true
+			}
+		case float64:
+			return compareFloat(float64(x.UnixNano()/int64(time.Millisecond)), y), true
+		}
+	case bool:
+		switch y := b.(type) {
+		case bool:
+			return compareFloat(boolNumber(x), boolNumber(y)), true
+		case float64:
+			return compareFloat(boolNumber(x), y), true
+		}
+	case string:
+		if _, isString := b.(string); !isString {
+			c, ok = compare(b, a)
+			return -c, ok
+		}
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b)), true
+}
+
+func compareFloat(a float64, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func boolNumber(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// columns collect the columns referenced by the expression
+func columns(ex expr, names map[string]bool) {
+	switch x := ex.(type) {
+	case *columnRef:
+		names[x.name] = true
+	case *binaryExpr:
+		columns(x.left, names)
+		columns(x.right, names)
+	case *notExpr:
+		columns(x.expr, names)
+	case *isNullExpr:
+		columns(x.expr, names)
+	case *likeExpr:
+		columns(x.expr, names)
+		columns(x.pattern, names)
+	case *inExpr:
+		columns(x.expr, names)
+		for _, item := range x.list {
+			columns(item, names)
+		}
+	}
+}
+
+// formula translate the where clause to the filter formula of the records query.
+//
+// * the records matched by the formula are a superset of the rows matched by the where clause,
+// so the where clause is always evaluated again by the client.
+// * exact is true if the formula matches the same records, then the limit can be pushed down too.
+// * the untranslatable conditions of AND are dropped, the others return an empty formula.
+func (e *evaluator) formula(ex expr, t *table) (formula string, exact bool) {
+	switch x := ex.(type) {
+	case *binaryExpr:
+		switch x.op {
+		case "AND", "OR":
+			left, lexact := e.formula(x.left, t)
+			right, rexact := e.formula(x.right, t)
+			if x.op == "AND" {
+				switch {
+				case left == "":
+					return right, false
+				case right == "":
+					return left, false
+				}
+			} else if left == "" || right == "" {
+				return "", false
+			}
+			return fmt.Sprintf("%s(%s, %s)", x.op, left, right), lexact && rexact
+		}
+		return e.comparisonFormula(x, t)
+	case *notExpr:
+		// the negation of a superset is a subset, so only the exact formula is negated
+		if inner, exact := e.formula(x.expr, t); inner != "" && exact {
+			return "NOT(" + inner + ")", false
+		}
+	case *isNullExpr:
+		column, ok := x.expr.(*columnRef)
+		if !ok || t.fields[column.name] == nil || t.columnType(column.name) == ColumnType_Boolean {
+			return "", false
+		}
+		if x.not {
+			return fmt.Sprintf("NOT(%s=BLANK())", fieldRef(column.name)), true
+		}
+		return fmt.Sprintf("%s=BLANK()", fieldRef(column.name)), true
+	case *inExpr:
+		if x.not {
+			return "", false
+		}
+		items := []string{}
+		exact := true
+		for _, item := range x.list {
+			f, itemExact := e.comparisonFormula(&binaryExpr{op: "=", left: x.expr, right: item}, t)
+			if f == "" {
+				return "", false
+			}
+			items = append(items, f)
+			exact = exact && itemExact
+		}
+		if len(items) == 1 {
+			return items[0], exact
+		}
+		return "OR(" + strings.Join(items, ", ") + ")", exact
+	case *likeExpr:
+		// only the containing pattern is translated, such as: %keyword%
+		column, ok := x.expr.(*columnRef)
+		pattern, _ := e.constant(x.pattern)
+		s, _ := pattern.(string)
+		if !ok || x.not || t.columnType(column.name) != ColumnType_Text || t.fields[column.name] == nil ||
+			len(s) < 3 || !strings.HasPrefix(s, "%") || !strings.HasSuffix(s, "%") || strings.ContainsAny(s[1:len(s)-1], "%_") {
+			return "", false
+		}
+		return fmt.Sprintf("FIND(LOWER(%s), LOWER(%s))>0", formulaString(s[1:len(s)-1]), fieldRef(column.name)), true
+	}
+	return "", false
+}
+
+// comparisonFormula translate the comparison of a field and a constant value
+func (e *evaluator) comparisonFormula(x *binaryExpr, t *table) (string, bool) {
+	op := x.op
+	column, ok := x.left.(*columnRef)
+	value, constant := e.constant(x.right)
+	if !ok {
+		// the constant is on the left side
+		if column, ok = x.right.(*columnRef); !ok {
+			return "", false
+		}
+		value, constant = e.constant(x.left)
+		op = map[string]string{"=": "=", "!=": "!=", "<": ">", "<=": ">=", ">": "<", ">=": "<="}[op]
+	}
+	if !constant || value == nil || t.fields[column.name] == nil {
+		return "", false
+	}
+	ref := fieldRef(column.name)
+	switch t.columnType(column.name) {
+	case ColumnType_Text:
+		s, ok := value.(string)
+		if !ok || (op != "=" && op != "!=") {
+			return "", false
+		}
+		// the empty cell doesn't equal to any string, but it's not equal to the string in the formula
+		return fmt.Sprintf("%s%s%s", ref, op, formulaString(s)), op == "=" && s != ""
+	case ColumnType_Number:
+		n, ok := value.(float64)
+		if !ok || math.IsInf(n, 0) || math.IsNaN(n) {
+			return "", false
+		}
+		// the empty cell is 0 in the formula
+		exact := (op == "=" && n != 0) || (op == ">" && n >= 0) || (op == ">=" && n > 0)
+		return fmt.Sprintf("%s%s%s", ref, op, strconv.FormatFloat(n, 'f', -1, 64)), exact
+	case ColumnType_Boolean:
+		if b, ok := value.(bool); ok && b && op == "=" {
+			return ref + "=TRUE()", true
+		}
+	}
+	return "", false
+}
+
+// constant evaluate the literal or the argument
+func (e *evaluator) constant(ex expr) (interface{}, bool) {
+	switch ex.(type) {
+	case *literal, *placeholder:
+		value, err := e.eval(ex)
+		return value, err == nil
+	}
+	return nil, false
+}
+
+func fieldRef(name string) string {
+	return "{" + name + "}"
+}
+
+func formulaString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,619 @@
+package sqldriver
+
+import (
+	"fmt"
+	aterror "github.com/apitable/apitable-sdks/apitable.go/lib/common/error"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	// the bare identifier or keyword
+	tokenWord
+	// the "double quoted" or `back quoted` identifier
+	tokenQuoted
+	tokenString
+	tokenNumber
+	// the ? or $n placeholder
+	tokenPlaceholder
+	tokenSymbol
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func invalidSQL(format string, args ...interface{}) error {
+	return aterror.NewSDKError(400, fmt.Sprintf(format, args...), "ClientError.InvalidSQL")
+}
+
+// tokenize split the sql into tokens, the comments are skipped
+func tokenize(sql string) ([]*token, error) {
+	tokens := []*token{}
+	runes := []rune(sql)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case r == '\'' || r == '"' || r == '`':
+			// the quote is escaped by doubling it
+			var b strings.Builder
+			start := i
+			for i++; ; i++ {
+				if i >= len(runes) {
+					return nil, invalidSQL("Unterminated quote at %d", start)
+				}
+				if runes[i] == r {
+					if i+1 < len(runes) && runes[i+1] == r {
+						b.WriteRune(r)
+						i++
+						continue
+					}
+					i++
+					break
+				}
+				b.WriteRune(runes[i])
+			}
+			kind := tokenQuoted
+			if r == '\'' {
+				kind = tokenString
+			}
+			tokens = append(tokens, &token{kind: kind, text: b.String(), pos: start})
+		case r == '?':
+			tokens = append(tokens, &token{kind: tokenPlaceholder, text: "?", pos: i})
+			i++
+		case r == '$':
+			start := i
+			for i++; i < len(runes) && unicode.IsDigit(runes[i]); i++ {
+			}
+			if i == start+1 {
+				return nil, invalidSQL("Invalid placeholder at %d", start)
+			}
+			tokens = append(tokens, &token{kind: tokenPlaceholder, text: string(runes[start:i]), pos: start})
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, &token{kind: tokenNumber, text: string(runes[start:i]), pos: start})
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, &token{kind: tokenWord, text: string(runes[start:i]), pos: start})
+		default:
+			text := string(r)
+			if i+1 < len(runes) {
+				switch two := string(runes[i : i+2]); two {
+				case "<=", ">=", "<>", "!=":
+					text = two
+				}
+			}
+			if !strings.Contains("(),;*=<>!-", text[:1]) || text == "!" {
+				return nil, invalidSQL("Unexpected character %q at %d", r, i)
+			}
+			tokens = append(tokens, &token{kind: tokenSymbol, text: text, pos: i})
+			i += len([]rune(text))
+		}
+	}
+	return append(tokens, &token{kind: tokenEOF, pos: len(runes)}), nil
+}
+
+// expr the expression of the where clause or the values
+type expr interface{}
+
+// columnRef the field name, or _recordId
+type columnRef struct {
+	name string
+}
+
+// literal the string, float64, bool or nil value
+type literal struct {
+	value interface{}
+}
+
+// placeholder the argument, index starts from 0
+type placeholder struct {
+	index int
+}
+
+// binaryExpr AND, OR or the comparison
+type binaryExpr struct {
+	op    string
+	left  expr
+	right expr
+}
+
+type notExpr struct {
+	expr expr
+}
+
+type isNullExpr struct {
+	expr expr
+	not  bool
+}
+
+type likeExpr struct {
+	expr    expr
+	pattern expr
+	not     bool
+}
+
+type inExpr struct {
+	expr expr
+	list []expr
+	not  bool
+}
+
+type selectColumn struct {
+	name  string
+	alias string
+}
+
+type orderBy struct {
+	column string
+	desc   bool
+}
+
+type selectStmt struct {
+	table string
+	// nil for *
+	columns []*selectColumn
+	where   expr
+	orderBy []*orderBy
+	// nil if not specified
+	limit  expr
+	offset expr
+}
+
+type insertStmt struct {
+	table   string
+	columns []string
+	rows    [][]expr
+}
+
+type assignment struct {
+	column string
+	value  expr
+}
+
+type updateStmt struct {
+	table string
+	sets  []*assignment
+	where expr
+}
+
+type deleteStmt struct {
+	table string
+	where expr
+}
+
+type parser struct {
+	tokens []*token
+	pos    int
+	// the count of the arguments
+	args int
+	// the next index of the ? placeholder
+	next int
+}
+
+// parse parse one statement, return the statement and the count of the arguments
+func parse(sql string) (stmt interface{}, args int, err error) {
+	tokens, err := tokenize(sql)
+	if err != nil {
+		return nil, 0, err
+	}
+	p := &parser{tokens: tokens}
+	switch {
+	case p.keyword("SELECT"):
+		stmt, err = p.selectStmt()
+	case p.keyword("INSERT"):
+		stmt, err = p.insertStmt()
+	case p.keyword("UPDATE"):
+		stmt, err = p.updateStmt()
+	case p.keyword("DELETE"):
+		stmt, err = p.deleteStmt()
+	default:
+		return nil, 0, p.unexpected("SELECT, INSERT, UPDATE or DELETE")
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	p.symbol(";")
+	if p.peek().kind != tokenEOF {
+		return nil, 0, p.unexpected("the end of the statement")
+	}
+	return stmt, p.args, nil
+}
+
+func (p *parser) peek() *token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) unexpected(expected string) error {
+	t := p.peek()
+	if t.kind == tokenEOF {
+		return invalidSQL("Expect %s, but the statement ends", expected)
+	}
+	return invalidSQL("Expect %s at %d, but got %q", expected, t.pos, t.text)
+}
+
+// keyword consume the keyword if matched, case insensitive
+func (p *parser) keyword(words ...string) bool {
+	for i, word := range words {
+		if p.pos+i >= len(p.tokens) {
+			return false
+		}
+		t := p.tokens[p.pos+i]
+		if t.kind != tokenWord || !strings.EqualFold(t.text, word) {
+			return false
+		}
+	}
+	p.pos += len(words)
+	return true
+}
+
+func (p *parser) expectKeyword(words ...string) error {
+	if !p.keyword(words...) {
+		return p.unexpected(strings.Join(words, " "))
+	}
+	return nil
+}
+
+func (p *parser) symbol(text string) bool {
+	if t := p.peek(); t.kind == tokenSymbol && t.text == text {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectSymbol(text string) error {
+	if !p.symbol(text) {
+		return p.unexpected(text)
+	}
+	return nil
+}
+
+var reservedWords = map[string]bool{
+	"SELECT": true, "FROM": true, "WHERE": true, "ORDER": true, "BY": true, "LIMIT": true, "OFFSET": true,
+	"INSERT": true, "INTO": true, "VALUES": true, "UPDATE": true, "SET": true, "DELETE": true,
+	"AND": true, "OR": true, "NOT": true, "IS": true, "NULL": true, "LIKE": true, "IN": true,
+	"TRUE": true, "FALSE": true, "AS": true, "ASC": true, "DESC": true,
+}
+
+// identifier the bare or quoted name, the table name may be a path such as "Sales/Leads"
+func (p *parser) identifier(what string) (string, error) {
+	t := p.peek()
+	if t.kind == tokenQuoted || (t.kind == tokenWord && !reservedWords[strings.ToUpper(t.text)]) {
+		p.pos++
+		return t.text, nil
+	}
+	return "", p.unexpected(what)
+}
+
+func (p *parser) selectStmt() (*selectStmt, error) {
+	stmt := &selectStmt{}
+	if !p.symbol("*") {
+		for {
+			name, err := p.identifier("column")
+			if err != nil {
+				return nil, err
+			}
+			column := &selectColumn{name: name, alias: name}
+			if p.keyword("AS") {
+				if column.alias, err = p.identifier("alias"); err != nil {
+					return nil, err
+				}
+			}
+			stmt.columns = append(stmt.columns, column)
+			if !p.symbol(",") {
+				break
+			}
+		}
+	}
+	var err error
+	if err = p.expectKeyword("FROM"); err != nil {
+		return nil, err
+	}
+	if stmt.table, err = p.identifier("table"); err != nil {
+		return nil, err
+	}
+	if stmt.where, err = p.where(); err != nil {
+		return nil, err
+	}
+	if p.keyword("ORDER", "BY") {
+		for {
+			column, err := p.identifier("column")
+			if err != nil {
+				return nil, err
+			}
+			order := &orderBy{column: column}
+			if p.keyword("DESC") {
+				order.desc = true
+			} else {
+				p.keyword("ASC")
+			}
+			stmt.orderBy = append(stmt.orderBy, order)
+			if !p.symbol(",") {
+				break
+			}
+		}
+	}
+	if p.keyword("LIMIT") {
+		if stmt.limit, err = p.primary(); err != nil {
+			return nil, err
+		}
+		if p.keyword("OFFSET") {
+			stmt.offset, err = p.primary()
+		} else if p.symbol(",") {
+			// LIMIT offset, count
+			stmt.offset = stmt.limit
+			stmt.limit, err = p.primary()
+		}
+	}
+	return stmt, err
+}
+
+func (p *parser) insertStmt() (*insertStmt, error) {
+	stmt := &insertStmt{}
+	var err error
+	if err = p.expectKeyword("INTO"); err != nil {
+		return nil, err
+	}
+	if stmt.table, err = p.identifier("table"); err != nil {
+		return nil, err
+	}
+	if err = p.expectSymbol("("); err != nil {
+		return nil, err
+	}
+	for {
+		column, err := p.identifier("column")
+		if err != nil {
+			return nil, err
+		}
+		stmt.columns = append(stmt.columns, column)
+		if !p.symbol(",") {
+			break
+		}
+	}
+	if err = p.expectSymbol(")"); err != nil {
+		return nil, err
+	}
+	if err = p.expectKeyword("VALUES"); err != nil {
+		return nil, err
+	}
+	for {
+		if err = p.expectSymbol("("); err != nil {
+			return nil, err
+		}
+		row := []expr{}
+		for {
+			value, err := p.primary()
+			if err != nil {
+				return nil, err
+			}
+			row = append(row, value)
+			if !p.symbol(",") {
+				break
+			}
+		}
+		if err = p.expectSymbol(")"); err != nil {
+			return nil, err
+		}
+		if len(row) != len(stmt.columns) {
+			return nil, invalidSQL("Expect %d values, but got %d", len(stmt.columns), len(row))
+		}
+		stmt.rows = append(stmt.rows, row)
+		if !p.symbol(",") {
+			break
+		}
+	}
+	return stmt, nil
+}
+
+func (p *parser) updateStmt() (*updateStmt, error) {
+	stmt := &updateStmt{}
+	var err error
+	if stmt.table, err = p.identifier("table"); err != nil {
+		return nil, err
+	}
+	if err = p.expectKeyword("SET"); err != nil {
+		return nil, err
+	}
+	for {
+		set := &assignment{}
+		if set.column, err = p.identifier("column"); err != nil {
+			return nil, err
+		}
+		if err = p.expectSymbol("="); err != nil {
+			return nil, err
+		}
+		if set.value, err = p.primary(); err != nil {
+			return nil, err
+		}
+		stmt.sets = append(stmt.sets, set)
+		if !p.symbol(",") {
+			break
+		}
+	}
+	stmt.where, err = p.where()
+	return stmt, err
+}
+
+func (p *parser) deleteStmt() (*deleteStmt, error) {
+	stmt := &deleteStmt{}
+	var err error
+	if err = p.expectKeyword("FROM"); err != nil {
+		return nil, err
+	}
+	if stmt.table, err = p.identifier("table"); err != nil {
+		return nil, err
+	}
+	stmt.where, err = p.where()
+	return stmt, err
+}
+
+func (p *parser) where() (expr, error) {
+	if !p.keyword("WHERE") {
+		return nil, nil
+	}
+	return p.or()
+}
+
+func (p *parser) or() (expr, error) {
+	left, err := p.and()
+	for err == nil && p.keyword("OR") {
+		var right expr
+		if right, err = p.and(); err == nil {
+			left = &binaryExpr{op: "OR", left: left, right: right}
+		}
+	}
+	return left, err
+}
+
+func (p *parser) and() (expr, error) {
+	left, err := p.not()
+	for err == nil && p.keyword("AND") {
+		var right expr
+		if right, err = p.not(); err == nil {
+			left = &binaryExpr{op: "AND", left: left, right: right}
+		}
+	}
+	return left, err
+}
+
+func (p *parser) not() (expr, error) {
+	if p.keyword("NOT") {
+		e, err := p.not()
+		return &notExpr{expr: e}, err
+	}
+	return p.comparison()
+}
+
+// the comparison operators, <> is the same as !=
+var comparisonOps = map[string]string{"=": "=", "!=": "!=", "<>": "!=", "<": "<", "<=": "<=", ">": ">", ">=": ">="}
+
+func (p *parser) comparison() (expr, error) {
+	if p.symbol("(") {
+		e, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		return e, p.expectSymbol(")")
+	}
+	left, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind == tokenSymbol && comparisonOps[t.text] != "" {
+		p.pos++
+		op := comparisonOps[t.text]
+		right, err := p.primary()
+		return &binaryExpr{op: op, left: left, right: right}, err
+	}
+	if p.keyword("IS") {
+		not := p.keyword("NOT")
+		if err = p.expectKeyword("NULL"); err != nil {
+			return nil, err
+		}
+		return &isNullExpr{expr: left, not: not}, nil
+	}
+	not := p.keyword("NOT")
+	if p.keyword("LIKE") {
+		pattern, err := p.primary()
+		return &likeExpr{expr: left, pattern: pattern, not: not}, err
+	}
+	if p.keyword("IN") {
+		e := &inExpr{expr: left, not: not}
+		if err = p.expectSymbol("("); err != nil {
+			return nil, err
+		}
+		for {
+			item, err := p.primary()
+			if err != nil {
+				return nil, err
+			}
+			e.list = append(e.list, item)
+			if !p.symbol(",") {
+				break
+			}
+		}
+		return e, p.expectSymbol(")")
+	}
+	if not {
+		return nil, p.unexpected("LIKE or IN")
+	}
+	return nil, p.unexpected("comparison")
+}
+
+// primary the column, the literal or the placeholder
+func (p *parser) primary() (expr, error) {
+	t := p.peek()
+	switch t.kind {
+	case tokenString:
+		p.pos++
+		return &literal{value: t.text}, nil
+	case tokenNumber:
+		p.pos++
+		n, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, invalidSQL("Invalid number %q at %d", t.text, t.pos)
+		}
+		return &literal{value: n}, nil
+	case tokenPlaceholder:
+		p.pos++
+		index := p.next
+		if t.text == "?" {
+			p.next++
+		} else {
+			n, err := strconv.Atoi(t.text[1:])
+			if err != nil || n < 1 {
+				return nil, invalidSQL("Invalid placeholder %s at %d", t.text, t.pos)
+			}
+			index = n - 1
+		}
+		if index+1 > p.args {
+			p.args = index + 1
+		}
+		return &placeholder{index: index}, nil
+	case tokenSymbol:
+		if next := p.tokens[p.pos+1]; t.text == "-" && next.kind == tokenNumber {
+			p.pos++
+			n, err := p.primary()
+			if err != nil {
+				return nil, err
+			}
+			return &literal{value: -n.(*literal).value.(float64)}, nil
+		}
+	case tokenWord:
+		switch strings.ToUpper(t.text) {
+		case "NULL":
+			p.pos++
+			return &literal{}, nil
+		case "TRUE":
+			p.pos++
+			return &literal{value: true}, nil
+		case "FALSE":
+			p.pos++
+			return &literal{value: false}, nil
+		}
+	}
+	name, err := p.identifier("value")
+	if err != nil {
+		return nil, err
+	}
+	return &columnRef{name: name}, nil
+}
@@ -0,0 +1,361 @@
+package sqldriver
+
+import (
+	"database/sql/driver"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/common"
+	aterror "github.com/apitable/apitable-sdks/apitable.go/lib/common/error"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/datasheet"
+	"math"
+	"sort"
+	"strings"
+)
+
+// table resolve the table name and describe the fields, the name starting with dst is the datasheet id
+func (c *conn) table(name string) (*table, error) {
+	var dst *datasheet.Datasheet
+	if strings.HasPrefix(name, "dst") && !strings.Contains(name, "/") {
+		dst = c.space.Datasheet(name)
+	} else {
+		if c.space.SpaceId == "" {
+			return nil, aterror.NewSDKError(400, "The space of the data source name is required to find the table by path", "ClientError.InvalidArgument")
+		}
+		var err error
+		if dst, err = c.space.ResolvePath(name); err != nil {
+			return nil, err
+		}
+	}
+	fields, err := dst.DescribeFields(nil)
+	if err != nil {
+		return nil, err
+	}
+	t := &table{dst: dst, fields: map[string]*datasheet.DatasheetField{}}
+	for _, field := range fields {
+		if field.Name != nil {
+			t.fields[*field.Name] = field
+			t.names = append(t.names, *field.Name)
+		}
+	}
+	return t, nil
+}
+
+// integer evaluate the limit or offset
+func (e *evaluator) integer(ex expr, what string) (int, error) {
+	value, err := e.eval(ex)
+	if err != nil {
+		return 0, err
+	}
+	n, ok := normalize(value).(float64)
+	if !ok || n < 0 || n != math.Trunc(n) {
+		return 0, invalidSQL("The %s must be a non-negative integer", what)
+	}
+	return int(n), nil
+}
+
+// selectRecords query the records matching the where clause.
+//
+// * the where clause is translated to the filter formula as much as possible, then evaluated by the client.
+// * only the referenced fields are queried, all fields are queried if referenced is nil.
+// * maxRecords is pushed down only if the formula is exact, otherwise the filtered records may be less than the limit.
+func (c *conn) selectRecords(t *table, where expr, e *evaluator, referenced map[string]bool, sorts []*datasheet.Sort, maxRecords int) ([]*datasheet.Record, error) {
+	request := datasheet.NewDescribeRecordRequest()
+	formula, exact := "", where == nil
+	if where != nil {
+		formula, exact = e.formula(where, t)
+	}
+	if formula != "" {
+		request.FilterByFormula = common.StringPtr(formula)
+	}
+	request.Sort = sorts
+	if exact && maxRecords > 0 {
+		request.MaxRecords = common.Int64Ptr(int64(maxRecords))
+	}
+	if referenced != nil {
+		for _, name := range t.names {
+			if referenced[name] {
+				request.Fields = append(request.Fields, common.StringPtr(name))
+			}
+		}
+	}
+	records, err := t.dst.DescribeAllRecords(request)
+	if err != nil || where == nil {
+		return records, err
+	}
+	matched := []*datasheet.Record{}
+	for _, record := range records {
+		record := record
+		e.row = func(column string) interface{} {
+			return t.value(record, column)
+		}
+		ok, err := e.eval(where)
+		if err != nil {
+			return nil, err
+		}
+		if ok == true {
+			matched = append(matched, record)
+		}
+	}
+	e.row = nil
+	return matched, nil
+}
+
+// checkColumns return an error if any column referenced by the expression is not found
+func checkColumns(t *table, ex expr, referenced map[string]bool) error {
+	names := map[string]bool{}
+	columns(ex, names)
+	for name := range names {
+		if err := t.checkColumn(name); err != nil {
+			return err
+		}
+		referenced[name] = true
+	}
+	return nil
+}
+
+func (c *conn) query(query *selectStmt, args []driver.NamedValue) (driver.Rows, error) {
+	t, err := c.table(query.table)
+	if err != nil {
+		return nil, err
+	}
+	e := &evaluator{args: args}
+	selected := query.columns
+	if selected == nil {
+		for _, name := range t.names {
+			selected = append(selected, &selectColumn{name: name, alias: name})
+		}
+	}
+	referenced := map[string]bool{}
+	for _, column := range selected {
+		if err = t.checkColumn(column.name); err != nil {
+			return nil, err
+		}
+		referenced[column.name] = true
+	}
+	if err = checkColumns(t, query.where, referenced); err != nil {
+		return nil, err
+	}
+	// the sort is pushed down if all columns are fields
+	var sorts []*datasheet.Sort
+	pushSort := true
+	for _, order := range query.orderBy {
+		if err = t.checkColumn(order.column); err != nil {
+			return nil, err
+		}
+		referenced[order.column] = true
+		pushSort = pushSort && order.column != RecordIdColumn
+		direction := common.OrderAsc
+		if order.desc {
+			direction = common.OrderDesc
+		}
+		sorts = append(sorts, &datasheet.Sort{Field: common.StringPtr(order.column), Order: common.StringPtr(direction)})
+	}
+	if !pushSort {
+		sorts = nil
+	}
+	offset, limit := 0, -1
+	if query.limit != nil {
+		if limit, err = e.integer(query.limit, "limit"); err != nil {
+			return nil, err
+		}
+	}
+	if query.offset != nil {
+		if offset, err = e.integer(query.offset, "offset"); err != nil {
+			return nil, err
+		}
+	}
+	maxRecords := 0
+	if limit >= 0 && (len(query.orderBy) == 0 || sorts != nil) {
+		maxRecords = limit + offset
+	}
+	if limit == 0 {
+		return &rows{columns: columnNames(selected), types: columnTypes(t, selected)}, nil
+	}
+	if query.columns == nil {
+		referenced = nil
+	}
+	records, err := c.selectRecords(t, query.where, e, referenced, sorts, maxRecords)
+	if err != nil {
+		return nil, err
+	}
+	if len(query.orderBy) > 0 && sorts == nil {
+		sortRecords(t, records, query.orderBy)
+	}
+	if offset > len(records) {
+		offset = len(records)
+	}
+	records = records[offset:]
+	if limit >= 0 && limit < len(records) {
+		records = records[:limit]
+	}
+	r := &rows{columns: columnNames(selected), types: columnTypes(t, selected)}
+	for _, record := range records {
+		values := make([]interface{}, len(selected))
+		for i, column := range selected {
+			values[i] = t.value(record, column.name)
+		}
+		r.values = append(r.values, values)
+	}
+	return r, nil
+}
+
+func columnNames(selected []*selectColumn) []string {
+	names := make([]string, len(selected))
+	for i, column := range selected {
+		names[i] = column.alias
+	}
+	return names
+}
+
+func columnTypes(t *table, selected []*selectColumn) []string {
+	types := make([]string, len(selected))
+	for i, column := range selected {
+		types[i] = t.columnType(column.name)
+	}
+	return types
+}
+
+// sortRecords sort the records by the client, the nil values are the smallest
+func sortRecords(t *table, records []*datasheet.Record, orderBy []*orderBy) {
+	sort.SliceStable(records, func(i, j int) bool {
+		for _, order := range orderBy {
+			a, b := t.value(records[i], order.column), t.value(records[j], order.column)
+			c, ok := compare(a, b)
+			if !ok {
+				c = compareFloat(boolNumber(a != nil), boolNumber(b != nil))
+			}
+			if c == 0 {
+				continue
+			}
+			return (c < 0) != order.desc
+		}
+		return false
+	})
+}
+
+func (c *conn) insert(query *insertStmt, args []driver.NamedValue) (driver.Result, error) {
+	t, err := c.table(query.table)
+	if err != nil {
+		return nil, err
+	}
+	for _, column := range query.columns {
+		if err = t.checkWritable(column); err != nil {
+			return nil, err
+		}
+	}
+	e := &evaluator{args: args}
+	records := make([]*datasheet.Fields, 0, len(query.rows))
+	for _, row := range query.rows {
+		fields := datasheet.Field{}
+		for i, column := range query.columns {
+			value, err := e.eval(row[i])
+			if err != nil {
+				return nil, err
+			}
+			if fields[column], err = t.fieldValue(column, value); err != nil {
+				return nil, err
+			}
+		}
+		records = append(records, &datasheet.Fields{Fields: &fields})
+	}
+	affected := int64(0)
+	for start := 0; start < len(records); start += common.MaxWriteRecords {
+		end := start + common.MaxWriteRecords
+		if end > len(records) {
+			end = len(records)
+		}
+		request := datasheet.NewCreateRecordsRequest()
+		request.Records = records[start:end]
+		created, err := t.dst.CreateRecords(request)
+		if err != nil {
+			return &result{affected: affected}, err
+		}
+		affected += int64(len(created))
+	}
+	return &result{affected: affected}, nil
+}
+
+func (c *conn) update(query *updateStmt, args []driver.NamedValue) (driver.Result, error) {
+	t, err := c.table(query.table)
+	if err != nil {
+		return nil, err
+	}
+	referenced := map[string]bool{}
+	for _, set := range query.sets {
+		if err = t.checkWritable(set.column); err != nil {
+			return nil, err
+		}
+		if err = checkColumns(t, set.value, referenced); err != nil {
+			return nil, err
+		}
+	}
+	if err = checkColumns(t, query.where, referenced); err != nil {
+		return nil, err
+	}
+	e := &evaluator{args: args}
+	records, err := c.selectRecords(t, query.where, e, referenced, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	modified := make([]*datasheet.BaseRecord, 0, len(records))
+	for _, record := range records {
+		record := record
+		e.row = func(column string) interface{} {
+			return t.value(record, column)
+		}
+		fields := datasheet.Field{}
+		for _, set := range query.sets {
+			value, err := e.eval(set.value)
+			if err != nil {
+				return nil, err
+			}
+			if fields[set.column], err = t.fieldValue(set.column, value); err != nil {
+				return nil, err
+			}
+		}
+		modified = append(modified, &datasheet.BaseRecord{RecordId: record.RecordId, Fields: &fields})
+	}
+	affected := int64(0)
+	for start := 0; start < len(modified); start += common.MaxWriteRecords {
+		end := start + common.MaxWriteRecords
+		if end > len(modified) {
+			end = len(modified)
+		}
+		request := datasheet.NewModifyRecordsRequest()
+		request.Records = modified[start:end]
+		if _, err = t.dst.ModifyRecords(request); err != nil {
+			return &result{affected: affected}, err
+		}
+		affected += int64(end - start)
+	}
+	return &result{affected: affected}, nil
+}
+
+func (c *conn) delete(query *deleteStmt, args []driver.NamedValue) (driver.Result, error) {
+	t, err := c.table(query.table)
+	if err != nil {
+		return nil, err
+	}
+	referenced := map[string]bool{}
+	if err = checkColumns(t, query.where, referenced); err != nil {
+		return nil, err
+	}
+	records, err := c.selectRecords(t, query.where, &evaluator{args: args}, referenced, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	affected := int64(0)
+	for start := 0; start < len(records); start += common.MaxWriteRecords {
+		end := start + common.MaxWriteRecords
+		if end > len(records) {
+			end = len(records)
+		}
+		request := datasheet.NewDeleteRecordsRequest()
+		for _, record := range records[start:end] {
+			request.RecordIds = append(request.RecordIds, record.RecordId)
+		}
+		if err = t.dst.DeleteRecords(request); err != nil {
+			return &result{affected: affected}, err
+		}
+		affected += int64(end - start)
+	}
+	return &result{affected: affected}, nil
+}
@@ -0,0 +1,233 @@
+package sqldriver
+
+import (
+	"encoding/json"
+	"fmt"
+	aterror "github.com/apitable/apitable-sdks/apitable.go/lib/common/error"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/datasheet"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RecordIdColumn the pseudo column of the record id, it can be selected and filtered but not written
+const RecordIdColumn = "_recordId"
+
+// the database type names of the columns
+const (
+	ColumnType_Text     = "TEXT"
+	ColumnType_Number   = "NUMBER"
+	ColumnType_Boolean  = "BOOLEAN"
+	ColumnType_DateTime = "DATETIME"
+	// the arrays and objects, such as the members, the attachments and the links, are returned as json strings
+	ColumnType_JSON = "JSON"
+)
+
+// the go types of the column values
+var scanTypes = map[string]reflect.Type{
+	ColumnType_Text:     reflect.TypeOf(""),
+	ColumnType_Number:   reflect.TypeOf(float64(0)),
+	ColumnType_Boolean:  reflect.TypeOf(false),
+	ColumnType_DateTime: reflect.TypeOf(time.Time{}),
+	ColumnType_JSON:     reflect.TypeOf(""),
+}
+
+// table the datasheet and its fields
+type table struct {
+	dst    *datasheet.Datasheet
+	fields map[string]*datasheet.DatasheetField
+	// the field names in the order of the datasheet
+	names []string
+}
+
+func columnNotFound(table string, column string) error {
+	msg := fmt.Sprintf("Column %s not found in table %s", column, table)
+	return aterror.NewSDKError(404, msg, "ClientError.FieldNotFound")
+}
+
+func fieldType(field *datasheet.DatasheetField) datasheet.FieldType {
+	if field == nil || field.Type == nil {
+		return ""
+	}
+	return *field.Type
+}
+
+// columnType the database type name of the column, derived from the field type
+func (t *table) columnType(column string) string {
+	if column == RecordIdColumn {
+		return ColumnType_Text
+	}
+	field := t.fields[column]
+	switch fieldType(field) {
+	case datasheet.FieldType_SingleText, datasheet.FieldType_Text, datasheet.FieldType_SingleSelect,
+		datasheet.FieldType_URL, datasheet.FieldType_Phone:
+		return ColumnType_Text
+	case datasheet.FieldType_Number, datasheet.FieldType_Currency, datasheet.FieldType_Percent,
+		datasheet.FieldType_Rating, datasheet.FieldType_AutoNumber:
+		return ColumnType_Number
+	case datasheet.FieldType_Checkbox:
+		return ColumnType_Boolean
+	case datasheet.FieldType_DateTime, datasheet.FieldType_CreatedTime, datasheet.FieldType_LastModifiedTime:
+		return ColumnType_DateTime
+	case datasheet.FieldType_Formula:
+		if property := field.FormulaFieldProperty(); property != nil {
+			return valueColumnType(property.ValueType)
+		}
+	case datasheet.FieldType_MagicLookUp:
+		if property := field.MagicLookUpFieldProperty(); property != nil {
+			return valueColumnType(property.ValueType)
+		}
+	}
+	return ColumnType_JSON
+}
+
+func valueColumnType(valueType *datasheet.ValueType) string {
+	if valueType == nil {
+		return ColumnType_JSON
+	}
+	switch *valueType {
+	case datasheet.ValueType_String:
+		return ColumnType_Text
+	case datasheet.ValueType_Number:
+		return ColumnType_Number
+	case datasheet.ValueType_Boolean:
+		return ColumnType_Boolean
+	case datasheet.ValueType_DateTime:
+		return ColumnType_DateTime
+	}
+	return ColumnType_JSON
+}
+
+// checkColumn return an error if the column is not a field or the record id
+func (t *table) checkColumn(column string) error {
+	if _, ok := t.fields[column]; ok || column == RecordIdColumn {
+		return nil
+	}
+	return columnNotFound(t.dst.DatasheetId, column)
+}
+
+// checkWritable return an error if the column can't be written
+func (t *table) checkWritable(column string) error {
+	if err := t.checkColumn(column); err != nil {
+		return err
+	}
+	if column == RecordIdColumn || fieldType(t.fields[column]).IsComputed() {
+		msg := fmt.Sprintf("Column %s of table %s is read only", column, t.dst.DatasheetId)
+		return aterror.NewSDKError(400, msg, "ClientError.InvalidArgument")
+	}
+	return nil
+}
+
+// value get the column value of the record, converted to the go type of the column type
+func (t *table) value(record *datasheet.Record, column string) interface{} {
+	if record.BaseRecord == nil {
+		return nil
+	}
+	if column == RecordIdColumn {
+		if record.RecordId == nil {
+			return nil
+		}
+		return *record.RecordId
+	}
+	var raw interface{}
+	if record.Fields != nil {
+		raw = (*record.Fields)[column]
+	}
+	return columnValue(t.columnType(column), raw)
+}
+
+func columnValue(columnType string, raw interface{}) interface{} {
+	switch columnType {
+	case ColumnType_Boolean:
+		// the unchecked checkbox is empty
+		b, _ := raw.(bool)
+		return b
+	case ColumnType_Number:
+		if n, ok := raw.(float64); ok {
+			return n
+		}
+	case ColumnType_DateTime:
+		switch v := raw.(type) {
+		case float64:
+			return time.Unix(0, int64(v)*int64(time.Millisecond)).UTC()
+		case string:
+			if parsed, ok := parseTime(v); ok {
+				return parsed
+			}
+		}
+	case ColumnType_Text:
+		switch v := raw.(type) {
+		case string:
+			return v
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		case bool:
+			return strconv.FormatBool(v)
+		}
+	}
+	if raw == nil {
+		return nil
+	}
+	if s, ok := raw.(string); ok && columnType != ColumnType_JSON {
+		return s
+	}
+	b, _ := json.Marshal(raw)
+	return string(b)
+}
+
+// the time layouts of the string values compared with the datetime columns
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+func parseTime(s string) (time.Time, bool) {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, strings.TrimSpace(s)); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// fieldValue convert the sql value to the field value to write
+//
+// * time.Time is converted to the timestamp in milliseconds, []byte is converted to string.
+// * the json string of the JSON columns is decoded, such as: ["opt1","opt2"]
+func (t *table) fieldValue(column string, value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case time.Time:
+		return v.UnixNano() / int64(time.Millisecond), nil
+	case []byte:
+		return t.fieldValue(column, string(v))
+	case string:
+		columnType := t.columnType(column)
+		trimmed := strings.TrimSpace(v)
+		if columnType == ColumnType_JSON && (strings.HasPrefix(trimmed, "[") || strings.HasPrefix(trimmed, "{")) {
+			var decoded interface{}
+			if err := json.Unmarshal([]byte(v), &decoded); err != nil {
+				msg := fmt.Sprintf("Invalid json value of column %s: %v", column, err)
+				return nil, aterror.NewSDKError(400, msg, "ClientError.ConvertError")
+			}
+			return decoded, nil
+		}
+		if columnType == ColumnType_DateTime {
+			if parsed, ok := parseTime(v); ok {
+				return parsed.UnixNano() / int64(time.Millisecond), nil
+			}
+		}
+		if columnType == ColumnType_Number {
+			if n, err := strconv.ParseFloat(v, 64); err == nil {
+				return n, nil
+			}
+		}
+		return v, nil
+	}
+	return value, nil
+}
@@ -0,0 +1,730 @@
+// Package sqlsync provides the two-way sync between a sql database table and a datasheet
+package sqlsync
+
+import (
+	"context"
+	"crypto/sha1"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/common"
+	aterror "github.com/apitable/apitable-sdks/apitable.go/lib/common/error"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/datasheet"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Dialect the sql syntax differences of the databases
+type Dialect struct {
+	// the placeholder of the n-th argument, n starts from 1
+	Placeholder func(n int) string
+	// quote the table or column name
+	Quote func(name string) string
+}
+
+func questionPlaceholder(int) string {
+	return "?"
+}
+
+func dollarPlaceholder(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+func doubleQuote(name string) string {
+	return `"` + strings.Replace(name, `"`, `""`, -1) + `"`
+}
+
+func backQuote(name string) string {
+	return "`" + strings.Replace(name, "`", "``", -1) + "`"
+}
+
+// the dialects of the common databases
+var (
+	Dialect_SQLite   = Dialect{Placeholder: questionPlaceholder, Quote: doubleQuote}
+	Dialect_MySQL    = Dialect{Placeholder: questionPlaceholder, Quote: backQuote}
+	Dialect_Postgres = Dialect{Placeholder: dollarPlaceholder, Quote: doubleQuote}
+)
+
+// ConflictPolicy decide the winner when both the row and the record changed since the last sync
+type ConflictPolicy string
+
+const (
+	// the record in the datasheet wins
+	ConflictPolicy_RemoteWins ConflictPolicy = "RemoteWins"
+	// the row in the database wins
+	ConflictPolicy_LocalWins ConflictPolicy = "LocalWins"
+	// the side modified later wins, by UpdatedAtColumn and ModifiedTimeField
+	ConflictPolicy_LastWriteWins ConflictPolicy = "LastWriteWins"
+)
+
+// Column map a table column to a datasheet field
+type Column struct {
+	// the column name. required: yes.
+	Column string
+	// the field name, the default is the column name
+	Field string
+	// convert the column value to the field value, the default converts []byte to string and time to timestamp
+	ToField func(value interface{}) (interface{}, error)
+	// convert the field value to the column value, the default converts integral numbers to int64 and arrays to json
+	ToColumn func(value interface{}) (interface{}, error)
+}
+
+// Options the options of the syncer
+type Options struct {
+	// the table name. required: yes.
+	Table string
+	// the column identifying the rows, usually the primary key. required: yes.
+	KeyColumn string
+	// the field holding the key, the default is the key column name
+	KeyField string
+	// the mapped columns, the key column is not included. required: yes.
+	Columns []*Column
+	// the conflict policy, the default is ConflictPolicy_RemoteWins
+	Policy ConflictPolicy
+	// the column of the row modification time, required for ConflictPolicy_LastWriteWins
+	UpdatedAtColumn string
+	// the name of the LastModifiedTime field, required for ConflictPolicy_LastWriteWins
+	ModifiedTimeField string
+	// the sql dialect, the default is Dialect_SQLite
+	Dialect *Dialect
+	// persist the sync state, so the deletions are synced. required: no.
+	// without the state, the deletions are not synced and the differences are all treated as conflicts.
+	State StateStore
+	// compute the changes only, neither side nor the state is written
+	DryRun bool
+}
+
+// Action the change applied by the sync
+type Action string
+
+const (
+	Action_CreateRecord Action = "CreateRecord"
+	Action_UpdateRecord Action = "UpdateRecord"
+	Action_DeleteRecord Action = "DeleteRecord"
+	Action_InsertRow    Action = "InsertRow"
+	Action_UpdateRow    Action = "UpdateRow"
+	Action_DeleteRow    Action = "DeleteRow"
+)
+
+// Change one change applied by the sync
+type Change struct {
+	Action Action `json:"action"`
+	// the key of the row
+	Key string `json:"key"`
+	// such as: `rec*****`, empty for the created records in dry run
+	RecordId string `json:"recordId,omitempty"`
+	// the written values by field name, nil for deletions
+	Values map[string]interface{} `json:"values,omitempty"`
+	// the raw key value of the row or the record
+	keyValue interface{}
+}
+
+// Conflict the row and the record both changed since the last sync
+type Conflict struct {
+	// the key of the row
+	Key string `json:"key"`
+	// the winner side, local or remote
+	Winner string `json:"winner"`
+	// why the winner is chosen
+	Reason string `json:"reason"`
+}
+
+// Report the result of one sync
+type Report struct {
+	DryRun    bool        `json:"dryRun"`
+	Changes   []*Change   `json:"changes"`
+	Conflicts []*Conflict `json:"conflicts"`
+	// the count of the rows equal to the records
+	Unchanged int `json:"unchanged"`
+}
+
+// Count the count of the changes of the action
+func (r *Report) Count(action Action) int {
+	count := 0
+	for _, change := range r.Changes {
+		if change.Action == action {
+			count++
+		}
+	}
+	return count
+}
+
+// Syncer sync the rows of a table with the records of a datasheet in both directions
+type Syncer struct {
+	db        *sql.DB
+	datasheet *datasheet.Datasheet
+	options   Options
+}
+
+// NewSyncer init syncer instance
+func NewSyncer(db *sql.DB, dst *datasheet.Datasheet, options *Options) (*Syncer, error) {
+	if db == nil || dst == nil || options == nil {
+		return nil, aterror.NewSDKError(400, "The db, datasheet and options are required", "ClientError.InvalidArgument")
+	}
+	s := &Syncer{db: db, datasheet: dst, options: *options}
+	opts := &s.options
+	if opts.Table == "" || opts.KeyColumn == "" || len(opts.Columns) == 0 {
+		return nil, aterror.NewSDKError(400, "The table, key column and columns are required", "ClientError.InvalidArgument")
+	}
+	if opts.KeyField == "" {
+		opts.KeyField = opts.KeyColumn
+	}
+	if opts.Policy == "" {
+		opts.Policy = ConflictPolicy_RemoteWins
+	}
+	switch opts.Policy {
+	case ConflictPolicy_RemoteWins, ConflictPolicy_LocalWins:
+	case ConflictPolicy_LastWriteWins:
+		if opts.UpdatedAtColumn == "" || opts.ModifiedTimeField == "" {
+			msg := "The updated at column and modified time field are required for LastWriteWins"
+			return nil, aterror.NewSDKError(400, msg, "ClientError.InvalidArgument")
+		}
+	default:
+		msg := fmt.Sprintf("Unsupported conflict policy: %s", opts.Policy)
+		return nil, aterror.NewSDKError(400, msg, "ClientError.InvalidArgument")
+	}
+	if opts.Dialect == nil {
+		opts.Dialect = &Dialect_SQLite
+	}
+	columns := make([]*Column, len(opts.Columns))
+	for i, column := range opts.Columns {
+		if column == nil || column.Column == "" {
+			return nil, aterror.NewSDKError(400, "The column name is required", "ClientError.InvalidArgument")
+		}
+		copied := *column
+		if copied.Field == "" {
+			copied.Field = copied.Column
+		}
+		if copied.ToField == nil {
+			copied.ToField = defaultToField
+		}
+		if copied.ToColumn == nil {
+			copied.ToColumn = defaultToColumn
+		}
+		columns[i] = &copied
+	}
+	opts.Columns = columns
+	return s, nil
+}
+
+// row the mapped values of one side
+type row struct {
+	key string
+	// the raw key value
+	keyValue interface{}
+	// the values by field name, converted to field values for the local rows
+	values map[string]interface{}
+	// the modification time. such as: timestamp, 0 if unknown
+	modifiedAt int64
+	recordId   string
+}
+
+// Sync compare the rows with the records, and apply the changes to both sides.
+//
+// * the side changed since the last sync overwrites the other, the conflicts are resolved by Policy.
+// * the deletions are synced only if the key is found in the sync state.
+// * the records are written first, the state is saved only if both sides are written.
+func (s *Syncer) Sync(ctx context.Context) (*Report, error) {
+	state := &State{}
+	if s.options.State != nil {
+		saved, err := s.options.State.Load()
+		if err != nil {
+			return nil, err
+		}
+		if saved != nil {
+			state = saved
+		}
+	}
+	if state.Rows == nil {
+		state.Rows = map[string]*RowState{}
+	}
+	locals, err := s.loadRows(ctx)
+	if err != nil {
+		return nil, err
+	}
+	remotes, err := s.loadRecords()
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(locals)+len(remotes))
+	for key := range locals {
+		keys = append(keys, key)
+	}
+	for key := range remotes {
+		if _, ok := locals[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	report := &Report{DryRun: s.options.DryRun, Changes: []*Change{}, Conflicts: []*Conflict{}}
+	plan := &plan{}
+	next := &State{Rows: map[string]*RowState{}, SyncedAt: time.Now().UnixNano() / int64(time.Millisecond)}
+	for _, key := range keys {
+		local, remote, saved := locals[key], remotes[key], state.Rows[key]
+		s.compare(key, local, remote, saved, plan, report, next)
+	}
+	if s.options.DryRun {
+		return report, nil
+	}
+	if err = s.applyRecords(plan, next); err != nil {
+		return report, err
+	}
+	if err = s.applyRows(ctx, plan); err != nil {
+		return report, err
+	}
+	if s.options.State != nil {
+		if err = s.options.State.Save(next); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// plan the changes to apply
+type plan struct {
+	creates []*Change
+	updates []*Change
+	deletes []*Change
+	rows    []*Change
+}
+
+func (s *Syncer) compare(key string, local *row, remote *row, saved *RowState, plan *plan, report *Report, next *State) {
+	var localHash, remoteHash string
+	if local != nil {
+		localHash = hashValues(local.values)
+	}
+	if remote != nil {
+		remoteHash = hashValues(remote.values)
+	}
+	localChanged := local != nil && (saved == nil || localHash != saved.LocalHash)
+	remoteChanged := remote != nil && (saved == nil || remoteHash != saved.RemoteHash)
+	keep := func(recordId string) {
+		next.Rows[key] = &RowState{RecordId: recordId, LocalHash: localHash, RemoteHash: remoteHash}
+	}
+	switch {
+	case local != nil && remote != nil:
+		if localHash == remoteHash {
+			report.Unchanged++
+			keep(remote.recordId)
+			return
+		}
+		push := localChanged && !remoteChanged
+		if localChanged == remoteChanged {
+			push = s.resolve(key, local, remote, report)
+		}
+		if push {
+			change := &Change{Action: Action_UpdateRecord, Key: key, RecordId: remote.recordId, Values: local.values}
+			plan.updates = append(plan.updates, change)
+			report.Changes = append(report.Changes, change)
+			remoteHash = localHash
+		} else {
+			change := &Change{Action: Action_UpdateRow, Key: key, RecordId: remote.recordId, Values: remote.values, keyValue: local.keyValue}
+			plan.rows = append(plan.rows, change)
+			report.Changes = append(report.Changes, change)
+			localHash = remoteHash
+		}
+		keep(remote.recordId)
+	case local != nil:
+		// the record is deleted since the last sync, unless the row is edited meanwhile.
+		if saved != nil && (!localChanged || !s.resolve(key, local, nil, report)) {
+			change := &Change{Action: Action_DeleteRow, Key: key, RecordId: saved.RecordId, keyValue: local.keyValue}
+			plan.rows = append(plan.rows, change)
+			report.Changes = append(report.Changes, change)
+			return
+		}
+		values := copyValues(local.values)
+		values[s.options.KeyField] = defaultValue(local.keyValue)
+		change := &Change{Action: Action_CreateRecord, Key: key, Values: values}
+		plan.creates = append(plan.creates, change)
+		report.Changes = append(report.Changes, change)
+		remoteHash = localHash
+		keep("")
+	case remote != nil:
+		// the row is deleted since the last sync, unless the record is edited meanwhile.
+		if saved != nil && (!remoteChanged || s.resolve(key, nil, remote, report)) {
+			change := &Change{Action: Action_DeleteRecord, Key: key, RecordId: remote.recordId}
+			plan.deletes = append(plan.deletes, change)
+			report.Changes = append(report.Changes, change)
+			return
+		}
+		change := &Change{Action: Action_InsertRow, Key: key, RecordId: remote.recordId, Values: remote.values, keyValue: remote.keyValue}
+		plan.rows = append(plan.rows, change)
+		report.Changes = append(report.Changes, change)
+		localHash = remoteHash
+		keep(remote.recordId)
+	}
+}
+
+// resolve decide the winner of the conflict, return true if the local row wins.
+// the missing side is deleted since the last sync.
+func (s *Syncer) resolve(key string, local *row, remote *row, report *Report) bool {
+	conflict := &Conflict{Key: key}
+	localWins := false
+	switch s.options.Policy {
+	case ConflictPolicy_LocalWins:
+		localWins = true
+		conflict.Reason = "policy local wins"
+	case ConflictPolicy_LastWriteWins:
+		switch {
+		case local == nil || remote == nil:
+			// the deletion time is unknown, keep the edited side.
+			localWins = local != nil
+			conflict.Reason = "edit wins over deletion"
+		case local.modifiedAt == 0 || remote.modifiedAt == 0:
+			conflict.Reason = "modification time unknown, remote wins"
+		default:
+			localWins = local.modifiedAt > remote.modifiedAt
+			conflict.Reason = "last write wins"
+		}
+	default:
+		conflict.Reason = "policy remote wins"
+	}
+	conflict.Winner = "remote"
+	if localWins {
+		conflict.Winner = "local"
+	}
+	report.Conflicts = append(report.Conflicts, conflict)
+	return localWins
+}
+
+func (s *Syncer) loadRows(ctx context.Context) (map[string]*row, error) {
+	quote := s.options.Dialect.Quote
+	names := []string{quote(s.options.KeyColumn)}
+	for _, column := range s.options.Columns {
+		names = append(names, quote(column.Column))
+	}
+	if s.options.UpdatedAtColumn != "" {
+		names = append(names, quote(s.options.UpdatedAtColumn))
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s", strings.Join(names, ", "), quote(s.options.Table))
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	locals := map[string]*row{}
+	for rows.Next() {
+		values := make([]interface{}, len(names))
+		dest := make([]interface{}, len(names))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err = rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		local := &row{keyValue: values[0], key: canonical(defaultValue(values[0])), values: map[string]interface{}{}}
+		for i, column := range s.options.Columns {
+			v, err := column.ToField(values[i+1])
+			if err != nil {
+				msg := fmt.Sprintf("Fail to convert column %s of row %s because %s", column.Column, local.key, err)
+				return nil, aterror.NewSDKError(400, msg, "ClientError.ConvertError")
+			}
+			local.values[column.Field] = v
+		}
+		if s.options.UpdatedAtColumn != "" {
+			local.modifiedAt = timestamp(values[len(values)-1])
+		}
+		if _, ok := locals[local.key]; ok {
+			msg := fmt.Sprintf("Duplicate key in table %s: %s", s.options.Table, local.key)
+			return nil, aterror.NewSDKError(409, msg, "ClientError.DuplicateKey")
+		}
+		locals[local.key] = local
+	}
+	return locals, rows.Err()
+}
+
+func (s *Syncer) loadRecords() (map[string]*row, error) {
+	fields := []string{s.options.KeyField}
+	for _, column := range s.options.Columns {
+		fields = append(fields, column.Field)
+	}
+	if s.options.ModifiedTimeField != "" {
+		fields = append(fields, s.options.ModifiedTimeField)
+	}
+	request := datasheet.NewDescribeRecordRequest()
+	request.Fields = common.StringPtrs(fields)
+	records, err := s.datasheet.DescribeAllRecords(request)
+	if err != nil {
+		return nil, err
+	}
+	remotes := map[string]*row{}
+	for _, record := range records {
+		if record.BaseRecord == nil || record.RecordId == nil {
+			continue
+		}
+		fields := datasheet.Field{}
+		if record.Fields != nil {
+			fields = *record.Fields
+		}
+		keyValue := fields[s.options.KeyField]
+		if keyValue == nil {
+			// the records without key are not synced, such as the blank rows added by hand.
+			continue
+		}
+		remote := &row{keyValue: keyValue, key: canonical(keyValue), recordId: *record.RecordId, values: map[string]interface{}{}}
+		for _, column := range s.options.Columns {
+			remote.values[column.Field] = fields[column.Field]
+		}
+		if s.options.ModifiedTimeField != "" {
+			remote.modifiedAt = timestamp(fields[s.options.ModifiedTimeField])
+		}
+		if _, ok := remotes[remote.key]; ok {
+			msg := fmt.Sprintf("Duplicate key in datasheet %s: %s", s.datasheet.DatasheetId, remote.key)
+			return nil, aterror.NewSDKError(409, msg, "ClientError.DuplicateKey")
+		}
+		remotes[remote.key] = remote
+	}
+	return remotes, nil
+}
+
+// applyRecords write the records in batches, and record the ids of the created records in the state.
+func (s *Syncer) applyRecords(plan *plan, next *State) error {
+	for _, changes := range batches(plan.creates) {
+		request := datasheet.NewCreateRecordsRequest()
+		for _, change := range changes {
+			request.Records = append(request.Records, &datasheet.Fields{Fields: toFields(change.Values)})
+		}
+		records, err := s.datasheet.CreateRecords(request)
+		if err != nil {
+			return err
+		}
+		for i, record := range records {
+			if i < len(changes) && record.BaseRecord != nil && record.RecordId != nil {
+				changes[i].RecordId = *record.RecordId
+				next.Rows[changes[i].Key].RecordId = *record.RecordId
+			}
+		}
+	}
+	for _, changes := range batches(plan.updates) {
+		request := datasheet.NewModifyRecordsRequest()
+		for _, change := range changes {
+			request.Records = append(request.Records, &datasheet.BaseRecord{RecordId: common.StringPtr(change.RecordId), Fields: toFields(change.Values)})
+		}
+		if _, err := s.datasheet.ModifyRecords(request); err != nil {
+			return err
+		}
+	}
+	for _, changes := range batches(plan.deletes) {
+		request := datasheet.NewDeleteRecordsRequest()
+		for _, change := range changes {
+			request.RecordIds = append(request.RecordIds, common.StringPtr(change.RecordId))
+		}
+		if err := s.datasheet.DeleteRecords(request); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// batches split the changes by the max count of records per request
+func batches(changes []*Change) [][]*Change {
+	result := [][]*Change{}
+	for start := 0; start < len(changes); start += common.MaxWriteRecords {
+		end := start + common.MaxWriteRecords
+		if end > len(changes) {
+			end = len(changes)
+		}
+		result = append(result, changes[start:end])
+	}
+	return result
+}
+
+// applyRows write the rows in one transaction
+func (s *Syncer) applyRows(ctx context.Context, plan *plan) error {
+	if len(plan.rows) == 0 {
+		return nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	for _, change := range plan.rows {
+		query, args, err := s.rowStatement(change)
+		if err == nil {
+			_, err = tx.ExecContext(ctx, query, args...)
+		}
+		if err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *Syncer) rowStatement(change *Change) (query string, args []interface{}, err error) {
+	quote, placeholder := s.options.Dialect.Quote, s.options.Dialect.Placeholder
+	table := quote(s.options.Table)
+	keyValue, err := defaultToColumn(change.keyValue)
+	if err != nil {
+		return "", nil, err
+	}
+	if change.Action == Action_DeleteRow {
+		query = fmt.Sprintf("DELETE FROM %s WHERE %s = %s", table, quote(s.options.KeyColumn), placeholder(1))
+		return query, []interface{}{keyValue}, nil
+	}
+	names := []string{}
+	for _, column := range s.options.Columns {
+		v, err := column.ToColumn(change.Values[column.Field])
+		if err != nil {
+			msg := fmt.Sprintf("Fail to convert field %s of record %s because %s", column.Field, change.RecordId, err)
+			return "", nil, aterror.NewSDKError(400, msg, "ClientError.ConvertError")
+		}
+		names = append(names, quote(column.Column))
+		args = append(args, v)
+	}
+	if change.Action == Action_InsertRow {
+		names = append(names, quote(s.options.KeyColumn))
+		args = append(args, keyValue)
+		placeholders := make([]string, len(names))
+		for i := range names {
+			placeholders[i] = placeholder(i + 1)
+		}
+		query = fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, strings.Join(names, ", "), strings.Join(placeholders, ", "))
+		return query, args, nil
+	}
+	sets := make([]string, len(names))
+	for i, name := range names {
+		sets[i] = fmt.Sprintf("%s = %s", name, placeholder(i+1))
+	}
+	args = append(args, keyValue)
+	query = fmt.Sprintf("UPDATE %s SET %s WHERE %s = %s", table, strings.Join(sets, ", "), quote(s.options.KeyColumn), placeholder(len(args)))
+	return query, args, nil
+}
+
+// defaultValue convert the scanned column value to the field value
+func defaultValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.UnixNano() / int64(time.Millisecond)
+	}
+	return value
+}
+
+func defaultToField(value interface{}) (interface{}, error) {
+	return defaultValue(value), nil
+}
+
+func defaultToColumn(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return int64(v), nil
+		}
+	case []interface{}, map[string]interface{}:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+	}
+	return value, nil
+}
+
+// canonical format the value, so the equal values of the database and the datasheet have the same format
+func canonical(value interface{}) string {
+	switch v := defaultValue(value).(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case int:
+		return strconv.FormatInt(int64(v), 10)
+	case int32:
+		return strconv.FormatInt(int64(v), 10)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 64)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+}
+
+// isEmptyValue the value is empty, the api leaves the false and the empty cells out of the records.
+//
+// * the empty array and object written to the column by defaultToColumn are empty too.
+func isEmptyValue(value interface{}) bool {
+	switch v := defaultValue(value).(type) {
+	case nil:
+		return true
+	case bool:
+		return !v
+	case string:
+		return v == "" || v == "[]" || v == "{}"
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+// hashValues the content hash of the values, the keys are sorted by json and the empty values are hashed as nil.
+func hashValues(values map[string]interface{}) string {
+	formatted := make(map[string]string, len(values))
+	for key, value := range values {
+		if isEmptyValue(value) {
+			formatted[key] = ""
+			continue
+		}
+		formatted[key] = canonical(value)
+	}
+	b, _ := json.Marshal(formatted)
+	sum := sha1.Sum(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func toFields(values map[string]interface{}) *datasheet.Field {
+	fields := make(datasheet.Field, len(values))
+	for key, value := range values {
+		fields[key] = value
+	}
+	return &fields
+}
+
+func copyValues(values map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(values)+1)
+	for key, value := range values {
+		copied[key] = value
+	}
+	return copied
+}
+
+// timestamp parse the modification time, such as: time, timestamp in seconds or milliseconds, RFC3339 string.
+func timestamp(value interface{}) int64 {
+	var ms int64
+	switch v := value.(type) {
+	case time.Time:
+		return v.UnixNano() / int64(time.Millisecond)
+	case int64:
+		ms = v
+	case float64:
+		ms = int64(v)
+	case []byte:
+		return timestamp(string(v))
+	case string:
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999", "2006-01-02 15:04:05"} {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t.UnixNano() / int64(time.Millisecond)
+			}
+		}
+		ms, _ = strconv.ParseInt(v, 10, 64)
+	}
+	if ms > 0 && ms < 1e11 {
+		// the timestamp in seconds
+		ms *= 1000
+	}
+	return ms
+}
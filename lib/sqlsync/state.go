@@ -0,0 +1,80 @@
+package sqlsync
+
+import (
+	"encoding/json"
+	"fmt"
+	aterror "github.com/apitable/apitable-sdks/apitable.go/lib/common/error"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// RowState the state of one row after the last sync
+type RowState struct {
+	// such as: `rec*****`
+	RecordId string `json:"recordId"`
+	// the content hash of the mapped columns
+	LocalHash string `json:"localHash"`
+	// the content hash of the mapped fields
+	RemoteHash string `json:"remoteHash"`
+}
+
+// State the sync state, used to tell which side changed since the last sync
+type State struct {
+	// the rows by key
+	Rows map[string]*RowState `json:"rows"`
+	// the time of the last sync. such as: timestamp
+	SyncedAt int64 `json:"syncedAt"`
+}
+
+// StateStore persist the sync state
+type StateStore interface {
+	// Load get the saved state, nil if not saved
+	Load() (*State, error)
+	// Save save the state
+	Save(state *State) error
+}
+
+// FileStateStore persist the sync state to a json file
+type FileStateStore struct {
+	FilePath string
+}
+
+// NewFileStateStore init file state store instance
+func NewFileStateStore(filePath string) *FileStateStore {
+	return &FileStateStore{FilePath: filePath}
+}
+
+func (s *FileStateStore) Load() (*State, error) {
+	b, err := ioutil.ReadFile(s.FilePath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		msg := fmt.Sprintf("Fail to read sync state because %s", err)
+		return nil, aterror.NewSDKError(500, msg, "ClientError.FileReadError")
+	}
+	state := &State{}
+	if err = json.Unmarshal(b, state); err != nil {
+		msg := fmt.Sprintf("Fail to parse sync state because %s", err)
+		return nil, aterror.NewSDKError(500, msg, "ClientError.ParseJsonError")
+	}
+	return state, nil
+}
+
+func (s *FileStateStore) Save(state *State) error {
+	b, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	// write to a temporary file first, so a crash never leaves a broken state.
+	tmp := filepath.Join(filepath.Dir(s.FilePath), "."+filepath.Base(s.FilePath)+".tmp")
+	if err = ioutil.WriteFile(tmp, b, 0644); err == nil {
+		err = os.Rename(tmp, s.FilePath)
+	}
+	if err != nil {
+		msg := fmt.Sprintf("Fail to write sync state because %s", err)
+		return aterror.NewSDKError(500, msg, "ClientError.FileWriteError")
+	}
+	return nil
+}
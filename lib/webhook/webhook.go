@@ -0,0 +1,276 @@
+// Package webhook provides the http handler receiving the automation requests
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/datasheet"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultSignatureHeader the default header carrying the signature of the request body
+const DefaultSignatureHeader = "X-Vika-Signature"
+
+// signaturePrefix the optional prefix of the signature, such as: sha256=<hex>
+const signaturePrefix = "sha256="
+
+// the default max size of the request body
+const defaultMaxBodySize = 5 << 20
+
+// the default time to remember the delivered events
+const defaultDedupTTL = 24 * time.Hour
+
+// TriggerType the automation trigger type
+type TriggerType string
+
+// all automation trigger types
+const (
+	TriggerType_RecordCreated           TriggerType = "RecordCreated"
+	TriggerType_RecordMatchesConditions TriggerType = "RecordMatchesConditions"
+	TriggerType_FormSubmitted           TriggerType = "FormSubmitted"
+	// match all trigger types when registering handlers
+	TriggerType_Any TriggerType = "*"
+)
+
+// AnyDatasheet match all datasheets when registering handlers
+const AnyDatasheet = "*"
+
+// Event the automation payload
+type Event struct {
+	// the unique id of the event, used to deduplicate redeliveries
+	EventId string `json:"eventId"`
+	// such as: `dst*****`
+	DatasheetId string `json:"datasheetId"`
+	// the trigger of the automation
+	TriggerType TriggerType `json:"triggerType"`
+	// the time the automation is triggered. such as: timestamp
+	Timestamp int64 `json:"timestamp"`
+	// the record triggering the automation
+	Record *datasheet.Record `json:"record,omitempty"`
+	// the records triggering the automation, for batch payloads
+	Records []*datasheet.Record `json:"records,omitempty"`
+	// the raw request body
+	Raw json.RawMessage `json:"-"`
+}
+
+// AllRecords get the record and the records of the event
+func (e *Event) AllRecords() []*datasheet.Record {
+	records := make([]*datasheet.Record, 0, len(e.Records)+1)
+	if e.Record != nil {
+		records = append(records, e.Record)
+	}
+	return append(records, e.Records...)
+}
+
+// HandlerFunc handle the event, return error to respond 500, so the automation redelivers it.
+type HandlerFunc func(ctx context.Context, event *Event) error
+
+// Options the options of the receiver
+type Options struct {
+	// the header carrying the signature, the default is X-Vika-Signature
+	SignatureHeader string
+	// the max size of the request body, the default is 5MB
+	MaxBodySize int64
+	// the time to remember the delivered events, the default is 24 hours
+	DedupTTL time.Duration
+}
+
+type handlerKey struct {
+	datasheetId string
+	triggerType TriggerType
+}
+
+// Receiver the http handler verifying, deduplicating and dispatching the automation requests
+type Receiver struct {
+	secret   []byte
+	options  Options
+	mu       sync.RWMutex
+	handlers map[handlerKey]HandlerFunc
+	dedup    *deduplicator
+}
+
+// NewReceiver init receiver instance with the shared secret, the signature is not verified if the secret is empty.
+func NewReceiver(secret string, options *Options) *Receiver {
+	r := &Receiver{
+		secret:   []byte(secret),
+		handlers: map[handlerKey]HandlerFunc{},
+	}
+	if options != nil {
+		r.options = *options
+	}
+	if r.options.SignatureHeader == "" {
+		r.options.SignatureHeader = DefaultSignatureHeader
+	}
+	if r.options.MaxBodySize <= 0 {
+		r.options.MaxBodySize = defaultMaxBodySize
+	}
+	if r.options.DedupTTL <= 0 {
+		r.options.DedupTTL = defaultDedupTTL
+	}
+	r.dedup = newDeduplicator(r.options.DedupTTL)
+	return r
+}
+
+// Handle register the handler for the datasheet and trigger type, AnyDatasheet and TriggerType_Any are wildcards.
+//
+// * the most specific handler is used: datasheet and trigger, datasheet, trigger, then wildcards.
+func (r *Receiver) Handle(datasheetId string, triggerType TriggerType, handler HandlerFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers[handlerKey{datasheetId, triggerType}] = handler
+}
+
+func (r *Receiver) handler(event *Event) HandlerFunc {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	keys := []handlerKey{
+		{event.DatasheetId, event.TriggerType},
+		{event.DatasheetId, TriggerType_Any},
+		{AnyDatasheet, event.TriggerType},
+		{AnyDatasheet, TriggerType_Any},
+	}
+	for _, key := range keys {
+		if handler, ok := r.handlers[key]; ok {
+			return handler
+		}
+	}
+	return nil
+}
+
+// Sign compute the signature of the body, such as: sha256=<hex>
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify check the signature of the body, the prefix sha256= is optional.
+func (r *Receiver) Verify(signature string, body []byte) bool {
+	if len(r.secret) == 0 {
+		return true
+	}
+	signature = strings.TrimPrefix(strings.TrimSpace(signature), signaturePrefix)
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, r.secret)
+	_, _ = mac.Write(body)
+	return hmac.Equal(expected, mac.Sum(nil))
+}
+
+// ParseEvent decode the request body into the event
+func ParseEvent(body []byte) (*Event, error) {
+	event := &Event{}
+	if err := json.Unmarshal(body, event); err != nil {
+		return nil, err
+	}
+	if event.EventId == "" {
+		// use the content hash as the event id, so the same redelivered payload is deduplicated.
+		sum := sha256.Sum256(body)
+		event.EventId = hex.EncodeToString(sum[:])
+	}
+	event.Raw = body
+	return event, nil
+}
+
+func writeResult(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"code":    status,
+		"success": status == http.StatusOK,
+		"message": message,
+	})
+}
+
+// ServeHTTP verify the signature, decode the payload, deduplicate the redeliveries and dispatch the event.
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		writeResult(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, req.Body, r.options.MaxBodySize))
+	if err != nil {
+		writeResult(w, http.StatusRequestEntityTooLarge, "request body too large")
+		return
+	}
+	if !r.Verify(req.Header.Get(r.options.SignatureHeader), body) {
+		writeResult(w, http.StatusUnauthorized, "invalid signature")
+		return
+	}
+	event, err := ParseEvent(body)
+	if err != nil {
+		writeResult(w, http.StatusBadRequest, fmt.Sprintf("invalid payload: %s", err))
+		return
+	}
+	if !r.dedup.begin(event.EventId) {
+		writeResult(w, http.StatusOK, "duplicate event")
+		return
+	}
+	handler := r.handler(event)
+	if handler == nil {
+		r.dedup.done(event.EventId, true)
+		writeResult(w, http.StatusOK, "no handler")
+		return
+	}
+	if err = handler(req.Context(), event); err != nil {
+		// forget the event, so the redelivery is handled again.
+		r.dedup.done(event.EventId, false)
+		writeResult(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	r.dedup.done(event.EventId, true)
+	writeResult(w, http.StatusOK, "SUCCESS")
+}
+
+// deduplicator remember the event ids being handled or handled successfully
+type deduplicator struct {
+	mu     sync.Mutex
+	ttl    time.Duration
+	events map[string]time.Time
+	// the last time the expired events are removed
+	swept time.Time
+}
+
+func newDeduplicator(ttl time.Duration) *deduplicator {
+	return &deduplicator{ttl: ttl, events: map[string]time.Time{}, swept: time.Now()}
+}
+
+// begin mark the event as being handled, return false if it's handled or being handled.
+func (d *deduplicator) begin(eventId string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := time.Now()
+	if now.Sub(d.swept) > d.ttl {
+		for id, at := range d.events {
+			if now.Sub(at) > d.ttl {
+				delete(d.events, id)
+			}
+		}
+		d.swept = now
+	}
+	if at, ok := d.events[eventId]; ok && now.Sub(at) <= d.ttl {
+		return false
+	}
+	d.events[eventId] = now
+	return true
+}
+
+// done finish handling the event, the failed event is forgotten.
+func (d *deduplicator) done(eventId string, success bool) {
+	if success {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.events, eventId)
+}
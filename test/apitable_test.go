@@ -270,3 +270,70 @@ func TestDescribeNode(t *testing.T) {
 	util.Dd(node)
 	t.Log(node)
 }
+
+func TestManageField(t *testing.T) {
+	// HOST can use the produced host by default without setting.
+	credential := common.NewCredential(os.Getenv("TOKEN"))
+	cpf := profile.NewClientProfile()
+	cpf.HttpProfile.Domain = os.Getenv("DOMAIN")
+	datasheet, _ := apitable.NewDatasheet(credential, os.Getenv("DATASHEET_ID"), cpf)
+	datasheet.SpaceId = os.Getenv("SPACE_ID")
+	createRequest := apitable.NewCreateFieldRequest()
+	fieldType := apitable.FieldType_SingleSelect
+	createRequest.Type = &fieldType
+	createRequest.Name = common.StringPtr("sdk_select_field")
+	createRequest.Property = &apitable.SelectFieldProperty{
+		Options: []*apitable.SelectFieldOption{{Name: common.StringPtr("open")}},
+	}
+	field, err := datasheet.CreateField(createRequest)
+	if _, ok := err.(*aterror.SDKError); ok {
+		t.Errorf("An API error has returned: %s", err)
+		return
+	}
+	// Non-SDK exception, direct failure. Other processing can be added to the actual code.
+	if err != nil {
+		t.Errorf("An unexcepted error has returned: %s", err)
+		panic(err)
+	}
+	updateRequest := apitable.NewUpdateFieldRequest()
+	updateRequest.FieldId = field.Id
+	updateRequest.Type = &fieldType
+	updateRequest.Name = common.StringPtr("sdk_select_field_renamed")
+	updateRequest.Property = createRequest.Property
+	_, err = datasheet.UpdateField(updateRequest)
+	if err != nil {
+		t.Errorf("An API error has returned: %s", err)
+	}
+	deleteRequest := apitable.NewDeleteFieldRequest()
+	deleteRequest.FieldId = field.Id
+	err = datasheet.DeleteField(deleteRequest)
+	if err != nil {
+		t.Errorf("An API error has returned: %s", err)
+	}
+	t.Log(*field.Id)
+}
+
+func TestCreateDatasheet(t *testing.T) {
+	// HOST can use the produced host by default without setting.
+	credential := common.NewCredential(os.Getenv("TOKEN"))
+	cpf := profile.NewClientProfile()
+	cpf.HttpProfile.Domain = os.Getenv("DOMAIN")
+	spaceClient, _ := space.NewSpace(credential, os.Getenv("SPACE_ID"), cpf)
+	textType := apitable.FieldType_Text
+	numberType := apitable.FieldType_Number
+	fields := []*space.DatasheetFieldSchema{
+		{Type: &textType, Name: common.StringPtr("title")},
+		{Type: &numberType, Name: common.StringPtr("amount"), Property: &apitable.NumberFieldProperty{}},
+	}
+	datasheet, err := spaceClient.CreateDatasheet("sdk_datasheet", "", fields, "created by sdk test")
+	if _, ok := err.(*aterror.SDKError); ok {
+		t.Errorf("An API error has returned: %s", err)
+		return
+	}
+	// Non-SDK exception, direct failure. Other processing can be added to the actual code.
+	if err != nil {
+		t.Errorf("An unexcepted error has returned: %s", err)
+		panic(err)
+	}
+	t.Log(datasheet.DatasheetId)
+}
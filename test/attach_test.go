@@ -0,0 +1,76 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	apitable "github.com/apitable/apitable-sdks/apitable.go/lib/datasheet"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestAttachFiles(t *testing.T) {
+	remote := newFakeRecords("dst1", map[string]interface{}{
+		"Files": []interface{}{map[string]interface{}{"token": "old", "name": "old.txt", "url": "https://s1/old.txt"}},
+	})
+	var mu sync.Mutex
+	uploads := 0
+	handlers := remote.register(map[string]func(r *http.Request) interface{}{
+		"POST /fusion/v1/datasheets/dst1/attachments": func(r *http.Request) interface{} {
+			_, _ = ioutil.ReadAll(r.Body)
+			mu.Lock()
+			defer mu.Unlock()
+			uploads++
+			return map[string]interface{}{"token": fmt.Sprintf("tok%d", uploads)}
+		},
+	})
+	server, credential, cpf := newTestServer(t, handlers)
+	defer server.Close()
+	dir, _ := ioutil.TempDir("", "attach")
+	defer os.RemoveAll(dir)
+	files := map[string]string{"a.txt": "same", "b.txt": "same", "c.txt": "other"}
+	for name, content := range files {
+		_ = ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+	}
+	cachePath := filepath.Join(dir, "cache", "uploads.json")
+	datasheet, _ := apitable.NewDatasheet(credential, "dst1", cpf)
+	datasheet.UploadCache, _ = apitable.NewUploadCache(cachePath)
+
+	record, err := datasheet.AttachFiles("rec1", "Files", []string{filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt")}, apitable.AttachMode_Append)
+	if err != nil || record == nil {
+		t.Fatalf("unexpected attach: %v", err)
+	}
+	b, _ := json.Marshal(remote.get("rec1")["Files"])
+	if string(b) != `[{"name":"old.txt","token":"old"},{"name":"a.txt","token":"tok1"}]` || uploads != 1 {
+		t.Errorf("unexpected attachments: %s, %d uploads", b, uploads)
+	}
+
+	// the cache is loaded from the file, only the new content is uploaded
+	datasheet.UploadCache, _ = apitable.NewUploadCache(cachePath)
+	_, err = datasheet.AttachFiles("rec1", "Files", []string{filepath.Join(dir, "c.txt"), filepath.Join(dir, "b.txt")}, apitable.AttachMode_Replace)
+	b, _ = json.Marshal(remote.get("rec1")["Files"])
+	if err != nil || string(b) != `[{"name":"c.txt","token":"tok2"},{"name":"b.txt","token":"tok1"}]` || uploads != 2 {
+		t.Errorf("unexpected attachments: %s, %d uploads, %v", b, uploads, err)
+	}
+
+	if _, err = datasheet.AttachFiles("rec1", "Files", []string{filepath.Join(dir, "missing.txt")}, apitable.AttachMode_Replace); err == nil {
+		t.Errorf("expect the file read error")
+	}
+	if _, err = datasheet.AttachFiles("rec1", "Files", nil, "merge"); err == nil {
+		t.Errorf("expect the invalid mode error")
+	}
+
+	// without UploadCache, the same content is uploaded once per call, and nothing is cached across the calls
+	datasheet, _ = apitable.NewDatasheet(credential, "dst1", cpf)
+	for i := 0; i < 2; i++ {
+		if _, err = datasheet.AttachFiles("rec1", "Files", []string{filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt")}, apitable.AttachMode_Replace); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if uploads != 4 {
+		t.Errorf("unexpected uploads without the cache: %d", uploads)
+	}
+}
@@ -0,0 +1,176 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/backup"
+	apitable "github.com/apitable/apitable-sdks/apitable.go/lib/datasheet"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/space"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// backupHandlers serve the space tree of spc1 with the fields and records of dst1, dst2 and dst3
+func backupHandlers(fileURL string) map[string]func(r *http.Request) interface{} {
+	handlers := spaceTreeHandlers()
+	fields := map[string][]*apitable.DatasheetField{
+		"dst1": {
+			newTestField("fld1", "Name", apitable.FieldType_SingleText),
+			withProperty(newTestField("fld2", "Status", apitable.FieldType_SingleSelect), `{"options":[{"id":"opt1","name":"Open","color":{"name":"blue"}}]}`),
+			newTestField("fld3", "Files", apitable.FieldType_Attachment),
+			withProperty(newTestField("fld4", "Total", apitable.FieldType_Formula), `{"expression":"1+1"}`),
+			withProperty(newTestField("fld5", "Owner", apitable.FieldType_Member), `{"isMulti":true,"options":[{"id":"u1"}]}`),
+			withProperty(newTestField("fld6", "Company", apitable.FieldType_MagicLink), `{"foreignDatasheetId":"dst3","brotherFieldId":"fld9"}`),
+		},
+		"dst2": {withProperty(newTestField("fld7", "Code", apitable.FieldType_Formula), `{"expression":"1+1"}`)},
+		"dst3": {
+			newTestField("fld8", "Title", apitable.FieldType_SingleText),
+			withProperty(newTestField("fld9", "Leads", apitable.FieldType_MagicLink), `{"foreignDatasheetId":"dst1","brotherFieldId":"fld6"}`),
+		},
+	}
+	file := func(token string, name string) map[string]interface{} {
+		return map[string]interface{}{"token": token, "name": name, "url": fileURL + "/" + token}
+	}
+	records := map[string][]interface{}{
+		"dst1": {
+			record("recA1", map[string]interface{}{
+				"Name": "a", "Status": "Open", "Total": 2, "Owner": []interface{}{map[string]interface{}{"id": "u1"}},
+				"Files":   []interface{}{file("tok1", "a.txt"), file("missing", "b.txt")},
+				"Company": []interface{}{"recC1"},
+			}),
+			record("recA2", map[string]interface{}{"Name": "b", "Files": []interface{}{file("tok1", "a.txt")}}),
+		},
+		"dst2": {record("recB1", map[string]interface{}{"Code": 42.5})},
+		"dst3": {record("recC1", map[string]interface{}{"Title": "Acme", "Leads": []interface{}{"recA1"}})},
+	}
+	for id := range fields {
+		id := id
+		handlers["GET /fusion/v1/datasheets/"+id+"/fields"] = func(r *http.Request) interface{} {
+			return map[string]interface{}{"fields": fields[id]}
+		}
+		handlers["GET /fusion/v1/datasheets/"+id+"/views"] = func(r *http.Request) interface{} {
+			return map[string]interface{}{"views": []interface{}{map[string]interface{}{"id": "viw1", "name": "Grid", "type": "Grid"}}}
+		}
+		handlers["GET /fusion/v1/datasheets/"+id+"/records"] = func(r *http.Request) interface{} {
+			return recordPage(records[id]...)
+		}
+	}
+	return handlers
+}
+
+func TestBackupAndRestore(t *testing.T) {
+	files := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/tok1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer files.Close()
+	server, credential, cpf := newTestServer(t, backupHandlers(files.URL))
+	defer server.Close()
+	source, _ := space.NewSpace(credential, "spc1", cpf)
+
+	var archive bytes.Buffer
+	manifest, err := backup.Backup(source, &archive, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest.Datasheets) != 3 || manifest.Datasheets[2].Path != "Sales/2024/Leads" || manifest.Datasheets[2].Records != 2 {
+		t.Fatalf("unexpected datasheets: %+v", manifest.Datasheets)
+	}
+	if len(manifest.Attachments) != 1 || manifest.Attachments[0].Size != 5 || len(manifest.Problems) != 1 {
+		t.Fatalf("unexpected attachments: %+v, %v", manifest.Attachments, manifest.Problems)
+	}
+	verified, err := backup.Verify(bytes.NewReader(archive.Bytes()))
+	if err != nil || verified.SpaceId != "spc1" || len(verified.Checksums) != 7 {
+		t.Fatalf("unexpected verify: %+v, %v", verified, err)
+	}
+	if _, err = backup.Verify(strings.NewReader("not an archive")); err == nil {
+		t.Errorf("expect the invalid archive error")
+	}
+
+	// restore to another space
+	created := map[string]map[string]interface{}{}
+	linkFields := []string{}
+	uploads := 0
+	handlers := map[string]func(r *http.Request) interface{}{
+		"POST /fusion/v1/spaces/spc2/datasheets": func(r *http.Request) interface{} {
+			body := map[string]interface{}{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			id := fmt.Sprintf("new%d", len(created)+1)
+			created[id] = body
+			return map[string]interface{}{"id": id, "fields": []interface{}{}}
+		},
+	}
+	targets := map[string]*fakeRecords{}
+	for _, id := range []string{"new1", "new2", "new3"} {
+		id := id
+		targets[id] = newFakeRecords(id)
+		targets[id].register(handlers)
+		handlers["POST /fusion/v1/spaces/spc2/datasheets/"+id+"/fields"] = func(r *http.Request) interface{} {
+			body := map[string]interface{}{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			property := body["property"].(map[string]interface{})
+			linkFields = append(linkFields, fmt.Sprintf("%s.%s->%s", id, body["name"], property["foreignDatasheetId"]))
+			return map[string]interface{}{"id": "fldNew", "name": body["name"]}
+		}
+		handlers["POST /fusion/v1/datasheets/"+id+"/attachments"] = func(r *http.Request) interface{} {
+			uploads++
+			_, _ = ioutil.ReadAll(r.Body)
+			return map[string]interface{}{"token": "newtok", "name": "a.txt", "size": 5}
+		}
+	}
+	server2, credential2, cpf2 := newTestServer(t, handlers)
+	defer server2.Close()
+	target, _ := space.NewSpace(credential2, "spc2", cpf2)
+	report, err := backup.Restore(target, bytes.NewReader(archive.Bytes()), &backup.RestoreOptions{FolderId: "fod9"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Datasheets["dst3"] != "new1" || report.Datasheets["dst1"] != "new3" || len(report.Records) != 4 || report.Attachments != 1 || uploads != 1 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	leads := created["new3"]
+	if leads["name"] != "Leads" || leads["folderId"] != "fod9" {
+		t.Errorf("unexpected datasheet: %v", leads)
+	}
+	names := []string{}
+	for _, field := range leads["fields"].([]interface{}) {
+		names = append(names, field.(map[string]interface{})["name"].(string))
+	}
+	if strings.Join(names, ",") != "Name,Status,Files,Owner" {
+		t.Errorf("unexpected fields: %v", names)
+	}
+	option := leads["fields"].([]interface{})[1].(map[string]interface{})["property"].(map[string]interface{})["options"].([]interface{})[0].(map[string]interface{})
+	if _, ok := option["id"]; ok || option["name"] != "Open" {
+		t.Errorf("unexpected option: %v", option)
+	}
+	if strings.Join(linkFields, ";") != "new1.Leads->new3" {
+		t.Errorf("unexpected link fields: %v", linkFields)
+	}
+	first := targets["new3"].get(report.Records["recA1"])
+	files1, _ := json.Marshal(first["Files"])
+	if string(files1) != `[{"name":"a.txt","token":"newtok"}]` || first["Owner"] != nil || first["Total"] != nil {
+		t.Errorf("unexpected record: %v", first)
+	}
+	// the links are written from Docs, the Company values of Leads are synced by the server
+	leadsLink, _ := targets["new1"].get(report.Records["recC1"])["Leads"].([]interface{})
+	if len(leadsLink) != 1 || leadsLink[0] != report.Records["recA1"] || first["Company"] != nil {
+		t.Errorf("unexpected link: %v, %v", leadsLink, first["Company"])
+	}
+	// the unsupported primary field is restored as text
+	if code := targets["new2"].get(report.Records["recB1"])["Code"]; code != "42.5" {
+		t.Errorf("unexpected primary field value: %v", code)
+	}
+	problems := strings.Join(report.Problems, "\n")
+	for _, expected := range []string{"field Total", "primary field Code", "member values of Owner", "fld6", "attachment missing"} {
+		if !strings.Contains(problems, expected) {
+			t.Errorf("missing problem %q in:\n%s", expected, problems)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package test
+
+import (
+	"github.com/apitable/apitable-sdks/apitable.go/lib/cache"
+	apitable "github.com/apitable/apitable-sdks/apitable.go/lib/datasheet"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func record(id string, fields map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"recordId": id, "fields": fields}
+}
+
+func recordPage(records ...interface{}) map[string]interface{} {
+	return map[string]interface{}{"pageNum": 1, "pageSize": 1000, "total": len(records), "records": records}
+}
+
+func TestCache(t *testing.T) {
+	remote := []interface{}{
+		record("rec1", map[string]interface{}{"Name": "a", "Modified": 1000}),
+		record("rec2", map[string]interface{}{"Name": "b", "Modified": 2000}),
+	}
+	formulas := []string{}
+	server, credential, cpf := newTestServer(t, map[string]func(r *http.Request) interface{}{
+		"GET /fusion/v1/datasheets/dst1/records": func(r *http.Request) interface{} {
+			formula := r.URL.Query().Get("filterByFormula")
+			formulas = append(formulas, formula)
+			if formula != "" {
+				return recordPage(record("rec2", map[string]interface{}{"Name": "c", "Modified": 3000}))
+			}
+			return recordPage(remote...)
+		},
+	})
+	defer server.Close()
+	datasheet, _ := apitable.NewDatasheet(credential, "dst1", cpf)
+	dir, _ := ioutil.TempDir("", "cache")
+	defer os.RemoveAll(dir)
+
+	for _, encoding := range []cache.Encoding{cache.Encoding_JSON, cache.Encoding_Gob} {
+		filePath := filepath.Join(dir, "records."+string(encoding))
+		store, err := cache.NewFileStore(filePath, encoding)
+		if err != nil {
+			t.Fatal(err)
+		}
+		recordCache := cache.NewCache(datasheet, &cache.Options{Store: store, ModifiedTimeField: "Modified", ReconcileInterval: time.Hour})
+		result, err := recordCache.Refresh()
+		if err != nil || !result.Full || result.Updated != 2 {
+			t.Fatalf("unexpected full refresh: %+v, %v", result, err)
+		}
+		result, err = recordCache.Refresh()
+		if err != nil || result.Full || result.Updated != 1 {
+			t.Fatalf("unexpected incremental refresh: %+v, %v", result, err)
+		}
+		if !strings.Contains(formulas[len(formulas)-1], "IS_AFTER({Modified}") {
+			t.Errorf("unexpected formula: %s", formulas[len(formulas)-1])
+		}
+		cached, err := recordCache.GetRecord("rec2")
+		if err != nil || (*cached.Fields)["Name"] != "c" {
+			t.Errorf("unexpected record: %v", err)
+		}
+		if _, err = recordCache.GetRecord("rec3"); err == nil {
+			t.Errorf("missing record should return error")
+		}
+		if metrics := recordCache.Metrics(); metrics.Hits != 1 || metrics.Misses != 1 || metrics.Refreshes != 2 {
+			t.Errorf("unexpected metrics: %+v", metrics)
+		}
+
+		// reload from the file, and detect the deletion by reconciliation.
+		store, err = cache.NewFileStore(filePath, encoding)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count, _ := store.Count(); count != 2 {
+			t.Fatalf("unexpected count from file: %d", count)
+		}
+		meta, _ := store.Meta()
+		if meta.Cursor != 3000 {
+			t.Errorf("unexpected cursor: %d", meta.Cursor)
+		}
+		remote = remote[:1]
+		recordCache = cache.NewCache(datasheet, &cache.Options{Store: store, ModifiedTimeField: "Modified", ReconcileInterval: time.Nanosecond})
+		result, err = recordCache.Refresh()
+		if err != nil || len(result.Deleted) != 1 || result.Deleted[0] != "rec2" {
+			t.Errorf("unexpected reconciliation: %+v, %v", result, err)
+		}
+		matched, _ := recordCache.Scan(func(record *apitable.Record) bool {
+			return (*record.Fields)["Name"] == "a"
+		})
+		if count, _ := recordCache.Count(); count != 1 || len(matched) != 1 {
+			t.Errorf("unexpected records after deletion")
+		}
+		remote = append(remote, record("rec2", map[string]interface{}{"Name": "b", "Modified": 2000}))
+	}
+}
+
+func TestCacheModifiedTimeFieldId(t *testing.T) {
+	describes := 0
+	formulas := []string{}
+	server, credential, cpf := newTestServer(t, map[string]func(r *http.Request) interface{}{
+		"GET /fusion/v1/datasheets/dst1/fields": func(r *http.Request) interface{} {
+			describes++
+			return map[string]interface{}{"fields": []*apitable.DatasheetField{
+				newTestField("fld1", "Name", apitable.FieldType_SingleText),
+				newTestField("fld2", "Modified", apitable.FieldType_LastModifiedTime),
+			}}
+		},
+		"GET /fusion/v1/datasheets/dst1/records": func(r *http.Request) interface{} {
+			formulas = append(formulas, r.URL.Query().Get("filterByFormula"))
+			return recordPage(record("rec1", map[string]interface{}{"Name": "a", "Modified": 1000}))
+		},
+	})
+	defer server.Close()
+	datasheet, _ := apitable.NewDatasheet(credential, "dst1", cpf)
+
+	// the records are keyed by the field name, the id is resolved to the name once
+	recordCache := cache.NewCache(datasheet, &cache.Options{ModifiedTimeField: "fld2", ReconcileInterval: time.Hour})
+	for i := 0; i < 2; i++ {
+		if _, err := recordCache.Refresh(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if meta, _ := recordCache.Store().Meta(); meta.Cursor != 1000 || describes != 1 {
+		t.Errorf("unexpected cursor: %d, %d", meta.Cursor, describes)
+	}
+	if len(formulas) != 2 || !strings.Contains(formulas[1], "IS_AFTER({Modified}") {
+		t.Errorf("the second refresh should be incremental: %v", formulas)
+	}
+	if result, err := recordCache.Reload(); err != nil || !result.Full {
+		t.Fatalf("unexpected reload: %+v, %v", result, err)
+	}
+	if metrics := recordCache.Metrics(); metrics.Refreshes != 3 || metrics.Updated != 3 || metrics.LastRefresh.IsZero() {
+		t.Errorf("the reload should update the metrics: %+v", metrics)
+	}
+}
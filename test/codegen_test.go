@@ -0,0 +1,215 @@
+package test
+
+import (
+	"github.com/apitable/apitable-sdks/apitable.go/lib/codegen"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/common"
+	apitable "github.com/apitable/apitable-sdks/apitable.go/lib/datasheet"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	fields := []*apitable.DatasheetField{
+		newTestField("fld1", "Title", apitable.FieldType_SingleText),
+		withProperty(newTestField("fld2", "Status", apitable.FieldType_SingleSelect), `{"options":[{"id":"opt1","name":"In Progress"},{"id":"opt2","name":"已完成"}]}`),
+		withProperty(newTestField("fld3", "Total", apitable.FieldType_Formula), `{"valueType":"Number"}`),
+		newTestField("fld4", "Files", apitable.FieldType_Attachment),
+		newTestField("fld5", `Amount, USD\net`, apitable.FieldType_Number),
+	}
+	src, err := codegen.Generate(&codegen.Options{Package: "model"}, []*codegen.Table{
+		{Name: "leads", DatasheetId: "dst1", Fields: fields},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"type Leads struct",
+		"Title string `vika:\"Title,fld1,omitempty=false\"`",
+		"Total float64 `vika:\"Total,fld3,readonly\"`",
+		"Files []*datasheet.Attachment",
+		"LeadsStatusInProgress LeadsStatus = \"In Progress\"",
+		"LeadsStatusX已完成",
+		"func (r *Leads) GetTotal() float64",
+		"`vika:\"Amount\\\\, USD\\\\\\\\net,fld5,omitempty=false\"`",
+	} {
+		if !strings.Contains(string(src), expected) {
+			t.Errorf("generated source does not contain %q:\n%s", expected, src)
+		}
+	}
+}
+
+func TestGenerateZeroValues(t *testing.T) {
+	fields := []*apitable.DatasheetField{
+		newTestField("fld1", "Title", apitable.FieldType_SingleText),
+		newTestField("fld2", "Done", apitable.FieldType_Checkbox),
+		newTestField("fld3", "Amount", apitable.FieldType_Number),
+	}
+	src, err := codegen.Generate(&codegen.Options{Package: "model"}, []*codegen.Table{{Name: "leads", DatasheetId: "dst1", Fields: fields}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the generated tags are parsed by the codec, so the zero values clear the cells
+	type lead struct {
+		Title  string  `vika:"Title,fld1,omitempty=false"`
+		Done   bool    `vika:"Done,fld2,omitempty=false"`
+		Amount float64 `vika:"Amount,fld3,omitempty=false"`
+	}
+	for _, expected := range []string{"Title,fld1,omitempty=false", "Done,fld2,omitempty=false", "Amount,fld3,omitempty=false"} {
+		if !strings.Contains(string(src), expected) {
+			t.Errorf("generated source does not contain %q:\n%s", expected, src)
+		}
+	}
+	encoded, _ := apitable.EncodeFields(&lead{}, common.FieldKeyId)
+	if len(*encoded) != 3 || (*encoded)["fld2"] != false || (*encoded)["fld1"] != "" || (*encoded)["fld3"] != 0.0 {
+		t.Errorf("unexpected encoded fields: %v", *encoded)
+	}
+}
+
+func TestGenerateConflicts(t *testing.T) {
+	status := `{"options":[{"id":"opt1","name":"Open"},{"id":"opt2","name":"Open Now"}]}`
+	tables := []*codegen.Table{
+		{Name: "leads", DatasheetId: "dst1", Fields: []*apitable.DatasheetField{
+			// the option type LeadsDatasheetId conflicts with the const of the datasheet id
+			withProperty(newTestField("fld1", "Datasheet Id", apitable.FieldType_SingleSelect), status),
+			// the constant LeadsStatusOpen conflicts with the option type of the next field
+			withProperty(newTestField("fld2", "Status", apitable.FieldType_SingleSelect), status),
+			withProperty(newTestField("fld3", "Status Open", apitable.FieldType_MultiSelect), `{"options":[{"id":"opt1","name":"Now"}]}`),
+		}},
+	}
+	// the struct LeadsStatus conflicts with the option type of leads
+	statuses := append(tables, &codegen.Table{Name: "leads status", DatasheetId: "dst2"})
+	for _, tables := range [][]*codegen.Table{tables, statuses} {
+		src, err := codegen.Generate(&codegen.Options{Package: "model"}, tables)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertUniqueDecls(t, src)
+	}
+	src, _ := codegen.Generate(&codegen.Options{Package: "model"}, tables)
+	for _, expected := range []string{"LeadsDatasheetId = \"dst1\"", "type LeadsDatasheetId2 = string", "LeadsStatusOpenNow ",
+		"type LeadsStatusOpen2 = string", "LeadsStatusOpen2Now LeadsStatusOpen2"} {
+		if !strings.Contains(string(src), expected) {
+			t.Errorf("generated source does not contain %q:\n%s", expected, src)
+		}
+	}
+	if src, _ = codegen.Generate(&codegen.Options{Package: "model"}, statuses); !strings.Contains(string(src), "type LeadsStatus2 = string") {
+		t.Errorf("the option type should be suffixed:\n%s", src)
+	}
+	// the struct names are not suffixed
+	statuses = append(statuses, &codegen.Table{Name: "Leads-Status", DatasheetId: "dst3"})
+	if _, err := codegen.Generate(&codegen.Options{Package: "model"}, statuses); err == nil {
+		t.Errorf("expect the error of the duplicate struct name")
+	}
+}
+
+// assertUniqueDecls check the package level identifiers of the generated source are declared once
+func assertUniqueDecls(t *testing.T, src []byte) {
+	file, err := parser.ParseFile(token.NewFileSet(), "model.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	declared := map[string]bool{}
+	for _, decl := range file.Decls {
+		names := []string{}
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Recv == nil {
+				names = append(names, d.Name.Name)
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					names = append(names, s.Name.Name)
+				case *ast.ValueSpec:
+					for _, name := range s.Names {
+						names = append(names, name.Name)
+					}
+				}
+			}
+		}
+		for _, name := range names {
+			if declared[name] {
+				t.Errorf("the identifier %s is declared twice:\n%s", name, src)
+			}
+			declared[name] = true
+		}
+	}
+}
+
+type testLead struct {
+	RecordId string   `vika:"@recordId"`
+	Title    string   `vika:"Title,fld1"`
+	Amount   float64  `vika:"Amount,fld2"`
+	Tags     []string `vika:"Tags,fld3"`
+	Total    float64  `vika:"Total,fld4,readonly"`
+}
+
+func TestStructCodec(t *testing.T) {
+	record := &apitable.Record{BaseRecord: &apitable.BaseRecord{
+		RecordId: common.StringPtr("rec1"),
+		Fields: &apitable.Field{
+			"Title": "a",
+			"fld2":  12.5,
+			"Tags":  []interface{}{"x", "y"},
+			"Total": 3.0,
+		},
+	}}
+	lead := &testLead{}
+	if err := apitable.DecodeRecord(record, lead); err != nil {
+		t.Fatal(err)
+	}
+	if lead.RecordId != "rec1" || lead.Title != "a" || lead.Amount != 12.5 || len(lead.Tags) != 2 || lead.Total != 3 {
+		t.Errorf("unexpected decoded record: %+v", lead)
+	}
+	encoded, err := apitable.EncodeRecord(lead, common.FieldKeyId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *encoded.RecordId != "rec1" || (*encoded.Fields)["fld1"] != "a" {
+		t.Errorf("unexpected encoded record: %v", *encoded.Fields)
+	}
+	if _, ok := (*encoded.Fields)["fld4"]; ok {
+		t.Errorf("readonly field should not be encoded")
+	}
+}
+
+type testPartialLead struct {
+	Title   string   `vika:"Title"`
+	Amount  float64  `vika:"Amount\\, USD,fld2"`
+	Done    bool     `vika:"Done,fld3"`
+	Score   *float64 `vika:"Score,fld4"`
+	Checked bool     `vika:"Checked,fld5,omitempty=false"`
+	Note    *string  `vika:"Note,fld6"`
+}
+
+func TestStructCodecTags(t *testing.T) {
+	record := &apitable.Record{BaseRecord: &apitable.BaseRecord{
+		RecordId: common.StringPtr("rec1"),
+		Fields:   &apitable.Field{"Title": "a", "Amount, USD": 12.5, "fld3": true},
+	}}
+	lead := &testPartialLead{}
+	if err := apitable.DecodeRecord(record, lead); err != nil {
+		t.Fatal(err)
+	}
+	if lead.Title != "a" || lead.Amount != 12.5 || !lead.Done {
+		t.Errorf("unexpected decoded record: %+v", lead)
+	}
+	// the partial update writes the filled fields only
+	zero := 0.0
+	fields, err := apitable.EncodeFields(&testPartialLead{Title: "x", Score: &zero}, common.FieldKeyName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*fields) != 3 || (*fields)["Title"] != "x" || (*fields)["Score"] != &zero || (*fields)["Checked"] != false {
+		t.Errorf("unexpected encoded fields: %v", *fields)
+	}
+	// the tag without the id is keyed by the name
+	fields, _ = apitable.EncodeFields(&testPartialLead{Title: "x", Amount: 1}, common.FieldKeyId)
+	if (*fields)["Title"] != "x" || (*fields)["fld2"] != 1.0 {
+		t.Errorf("unexpected encoded fields: %v", *fields)
+	}
+}
@@ -0,0 +1,95 @@
+package test
+
+import (
+	"github.com/apitable/apitable-sdks/apitable.go/lib/space"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func member(unitId string, name string, email string) map[string]interface{} {
+	return map[string]interface{}{"unitId": unitId, "name": name, "email": email}
+}
+
+func team(unitId string, name string) map[string]interface{} {
+	return map[string]interface{}{"unitId": unitId, "name": name}
+}
+
+func TestContacts(t *testing.T) {
+	page := func(key string, total int, items ...interface{}) func(r *http.Request) interface{} {
+		return func(r *http.Request) interface{} {
+			return map[string]interface{}{"pageNum": 1, "pageSize": 100, "total": total, key: items}
+		}
+	}
+	handlers := map[string]func(r *http.Request) interface{}{
+		"GET /fusion/v1/spaces/spc1/teams/0/children":  page("teams", 1, team("t1", "Sales")),
+		"GET /fusion/v1/spaces/spc1/teams/t1/children": page("teams", 1, team("t2", "East")),
+		"GET /fusion/v1/spaces/spc1/teams/t2/children": page("teams", 0),
+		"GET /fusion/v1/spaces/spc1/teams/0/members":   page("members", 1, member("u1", "Alice", "alice@example.com")),
+		"GET /fusion/v1/spaces/spc1/teams/t1/members":  page("members", 2, member("u1", "Alice", "alice@example.com"), member("u2", "Bob", "bob@example.com")),
+		"GET /fusion/v1/spaces/spc1/teams/t2/members":  page("members", 1, member("u3", "Bob", "bob2@example.com")),
+		"GET /fusion/v1/spaces/spc1/roles":             page("roles", 1, team("r1", "Admin")),
+		"GET /fusion/v1/spaces/spc1/members/u1":        func(r *http.Request) interface{} { return map[string]interface{}{"member": member("u1", "Alice", "")} },
+	}
+	// count the walks of all members
+	walks := 0
+	rootMembers := handlers["GET /fusion/v1/spaces/spc1/teams/0/members"]
+	handlers["GET /fusion/v1/spaces/spc1/teams/0/members"] = func(r *http.Request) interface{} {
+		walks++
+		return rootMembers(r)
+	}
+	server, credential, cpf := newTestServer(t, handlers)
+	defer server.Close()
+	spaceClient, _ := space.NewSpace(credential, "spc1", cpf)
+
+	paths := []string{}
+	err := spaceClient.WalkTeams(func(team *space.Team, depth int) error {
+		paths = append(paths, strings.Repeat("-", depth)+*team.Name)
+		return nil
+	})
+	if err != nil || strings.Join(paths, ",") != "Sales,-East" {
+		t.Errorf("unexpected teams: %v, %v", paths, err)
+	}
+	members, err := spaceClient.DescribeAllMembers(true)
+	if err != nil || len(members) != 3 {
+		t.Errorf("unexpected members: %d, %v", len(members), err)
+	}
+	found, err := spaceClient.FindMemberByEmail("BOB@example.com")
+	if err != nil || *found.UnitId != "u2" {
+		t.Fatalf("unexpected member: %v", err)
+	}
+	if value := found.UnitFieldValue(); value.UnitId != "u2" || value.UnitType != "Member" {
+		t.Errorf("unexpected unit value: %+v", value)
+	}
+	if _, err = spaceClient.FindMemberByName("Bob"); err == nil {
+		t.Errorf("duplicate member name should be ambiguous")
+	}
+	// the members are walked once for the searches, until the cache is cleared
+	walks = 0
+	for _, email := range []string{"alice@example.com", "bob@example.com", "bob2@example.com"} {
+		if _, err = spaceClient.FindMemberByEmail(email); err != nil {
+			t.Errorf("unexpected error of %s: %v", email, err)
+		}
+	}
+	if searched, _ := spaceClient.SearchMembers("bob"); len(searched) != 2 || walks != 0 {
+		t.Errorf("the members should be cached: %d, %d", len(searched), walks)
+	}
+	spaceClient.ClearMemberCache()
+	_, _ = spaceClient.FindMemberByEmail("alice@example.com")
+	spaceClient.WithMemberCacheTTL(0)
+	_, _ = spaceClient.FindMemberByEmail("alice@example.com")
+	_, _ = spaceClient.FindMemberByEmail("alice@example.com")
+	if walks != 3 {
+		t.Errorf("unexpected walks of the members: %d", walks)
+	}
+	roles, err := spaceClient.DescribeAllRoles()
+	if err != nil || len(roles) != 1 {
+		t.Errorf("unexpected roles: %v", err)
+	}
+	request := space.NewDescribeMemberRequest()
+	request.UnitId = members[0].UnitId
+	detail, err := spaceClient.DescribeMember(request)
+	if err != nil || *detail.Name != "Alice" {
+		t.Errorf("unexpected member detail: %v", err)
+	}
+}
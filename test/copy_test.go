@@ -0,0 +1,140 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	apitable "github.com/apitable/apitable-sdks/apitable.go/lib/datasheet"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCopyRecords(t *testing.T) {
+	files := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("content of " + r.URL.Path))
+	}))
+	defer files.Close()
+	source := newFakeRecords("dst1",
+		map[string]interface{}{"Name": "a", "Status": "Done", "Files": []interface{}{
+			map[string]interface{}{"token": "tok1", "name": "a.txt", "url": files.URL + "/tok1"},
+		}},
+		map[string]interface{}{"Name": "b", "Status": "Done"},
+		map[string]interface{}{"Name": "c", "Status": "Open"},
+	)
+	archive := newFakeRecords("dst2")
+	handlers := archive.register(source.register(map[string]func(r *http.Request) interface{}{}))
+	listSource := handlers["GET /fusion/v1/datasheets/dst1/records"]
+	formulas := []string{}
+	handlers["GET /fusion/v1/datasheets/dst1/records"] = func(r *http.Request) interface{} {
+		formula := r.URL.Query().Get("filterByFormula")
+		formulas = append(formulas, formula)
+		if formula == "" {
+			return listSource(r)
+		}
+		return recordPage(source.records[0], source.records[1])
+	}
+	handlers["GET /fusion/v1/datasheets/dst1/fields"] = func(r *http.Request) interface{} {
+		return map[string]interface{}{"fields": []interface{}{
+			newTestField("fld1", "Name", apitable.FieldType_SingleText),
+			newTestField("fld2", "Status", apitable.FieldType_SingleText),
+		}}
+	}
+	handlers["GET /fusion/v1/datasheets/dst2/fields"] = func(r *http.Request) interface{} {
+		return map[string]interface{}{"fields": []interface{}{
+			newTestField("fld3", "Title", apitable.FieldType_SingleText),
+			newTestField("fld4", "Status", apitable.FieldType_SingleText),
+			newTestField("fld5", "Files", apitable.FieldType_Attachment),
+			newTestField("fld6", "Total", apitable.FieldType_Formula),
+		}}
+	}
+	uploaded := []string{}
+	handlers["POST /fusion/v1/datasheets/dst2/attachments"] = func(r *http.Request) interface{} {
+		b, _ := ioutil.ReadAll(r.Body)
+		uploaded = append(uploaded, string(b))
+		return map[string]interface{}{"token": "newtok", "name": "a.txt"}
+	}
+	server, credential, cpf := newTestServer(t, handlers)
+	defer server.Close()
+	src, _ := apitable.NewDatasheet(credential, "dst1", cpf)
+	src.SpaceId = "spc1"
+	dst, _ := apitable.NewDatasheet(credential, "dst2", cpf)
+	dst.SpaceId = "spc2"
+
+	mapping := []*apitable.FieldMapping{
+		{To: "Title", Func: func(record *apitable.Record) (apitable.FieldValue, error) {
+			return strings.ToUpper((*record.Fields)["Name"].(string)), nil
+		}},
+		{To: "Files", From: "Files"},
+		{To: "Status", Value: "Archived"},
+	}
+	result, err := apitable.CopyRecords(src, dst, mapping, `{Status}="Done"`, &apitable.CopyOptions{Mode: apitable.CopyMode_Move, BatchSize: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Copied != 2 || result.Deleted != 2 || result.Attachments != 1 || result.RecordIds["rec2"] != "rec2" || formulas[0] != `{Status}="Done"` {
+		t.Fatalf("unexpected result: %+v, %v", result, formulas)
+	}
+	if len(uploaded) != 1 || !strings.Contains(uploaded[0], "content of /tok1") {
+		t.Errorf("unexpected uploads: %v", uploaded)
+	}
+	b, _ := json.Marshal(archive.records)
+	if string(b) != `[{"fields":{"Files":[{"name":"a.txt","token":"newtok"}],"Status":"Archived","Title":"A"},"recordId":"rec1"},`+
+		`{"fields":{"Status":"Archived","Title":"B"},"recordId":"rec2"}]` {
+		t.Errorf("unexpected copied records: %s", b)
+	}
+	if len(source.records) != 1 || source.get("rec3") == nil {
+		t.Errorf("unexpected source records: %v", source.records)
+	}
+
+	// copy the fields of the same name, the source records are kept
+	result, err = apitable.CopyRecords(src, dst, nil, "", nil)
+	if err != nil || result.Copied != 1 || result.Deleted != 0 || len(source.records) != 1 {
+		t.Fatalf("unexpected result: %+v, %v", result, err)
+	}
+	if fields := archive.get("rec3"); len(fields) != 1 || fields["Status"] != "Open" {
+		t.Errorf("unexpected copied record: %v", fields)
+	}
+
+	if _, err = apitable.CopyRecords(src, dst, []*apitable.FieldMapping{{To: "Total", From: "Name"}}, "", nil); err == nil {
+		t.Errorf("expect the unsupported field error")
+	}
+}
+
+func TestCopyRecordsPartialMove(t *testing.T) {
+	source := newFakeRecords("dst1", map[string]interface{}{"Name": "a"}, map[string]interface{}{"Name": "b"}, map[string]interface{}{"Name": "c"})
+	archive := newFakeRecords("dst2")
+	handlers := archive.register(source.register(map[string]func(r *http.Request) interface{}{}))
+	handlers["GET /fusion/v1/datasheets/dst2/fields"] = func(r *http.Request) interface{} {
+		return map[string]interface{}{"fields": []interface{}{newTestField("fld1", "Title", apitable.FieldType_SingleText)}}
+	}
+	server, credential, cpf := newTestServer(t, handlers)
+	defer server.Close()
+	src, _ := apitable.NewDatasheet(credential, "dst1", cpf)
+	dst, _ := apitable.NewDatasheet(credential, "dst2", cpf)
+
+	failed := "b"
+	mapping := []*apitable.FieldMapping{{To: "Title", Func: func(record *apitable.Record) (apitable.FieldValue, error) {
+		name := (*record.Fields)["Name"].(string)
+		if name == failed {
+			return nil, fmt.Errorf("fail to map %s", name)
+		}
+		return name, nil
+	}}}
+	options := &apitable.CopyOptions{Mode: apitable.CopyMode_Move, BatchSize: 1}
+	// the record of the first batch is moved before the error
+	result, err := apitable.CopyRecords(src, dst, mapping, "", options)
+	if err == nil || result.Copied != 1 || result.Deleted != 1 || result.RecordIds["rec1"] != "rec1" {
+		t.Fatalf("unexpected result: %+v, %v", result, err)
+	}
+	if len(source.records) != 2 || source.get("rec1") != nil || len(archive.records) != 1 {
+		t.Fatalf("the copied record should be deleted: %v, %v", source.records, archive.records)
+	}
+	// the retry moves the others without duplicates
+	failed = ""
+	result, err = apitable.CopyRecords(src, dst, mapping, "", options)
+	if err != nil || result.Copied != 2 || result.Deleted != 2 || len(source.records) != 0 || len(archive.records) != 3 {
+		t.Errorf("unexpected retry: %+v, %v, %v", result, err, archive.records)
+	}
+}
@@ -0,0 +1,105 @@
+package test
+
+import (
+	"encoding/json"
+	apitable "github.com/apitable/apitable-sdks/apitable.go/lib/datasheet"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/dedupe"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestDedupe(t *testing.T) {
+	local := newFakeRecords("dst1",
+		map[string]interface{}{"Name": "Acme Inc", "Email": "a@x.com", "Tags": []interface{}{"a"}, "Score": 3, "Total": 1},
+		map[string]interface{}{"Name": "ＡＣＭＥ  inc ", "Phone": "123", "Tags": []interface{}{"b"}, "Score": 5, "Total": 2},
+		map[string]interface{}{"Name": "Acme Inx", "Phone": "456"},
+		map[string]interface{}{"Name": "Other"},
+		map[string]interface{}{"Name": "ｶﾞｽ"},
+		map[string]interface{}{"Name": "ガス"},
+	)
+	local.records[0]["createdAt"] = 100
+	local.records[1]["createdAt"] = 50
+	local.records[2]["createdAt"] = 200
+	remote := newFakeRecords("dst2", map[string]interface{}{"Company": []interface{}{"rec1", "rec4", "rec2"}})
+	handlers := remote.register(local.register(map[string]func(r *http.Request) interface{}{}))
+	handlers["GET /fusion/v1/datasheets/dst1/fields"] = func(r *http.Request) interface{} {
+		return map[string]interface{}{"fields": []interface{}{
+			newTestField("fld1", "Name", apitable.FieldType_SingleText),
+			newTestField("fld2", "Phone", apitable.FieldType_Phone),
+			newTestField("fld3", "Email", apitable.FieldType_Text),
+			newTestField("fld4", "Tags", apitable.FieldType_MultiSelect),
+			newTestField("fld5", "Score", apitable.FieldType_Number),
+			newTestField("fld6", "Total", apitable.FieldType_Formula),
+		}}
+	}
+	server, credential, cpf := newTestServer(t, handlers)
+	defer server.Close()
+	dst1, _ := apitable.NewDatasheet(credential, "dst1", cpf)
+	dst2, _ := apitable.NewDatasheet(credential, "dst2", cpf)
+	options := &dedupe.Options{
+		Keys:     []*dedupe.Key{{Field: "Name", Fuzzy: true, MaxDistance: 1}},
+		Survivor: dedupe.SurvivorRule_Oldest,
+		Rules:    map[string]dedupe.MergeRule{"Tags": dedupe.MergeRule_Union, "Score": dedupe.MergeRule_Max},
+		Links:    []*dedupe.LinkRef{{Datasheet: dst2, Field: "Company"}},
+		DryRun:   true,
+	}
+
+	report, err := dedupe.Run(dst1, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Groups) != 2 || report.Groups[0].Survivor != "rec2" || strings.Join(report.Groups[0].Duplicates, ",") != "rec1,rec3" {
+		t.Fatalf("unexpected groups:\n%s", report)
+	}
+	if report.Groups[1].Survivor != "rec5" || len(local.records) != 6 || len(report.LinkRewrites) != 1 {
+		t.Fatalf("unexpected dry run:\n%s", report)
+	}
+	if text := report.String(); !strings.HasPrefix(text, "dry run: 2 duplicate groups, 3 records to delete, 1 links to rewrite") ||
+		!strings.Contains(text, `Email: (empty) => "a@x.com"`) || !strings.Contains(text, "link dst2 rec1 Company: rec1, rec4, rec2 => rec2, rec4") {
+		t.Errorf("unexpected report:\n%s", text)
+	}
+
+	options.DryRun = false
+	report, err = dedupe.Run(dst1, options)
+	if err != nil || report.Deleted != 3 {
+		t.Fatalf("unexpected run: %v, %v", report, err)
+	}
+	ids := []string{}
+	for _, record := range local.records {
+		ids = append(ids, record["recordId"].(string))
+	}
+	if strings.Join(ids, ",") != "rec2,rec4,rec5" {
+		t.Errorf("unexpected records: %v", ids)
+	}
+	b, _ := json.Marshal(local.get("rec2"))
+	if string(b) != `{"Email":"a@x.com","Name":"ＡＣＭＥ  inc ","Phone":"123","Score":5,"Tags":["b","a"],"Total":2}` {
+		t.Errorf("unexpected survivor: %s", b)
+	}
+	if links, _ := json.Marshal(remote.get("rec1")["Company"]); string(links) != `["rec2","rec4"]` {
+		t.Errorf("unexpected links: %s", links)
+	}
+
+	groups, err := dedupe.Find([]*apitable.Record{}, nil, &dedupe.Options{})
+	if err == nil || groups != nil {
+		t.Errorf("expect the missing keys error")
+	}
+}
+
+func TestDedupeChain(t *testing.T) {
+	records := []*apitable.Record{
+		toRecord(record("rec1", map[string]interface{}{"Name": "abc"})),
+		toRecord(record("rec2", map[string]interface{}{"Name": "abd"})),
+		toRecord(record("rec3", map[string]interface{}{"Name": "abde"})),
+		toRecord(record("rec4", map[string]interface{}{"Name": "abdef"})),
+	}
+	// rec2 matches rec1 and rec3, but rec3 is too far from rec1, so it's grouped with rec4
+	groups, err := dedupe.Find(records, nil, &dedupe.Options{Keys: []*dedupe.Key{{Field: "Name", Fuzzy: true, MaxDistance: 1}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 2 || groups[0].Survivor != "rec1" || strings.Join(groups[0].Duplicates, ",") != "rec2" ||
+		groups[1].Survivor != "rec3" || strings.Join(groups[1].Duplicates, ",") != "rec4" {
+		t.Errorf("unexpected groups: %+v, %+v", groups[0], groups[len(groups)-1])
+	}
+}
@@ -0,0 +1,126 @@
+package test
+
+import (
+	"github.com/apitable/apitable-sdks/apitable.go/lib/common"
+	apitable "github.com/apitable/apitable-sdks/apitable.go/lib/datasheet"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func attachmentRecord(id string, attachments ...map[string]interface{}) *apitable.Record {
+	files := []interface{}{}
+	for _, attachment := range attachments {
+		files = append(files, attachment)
+	}
+	return &apitable.Record{BaseRecord: &apitable.BaseRecord{RecordId: common.StringPtr(id), Fields: &apitable.Field{"Files": files}}}
+}
+
+func TestDownloadAttachments(t *testing.T) {
+	var mu sync.Mutex
+	requests := map[string]int{}
+	ranges := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		count := requests[r.URL.Path]
+		if r.Header.Get("Range") != "" {
+			ranges = append(ranges, r.URL.Path+" "+r.Header.Get("Range"))
+		}
+		mu.Unlock()
+		switch r.URL.Path {
+		case "/tok1":
+			_, _ = w.Write([]byte("hello"))
+		case "/tok2":
+			// fail once, then succeed
+			if count == 1 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			_, _ = w.Write([]byte("world!"))
+		case "/tok3":
+			if r.Header.Get("Range") == "bytes=3-" {
+				w.WriteHeader(http.StatusPartialContent)
+				_, _ = w.Write([]byte("umed"))
+				return
+			}
+			_, _ = w.Write([]byte("resumed"))
+		default:
+			_, _ = w.Write([]byte("short"))
+		}
+	}))
+	defer server.Close()
+	file := func(token string, name string, size int) map[string]interface{} {
+		return map[string]interface{}{"token": token, "name": name, "size": size, "url": server.URL + "/" + token}
+	}
+	records := []*apitable.Record{
+		attachmentRecord("rec1", file("tok1", "a.txt", 5), file("tok2", "b.txt", 6)),
+		attachmentRecord("rec2", file("tok1", "a.txt", 5)),
+		attachmentRecord("rec3", file("tok4", "../d.txt", 100)),
+	}
+	dir, _ := ioutil.TempDir("", "download")
+	defer os.RemoveAll(dir)
+	options := &apitable.DownloadOptions{Concurrency: 2, RetryDelay: time.Millisecond, Retries: 1}
+
+	manifest, err := apitable.DownloadAttachments(records, "Files", dir, options)
+	if err == nil || manifest == nil {
+		t.Fatalf("expect the size mismatch error: %v", err)
+	}
+	if manifest.Downloaded != 2 || manifest.Skipped != 1 || manifest.Failed != 1 || requests["/tok1"] != 1 || requests["/tok4"] != 2 {
+		t.Fatalf("unexpected manifest: %+v, %v", manifest, requests)
+	}
+	if strings.Join(manifest.Records["rec2"], ",") != "rec1/a.txt" || !strings.Contains(manifest.Files[3].Error, "size mismatch") {
+		t.Errorf("unexpected files: %v, %+v", manifest.Records, manifest.Files[3])
+	}
+	b, _ := ioutil.ReadFile(filepath.Join(dir, "rec1", "b.txt"))
+	if string(b) != "world!" || manifest.Files[1].Sha256 == "" {
+		t.Errorf("unexpected file: %s, %+v", b, manifest.Files[1])
+	}
+
+	// the downloaded tokens are skipped
+	manifest, err = apitable.DownloadAttachments(records[:2], "Files", dir, options)
+	if err != nil || manifest.Skipped != 3 || requests["/tok1"] != 1 || requests["/tok2"] != 2 {
+		t.Fatalf("unexpected manifest: %+v, %v, %v", manifest, err, requests)
+	}
+
+	// resume from the part file
+	_ = os.MkdirAll(filepath.Join(dir, "tok3"), 0755)
+	_ = ioutil.WriteFile(filepath.Join(dir, "tok3", "c.txt.part"), []byte("res"), 0644)
+	options.NameTemplate = "{token}/{base}.{ext}"
+	manifest, err = apitable.DownloadAttachments([]*apitable.Record{attachmentRecord("rec4", file("tok3", "c.txt", 7))}, "Files", dir, options)
+	if err != nil || manifest.Downloaded != 1 || strings.Join(ranges, ",") != "/tok3 bytes=3-" {
+		t.Fatalf("unexpected resume: %+v, %v, %v", manifest, err, ranges)
+	}
+	b, _ = ioutil.ReadFile(filepath.Join(dir, "tok3", "c.txt"))
+	if string(b) != "resumed" {
+		t.Errorf("unexpected resumed file: %s", b)
+	}
+
+	// the attachments of the same name are saved to different paths
+	other, _ := ioutil.TempDir("", "download")
+	defer os.RemoveAll(other)
+	options.NameTemplate = ""
+	manifest, err = apitable.DownloadAttachments([]*apitable.Record{
+		attachmentRecord("rec6", file("tok1", "a.txt", 5), file("tok2", "a.txt", 6)),
+		attachmentRecord("rec7", file("tok3", "a.txt", 7)),
+	}, "Files", other, options)
+	if err != nil || manifest.Downloaded != 3 || strings.Join(manifest.Records["rec6"], ",") != "rec6/a.txt,rec6/a-1.txt" {
+		t.Fatalf("unexpected manifest of the same names: %+v, %v", manifest, err)
+	}
+	for name, content := range map[string]string{"a.txt": "hello", "a-1.txt": "world!"} {
+		if b, _ = ioutil.ReadFile(filepath.Join(other, "rec6", name)); string(b) != content {
+			t.Errorf("unexpected file %s: %s", name, b)
+		}
+	}
+
+	options.NameTemplate = "../{name}"
+	if _, err = apitable.DownloadAttachments([]*apitable.Record{attachmentRecord("rec5", file("tok5", "e.txt", 1))}, "Files", dir, options); err == nil {
+		t.Errorf("expect the invalid template error")
+	}
+}
@@ -0,0 +1,52 @@
+package test
+
+import (
+	"encoding/json"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/common"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/space"
+	"net/http"
+	"testing"
+)
+
+func TestEmbedLinks(t *testing.T) {
+	link := map[string]interface{}{"linkId": "emb1", "url": "https://vika.cn/embed/emb1", "theme": "dark"}
+	deleted := false
+	server, credential, cpf := newTestServer(t, map[string]func(r *http.Request) interface{}{
+		"POST /fusion/v1/spaces/spc1/nodes/dst1/embedlinks": func(r *http.Request) interface{} {
+			body := map[string]map[string]interface{}{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			if body["payload"]["permissionType"] != "readOnly" || body["payload"]["viewControl"].(map[string]interface{})["viewId"] != "viw1" {
+				t.Errorf("unexpected payload: %v", body)
+			}
+			return link
+		},
+		"GET /fusion/v1/spaces/spc1/nodes/dst1/embedlinks": func(r *http.Request) interface{} {
+			return []interface{}{link}
+		},
+		"DELETE /fusion/v1/spaces/spc1/nodes/dst1/embedlinks/emb1": func(r *http.Request) interface{} {
+			deleted = true
+			return nil
+		},
+	})
+	defer server.Close()
+	spaceClient, _ := space.NewSpace(credential, "spc1", cpf)
+	theme := space.EmbedLinkTheme_Dark
+	permission := space.EmbedLinkPermission_ReadOnly
+	created, err := spaceClient.CreateEmbedLink("dst1", &space.EmbedLinkOptions{
+		Theme: &theme,
+		Payload: &space.EmbedLinkPayload{
+			PermissionType: &permission,
+			ViewControl:    &space.EmbedLinkViewControl{ViewId: common.StringPtr("viw1")},
+		},
+	})
+	if err != nil || *created.LinkId != "emb1" {
+		t.Fatalf("unexpected link: %v", err)
+	}
+	links, err := spaceClient.ListEmbedLinks("dst1")
+	if err != nil || len(links) != 1 || *links[0].Theme != space.EmbedLinkTheme_Dark {
+		t.Errorf("unexpected links: %v", err)
+	}
+	if err = spaceClient.DeleteEmbedLink("dst1", "emb1"); err != nil || !deleted {
+		t.Errorf("link is not deleted: %v", err)
+	}
+}
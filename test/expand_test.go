@@ -0,0 +1,78 @@
+package test
+
+import (
+	"fmt"
+	apitable "github.com/apitable/apitable-sdks/apitable.go/lib/datasheet"
+	"net/http"
+	"testing"
+)
+
+func TestExpandLinks(t *testing.T) {
+	orders := newFakeRecords("dst1")
+	customers := newFakeRecords("dst2")
+	companies := newFakeRecords("dst3", map[string]interface{}{"Name": "Acme"})
+	for i := 1; i <= 120; i++ {
+		customer := fmt.Sprintf("rec%d", i)
+		orders.add(map[string]interface{}{"Title": "order " + customer, "Customer": []interface{}{customer}})
+		customers.add(map[string]interface{}{"Name": "customer " + customer, "Orders": []interface{}{customer}, "Company": []interface{}{"rec1"}})
+	}
+	// the first order links a deleted customer too
+	orders.records[0]["fields"].(map[string]interface{})["Customer"] = []interface{}{"rec1", "rec999"}
+	handlers := companies.register(customers.register(orders.register(map[string]func(r *http.Request) interface{}{})))
+	queried := map[string][]int{}
+	for _, id := range []string{"dst2", "dst3"} {
+		id := id
+		list := handlers["GET /fusion/v1/datasheets/"+id+"/records"]
+		handlers["GET /fusion/v1/datasheets/"+id+"/records"] = func(r *http.Request) interface{} {
+			queried[id] = append(queried[id], len(listParam(r, "recordIds")))
+			return list(r)
+		}
+	}
+	link := func(id string, name string, foreign string) *apitable.DatasheetField {
+		return withProperty(newTestField(id, name, apitable.FieldType_MagicLink), `{"foreignDatasheetId":"`+foreign+`"}`)
+	}
+	fields := map[string][]*apitable.DatasheetField{
+		"dst1": {newTestField("fld1", "Title", apitable.FieldType_SingleText), link("fld2", "Customer", "dst2")},
+		"dst2": {newTestField("fld3", "Name", apitable.FieldType_SingleText), link("fld4", "Orders", "dst1"), link("fld5", "Company", "dst3")},
+		"dst3": {newTestField("fld6", "Name", apitable.FieldType_SingleText)},
+	}
+	for id := range fields {
+		id := id
+		handlers["GET /fusion/v1/datasheets/"+id+"/fields"] = func(r *http.Request) interface{} {
+			return map[string]interface{}{"fields": fields[id]}
+		}
+	}
+	server, credential, cpf := newTestServer(t, handlers)
+	defer server.Close()
+	datasheet, _ := apitable.NewDatasheet(credential, "dst1", cpf)
+	records, _ := datasheet.DescribeAllRecords(nil)
+
+	expanded, err := datasheet.ExpandLinks(records, "Customer", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(expanded) != 120 || fmt.Sprint(queried["dst2"]) != "[100 21]" || fmt.Sprint(queried["dst3"]) != "[1]" {
+		t.Fatalf("unexpected queries: %v", queried)
+	}
+	customer := expanded[0].Links["Customer"]
+	if len(customer) != 1 || (*customer[0].Fields)["Name"] != "customer rec1" {
+		t.Fatalf("unexpected customer: %+v", customer)
+	}
+	// the order is the ancestor of its customer
+	back := customer[0].Links["Orders"]
+	if len(back) != 1 || !back[0].Cycle || back[0].Links != nil {
+		t.Errorf("expect the cycle: %+v", back)
+	}
+	company := customer[0].Links["Company"]
+	if len(company) != 1 || (*company[0].Fields)["Name"] != "Acme" || company[0].Links != nil {
+		t.Errorf("unexpected company: %+v", company)
+	}
+
+	expanded, err = datasheet.ExpandLinks(records[:1], "Customer", 1)
+	if err != nil || expanded[0].Links["Customer"][0].Links != nil {
+		t.Errorf("unexpected expand of depth 1: %v", err)
+	}
+	if _, err = datasheet.ExpandLinks(records, "Title", 1); err == nil {
+		t.Errorf("expect the invalid link field error")
+	}
+}
@@ -0,0 +1,143 @@
+package test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/common"
+	apitable "github.com/apitable/apitable-sdks/apitable.go/lib/datasheet"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/export"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func exportFields() []*apitable.DatasheetField {
+	return []*apitable.DatasheetField{
+		newTestField("fld1", "Name", apitable.FieldType_SingleText),
+		withProperty(newTestField("fld2", "Amount", apitable.FieldType_Currency), `{"symbol":"$","precision":2}`),
+		withProperty(newTestField("fld3", "Rate", apitable.FieldType_Percent), `{"precision":1}`),
+		withProperty(newTestField("fld4", "Due", apitable.FieldType_DateTime), `{"format":"YYYY-MM-DD","includeTime":true}`),
+		newTestField("fld5", "Owner", apitable.FieldType_Member),
+		newTestField("fld6", "Files", apitable.FieldType_Attachment),
+		newTestField("fld7", "Done", apitable.FieldType_Checkbox),
+		withProperty(newTestField("fld8", "Total", apitable.FieldType_Formula), `{"valueType":"Number","format":{"precision":1}}`),
+	}
+}
+
+func exportRecords() []*apitable.Record {
+	due := float64(time.Date(2024, 1, 31, 9, 30, 0, 0, time.UTC).UnixNano() / int64(time.Millisecond))
+	return []*apitable.Record{
+		{BaseRecord: &apitable.BaseRecord{RecordId: common.StringPtr("rec1"), Fields: &apitable.Field{
+			"Name":   "Acme <Inc>",
+			"Amount": 1234.5,
+			"Rate":   0.125,
+			"Due":    due,
+			"Owner":  []interface{}{map[string]interface{}{"unitName": "Alice"}, map[string]interface{}{"unitName": "Bob"}},
+			"Files":  []interface{}{map[string]interface{}{"name": "a.png", "url": "https://s1/a.png"}},
+			"Done":   true,
+			"Total":  10.0,
+		}}},
+		{BaseRecord: &apitable.BaseRecord{RecordId: common.StringPtr("rec2"), Fields: &apitable.Field{"Name": "Empty"}}},
+	}
+}
+
+func TestExportCSV(t *testing.T) {
+	var buf bytes.Buffer
+	count, err := export.WriteCSV(&buf, export.NewSliceIterator(exportRecords()), &export.Options{
+		Fields:          exportFields(),
+		IncludeRecordId: true,
+	})
+	if err != nil || count != 2 {
+		t.Fatalf("unexpected export: %d, %v", count, err)
+	}
+	expected := "recordId,Name,Amount,Rate,Due,Owner,Files,Done,Total\n" +
+		"rec1,Acme <Inc>,$1234.50,12.5%,2024-01-31 09:30,\"Alice, Bob\",a.png,true,10.0\n" +
+		"rec2,Empty,,,,,,,\n"
+	if buf.String() != expected {
+		t.Errorf("unexpected csv:\n%s", buf.String())
+	}
+	// the attachment without the url is rendered as empty
+	renderer := &export.Renderer{AttachmentURLs: true}
+	files := []interface{}{map[string]interface{}{"name": "a.png"}, map[string]interface{}{"name": "b.png", "url": "https://s1/b.png"}}
+	if text := renderer.Render(exportFields()[5], files); text != "https://s1/b.png" {
+		t.Errorf("unexpected attachments: %s", text)
+	}
+}
+
+func TestExportNDJSON(t *testing.T) {
+	remote := newFakeRecords("dst1", map[string]interface{}{"Name": "a", "Amount": 1.0}, map[string]interface{}{"Name": "b"})
+	server, credential, cpf := newTestServer(t, remote.register(map[string]func(r *http.Request) interface{}{}))
+	defer server.Close()
+	datasheet, _ := apitable.NewDatasheet(credential, "dst1", cpf)
+
+	var buf bytes.Buffer
+	count, err := export.WriteNDJSON(&buf, export.NewPageIterator(datasheet, nil), &export.Options{Fields: exportFields()[:2]})
+	if err != nil || count != 2 {
+		t.Fatalf("unexpected export: %d, %v", count, err)
+	}
+	if buf.String() != "{\"Name\":\"a\",\"Amount\":\"$1.00\"}\n{\"Name\":\"b\",\"Amount\":\"\"}\n" {
+		t.Errorf("unexpected json lines:\n%s", buf.String())
+	}
+	buf.Reset()
+	_, err = export.WriteNDJSON(&buf, export.NewSliceIterator(exportRecords()[:1]), &export.Options{
+		Fields:    exportFields()[:3],
+		RawValues: true,
+	})
+	if err != nil || buf.String() != "{\"Name\":\"Acme \\u003cInc\\u003e\",\"Amount\":1234.5,\"Rate\":0.125}\n" {
+		t.Errorf("unexpected raw json lines: %s, %v", buf.String(), err)
+	}
+}
+
+func TestExportXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	options := &export.Options{Fields: exportFields(), SheetName: "Leads/2024"}
+	options.Renderer.AttachmentURLs = true
+	count, err := export.WriteXLSX(&buf, export.NewSliceIterator(exportRecords()), options)
+	if err != nil || count != 2 {
+		t.Fatalf("unexpected export: %d, %v", count, err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := map[string]string{}
+	for _, f := range archive.File {
+		r, _ := f.Open()
+		b, _ := ioutil.ReadAll(r)
+		_ = r.Close()
+		parts[f.Name] = string(b)
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml"} {
+		if _, ok := parts[name]; !ok {
+			t.Fatalf("missing part %s", name)
+		}
+	}
+	if !strings.Contains(parts["xl/workbook.xml"], `name="Leads2024"`) {
+		t.Errorf("unexpected workbook: %s", parts["xl/workbook.xml"])
+	}
+	sheet := struct {
+		Rows []struct {
+			R     string `xml:"r,attr"`
+			Cells []struct {
+				R    string `xml:"r,attr"`
+				Text string `xml:"is>t"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}{}
+	if err = xml.Unmarshal([]byte(parts["xl/worksheets/sheet1.xml"]), &sheet); err != nil {
+		t.Fatal(err)
+	}
+	if len(sheet.Rows) != 3 || sheet.Rows[2].R != "3" || len(sheet.Rows[2].Cells) != 1 {
+		t.Fatalf("unexpected rows: %+v", sheet.Rows)
+	}
+	cells := map[string]string{}
+	for _, cell := range sheet.Rows[1].Cells {
+		cells[cell.R] = cell.Text
+	}
+	if cells["A2"] != "Acme <Inc>" || cells["B2"] != "$1234.50" || cells["F2"] != "https://s1/a.png" || cells["H2"] != "10.0" {
+		t.Errorf("unexpected cells: %v", cells)
+	}
+}
@@ -0,0 +1,97 @@
+package test
+
+import (
+	"github.com/apitable/apitable-sdks/apitable.go/lib/common"
+	apitable "github.com/apitable/apitable-sdks/apitable.go/lib/datasheet"
+	"net/http"
+	"testing"
+)
+
+func newTestField(id string, name string, fieldType apitable.FieldType) *apitable.DatasheetField {
+	return &apitable.DatasheetField{
+		Id:   common.StringPtr(id),
+		Name: common.StringPtr(name),
+		Type: &fieldType,
+	}
+}
+
+func TestFieldMapper(t *testing.T) {
+	fields := []*apitable.DatasheetField{
+		newTestField("fld1", "Title", apitable.FieldType_SingleText),
+		newTestField("fld2", "Amount", apitable.FieldType_Number),
+	}
+	mapper := apitable.NewFieldMapperFromFields(fields, false)
+	field, err := mapper.FieldToIds(&apitable.Field{"Title": "a", "fld2": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := (*field)["fld1"]; !ok {
+		t.Errorf("field name is not translated: %v", *field)
+	}
+	formula, _ := mapper.FormulaToIds("AND({Title}='a', {Amount}>1)")
+	if formula != "AND({fld1}='a', {fld2}>1)" {
+		t.Errorf("unexpected formula: %s", formula)
+	}
+	// the braces in the string literals are not field references
+	formula, _ = mapper.FormulaToIds(`IF({Title}="{Title}", 'it\'s {Amount}', {Amount})`)
+	if formula != `IF({fld1}="{Title}", 'it\'s {Amount}', {fld2})` {
+		t.Errorf("unexpected formula with the literals: %s", formula)
+	}
+	request := apitable.NewDescribeRecordRequest()
+	request.Fields = common.StringPtrs([]string{"fld1"})
+	request.Sort = []*apitable.Sort{{Field: common.StringPtr("fld2"), Order: common.StringPtr(common.OrderDesc)}}
+	if err = mapper.RequestToNames(request); err != nil {
+		t.Fatal(err)
+	}
+	if *request.Fields[0] != "Title" || *request.Sort[0].Field != "Amount" || *request.FieldKey != common.FieldKeyName {
+		t.Errorf("request is not translated")
+	}
+	if name, _ := mapper.FieldName("Unknown"); name != "Unknown" {
+		t.Errorf("unknown field should be kept in loose mode")
+	}
+	strict := apitable.NewFieldMapperFromFields(fields, true)
+	if _, err = strict.FieldId("Unknown"); err == nil {
+		t.Errorf("unknown field should fail in strict mode")
+	}
+}
+
+func TestFieldMapperRefresh(t *testing.T) {
+	fields := []*apitable.DatasheetField{newTestField("fld1", "Title", apitable.FieldType_SingleText)}
+	describes := 0
+	server, credential, cpf := newTestServer(t, map[string]func(r *http.Request) interface{}{
+		"GET /fusion/v1/datasheets/dst1/fields": func(r *http.Request) interface{} {
+			describes++
+			return map[string]interface{}{"fields": fields}
+		},
+	})
+	defer server.Close()
+	datasheet, _ := apitable.NewDatasheet(credential, "dst1", cpf)
+	mapper, err := apitable.NewFieldMapper(datasheet, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the unknown key refreshes once, and the miss is cached
+	for i := 0; i < 3; i++ {
+		if id, _ := mapper.FieldId("Unknown"); id != "Unknown" {
+			t.Errorf("unknown field should be kept in loose mode")
+		}
+	}
+	if describes != 2 {
+		t.Errorf("unexpected describe times: %d", describes)
+	}
+	// the field created later is found after Refresh
+	fields = append(fields, newTestField("fld2", "Unknown", apitable.FieldType_Number))
+	if id, _ := mapper.FieldId("Unknown"); id != "Unknown" || describes != 2 {
+		t.Errorf("the cached miss should not refresh: %s, %d", id, describes)
+	}
+	if err = mapper.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	if id, _ := mapper.FieldId("Unknown"); id != "fld2" || describes != 3 {
+		t.Errorf("unexpected field id after refresh: %s, %d", id, describes)
+	}
+	// the new unknown key still refreshes once
+	if _, err = mapper.FieldId("Other"); err != nil || describes != 4 {
+		t.Errorf("the new unknown key should refresh: %d, %v", describes, err)
+	}
+}
@@ -0,0 +1,61 @@
+package test
+
+import (
+	"github.com/apitable/apitable-sdks/apitable.go/lib/common"
+	apitable "github.com/apitable/apitable-sdks/apitable.go/lib/datasheet"
+	"net/http"
+	"testing"
+)
+
+func TestValidateFieldProperty(t *testing.T) {
+	precision := 2
+	outOfRange := 9
+	max := 5
+	cases := []struct {
+		fieldType apitable.FieldType
+		property  interface{}
+		valid     bool
+	}{
+		{apitable.FieldType_Text, nil, true},
+		{apitable.FieldType_Text, &apitable.SingleTextFieldProperty{}, false},
+		{apitable.FieldType_SingleText, nil, true},
+		{apitable.FieldType_Number, &apitable.NumberFieldProperty{Precision: &precision}, true},
+		{apitable.FieldType_Number, &apitable.NumberFieldProperty{Precision: &outOfRange}, false},
+		{apitable.FieldType_Number, &apitable.CurrencyFieldProperty{}, false},
+		{apitable.FieldType_SingleSelect, &apitable.SelectFieldProperty{Options: []*apitable.SelectFieldOption{{Name: common.StringPtr("a")}}}, true},
+		{apitable.FieldType_MultiSelect, &apitable.SelectFieldProperty{Options: []*apitable.SelectFieldOption{{Name: common.StringPtr("a")}, {Name: common.StringPtr("a")}}}, false},
+		{apitable.FieldType_Rating, &apitable.RatingFieldProperty{Icon: common.StringPtr("star"), Max: &max}, true},
+		{apitable.FieldType_Rating, &apitable.RatingFieldProperty{Icon: common.StringPtr("star")}, false},
+		{apitable.FieldType_MagicLink, &apitable.MagicLinkFieldProperty{}, false},
+		{apitable.FieldType_MagicLink, &apitable.MagicLinkFieldProperty{ForeignDatasheetId: common.StringPtr("dst1")}, true},
+		{apitable.FieldType_Formula, &apitable.FormulaFieldProperty{Expression: common.StringPtr("1+1")}, true},
+	}
+	for _, c := range cases {
+		err := apitable.ValidateFieldProperty(c.fieldType, c.property)
+		if (err == nil) != c.valid {
+			t.Errorf("%s %T: expect valid=%v, but got %v", c.fieldType, c.property, c.valid, err)
+		}
+	}
+}
+
+func TestDeleteFieldResponse(t *testing.T) {
+	responses := []interface{}{true, map[string]interface{}{}, nil}
+	server, credential, cpf := newTestServer(t, map[string]func(r *http.Request) interface{}{
+		"DELETE /fusion/v1/spaces/spc1/datasheets/dst1/fields/fld1": func(r *http.Request) interface{} {
+			response := responses[0]
+			responses = responses[1:]
+			return response
+		},
+	})
+	defer server.Close()
+	datasheet, _ := apitable.NewDatasheet(credential, "dst1", cpf)
+	datasheet.SpaceId = "spc1"
+	// the data of the delete response can be true, an empty object or null
+	for i := 0; i < 3; i++ {
+		request := apitable.NewDeleteFieldRequest()
+		request.FieldId = common.StringPtr("fld1")
+		if err := datasheet.DeleteField(request); err != nil {
+			t.Errorf("unexpected error of the response %d: %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package test
+
+import (
+	"bytes"
+	"fmt"
+	apitable "github.com/apitable/apitable-sdks/apitable.go/lib/datasheet"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestImportCSV(t *testing.T) {
+	remote := newFakeRecords("dst1")
+	server, credential, cpf := newTestServer(t, remote.register(map[string]func(r *http.Request) interface{}{}))
+	defer server.Close()
+	datasheet, _ := apitable.NewDatasheet(credential, "dst1", cpf)
+
+	fields := []*apitable.DatasheetField{
+		newTestField("fld1", "Name", apitable.FieldType_SingleText),
+		newTestField("fld2", "Amount", apitable.FieldType_Currency),
+		newTestField("fld3", "Rate", apitable.FieldType_Percent),
+		newTestField("fld4", "Due", apitable.FieldType_DateTime),
+		newTestField("fld5", "Done", apitable.FieldType_Checkbox),
+		withProperty(newTestField("fld6", "Tags", apitable.FieldType_MultiSelect), `{"options":[{"name":"Hot"},{"name":"Cold"}]}`),
+		withProperty(newTestField("fld7", "Owner", apitable.FieldType_Member), `{"options":[{"id":"uni1","name":"Alice","type":"Member"}]}`),
+		withProperty(newTestField("fld8", "Score", apitable.FieldType_Rating), `{"max":3}`),
+	}
+	input := "name;Amount;Rate;Due;Done;Tags;Owner;Score;Note\n" +
+		"Acme;€1.234,5;12,5%;31/01/2024;yes;hot| Cold;Alice|Bob;3;x\n" +
+		"Bad;abc;;;;;;;\n" +
+		"Unknown;;;;;Warm;;;\n" +
+		"Rating;;;;no;;;4;\n" +
+		"Plain;;;;false;;;;\n"
+	progress := []apitable.ImportProgress{}
+	rejects := &bytes.Buffer{}
+	result, err := datasheet.ImportCSV(strings.NewReader(input), &apitable.ImportOptions{
+		Fields:             fields,
+		Comma:              ';',
+		DateLayouts:        []string{"02/01/2006"},
+		DecimalSeparator:   ",",
+		ThousandsSeparator: ".",
+		ListDelimiter:      "|",
+		ResolveMember: func(name string) (*apitable.UnitFieldValue, error) {
+			if name == "Bob" {
+				return &apitable.UnitFieldValue{UnitId: "uni2", UnitName: "Bob", UnitType: "Member"}, nil
+			}
+			return nil, nil
+		},
+		BatchSize: 1,
+		Rejects:   rejects,
+		Progress: func(p apitable.ImportProgress) {
+			progress = append(progress, p)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Rows != 5 || result.Imported != 2 || result.Rejected != 3 || len(result.RecordIds) != 2 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if len(result.IgnoredColumns) != 1 || result.IgnoredColumns[0] != "Note" {
+		t.Errorf("unexpected ignored columns: %v", result.IgnoredColumns)
+	}
+	if len(progress) != 2 || progress[1].Imported != 2 {
+		t.Errorf("unexpected progress: %+v", progress)
+	}
+
+	acme := remote.get("rec1")
+	due := float64(time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC).UnixNano() / int64(time.Millisecond))
+	if acme["Name"] != "Acme" || acme["Amount"] != 1234.5 || acme["Rate"] != 0.125 || acme["Due"] != due || acme["Done"] != true || acme["Score"] != 3.0 {
+		t.Errorf("unexpected converted fields: %v", acme)
+	}
+	if tags, ok := acme["Tags"].([]interface{}); !ok || len(tags) != 2 || tags[0] != "Hot" || tags[1] != "Cold" {
+		t.Errorf("unexpected tags: %v", acme["Tags"])
+	}
+	if owners, ok := acme["Owner"].([]interface{}); !ok || len(owners) != 2 || owners[1].(map[string]interface{})["unitId"] != "uni2" {
+		t.Errorf("unexpected owners: %v", acme["Owner"])
+	}
+	if plain := remote.get("rec2"); plain["Done"] != false {
+		t.Errorf("unexpected plain record: %v", plain)
+	}
+
+	lines := strings.Split(strings.TrimSpace(rejects.String()), "\n")
+	if len(lines) != 4 || !strings.HasSuffix(lines[0], ";reason") {
+		t.Fatalf("unexpected rejects: %s", rejects.String())
+	}
+	for i, reason := range []string{"Amount: invalid number abc", "Tags: unknown option Warm", "Score: invalid rating 4, the max is 3"} {
+		if !strings.HasSuffix(lines[i+1], reason) {
+			t.Errorf("unexpected reject %s, expect %s", lines[i+1], reason)
+		}
+	}
+}
+
+func TestImportCSVSeparators(t *testing.T) {
+	remote := newFakeRecords("dst1")
+	server, credential, cpf := newTestServer(t, remote.register(map[string]func(r *http.Request) interface{}{}))
+	defer server.Close()
+	datasheet, _ := apitable.NewDatasheet(credential, "dst1", cpf)
+	fields := []*apitable.DatasheetField{newTestField("fld1", "Amount", apitable.FieldType_Number)}
+
+	// the thousands separator is "." by default if the decimal separator is ","
+	result, err := datasheet.ImportCSV(strings.NewReader("Amount\n1,5\n1.234,5\n"), &apitable.ImportOptions{Fields: fields, Comma: ';', DecimalSeparator: ","})
+	if err != nil || result.Imported != 2 || remote.get("rec1")["Amount"] != 1.5 || remote.get("rec2")["Amount"] != 1234.5 {
+		t.Fatalf("unexpected result: %+v, %v, %v", result, err, remote.records)
+	}
+	if _, err = datasheet.ImportCSV(strings.NewReader("Amount\n1,5\n"), &apitable.ImportOptions{Fields: fields, ThousandsSeparator: "."}); err == nil {
+		t.Errorf("expect the error of the same separators")
+	}
+
+	// only the currency symbols and the whitespaces around the number are removed
+	remote.records = nil
+	input := "Amount\n-$5\n\"$ 1,000.50\"\n¥12\n12abc\nabc12\nNaN\n0x10\n"
+	result, err = datasheet.ImportCSV(strings.NewReader(input), &apitable.ImportOptions{Fields: fields})
+	if err != nil || result.Imported != 3 || result.Rejected != 4 {
+		t.Fatalf("unexpected result: %+v, %v", result, err)
+	}
+	amounts := []interface{}{}
+	for _, record := range remote.records {
+		amounts = append(amounts, record["fields"].(map[string]interface{})["Amount"])
+	}
+	if fmt.Sprint(amounts) != "[-5 1000.5 12]" {
+		t.Errorf("unexpected amounts: %v", amounts)
+	}
+}
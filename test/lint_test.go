@@ -0,0 +1,227 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/common"
+	apitable "github.com/apitable/apitable-sdks/apitable.go/lib/datasheet"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/lint"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func issueKeys(report *lint.Report) string {
+	keys := []string{}
+	for _, issue := range report.Issues {
+		keys = append(keys, issue.Rule+"/"+issue.RecordId+"/"+issue.Field)
+	}
+	return strings.Join(keys, " ")
+}
+
+func TestLint(t *testing.T) {
+	now := time.Now()
+	old := float64(now.AddDate(0, -3, 0).UnixNano() / int64(time.Millisecond))
+	recent := float64(now.AddDate(0, 0, -1).UnixNano() / int64(time.Millisecond))
+	fields := []*apitable.DatasheetField{
+		newTestField("fld1", "Name", apitable.FieldType_SingleText),
+		newTestField("fld2", "Website", apitable.FieldType_URL),
+		newTestField("fld3", "Phone", apitable.FieldType_Phone),
+		withProperty(newTestField("fld4", "Status", apitable.FieldType_SingleSelect), `{"options":[{"id":"opt1","name":"Open"},{"id":"opt2","name":"Closed"}]}`),
+		withProperty(newTestField("fld5", "Company", apitable.FieldType_MagicLink), `{"foreignDatasheetId":"dst2"}`),
+		withProperty(newTestField("fld6", "Score", apitable.FieldType_Rating), `{"icon":"star","max":3}`),
+		withProperty(newTestField("fld7", "Total", apitable.FieldType_Formula), `{"expression":"{Amount} *","hasError":true}`),
+		newTestField("fld8", "Updated", apitable.FieldType_DateTime),
+		newTestField("fld9", "Email", apitable.FieldType_SingleText),
+	}
+	leads := newFakeRecords("dst1",
+		map[string]interface{}{"Name": "Acme", "Website": map[string]interface{}{"text": "https://acme.com", "title": "Acme"}, "Phone": "+1 (555) 010-0000",
+			"Status": "Open", "Company": []interface{}{"rec1"}, "Score": 3.0, "Updated": recent, "Email": "sales@acme.com"},
+		map[string]interface{}{"Name": "", "Website": "not a url", "Phone": "call me", "Status": "Pending", "Company": []interface{}{"rec1", "rec9"},
+			"Score": 5.0, "Updated": old, "Email": "nobody"},
+		map[string]interface{}{"Name": "Globex", "Website": "www.globex.com", "Phone": "010 1234 5678", "Updated": recent},
+	)
+	companies := newFakeRecords("dst2", map[string]interface{}{"Title": "Acme Corp"})
+	handlers := companies.register(leads.register(map[string]func(r *http.Request) interface{}{
+		"GET /fusion/v1/datasheets/dst1/fields": func(r *http.Request) interface{} {
+			return map[string]interface{}{"fields": fields}
+		},
+	}))
+	server, credential, cpf := newTestServer(t, handlers)
+	defer server.Close()
+	datasheet, _ := apitable.NewDatasheet(credential, "dst1", cpf)
+
+	config, err := lint.ParseConfig([]byte(`{
+		"required": ["Name", "Owner"],
+		"staleAfter": "30d",
+		"staleField": "Updated",
+		"severities": {"url": "error"},
+		"rules": [{"name": "email", "field": "Email", "pattern": "^[^@]+@[^@]+$", "severity": "warning", "message": "{field} is not an email: {value}"}]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the custom rule in Go
+	noGlobex := lint.NewRule("no-globex", func(ctx *lint.Context, record *apitable.Record) []*lint.Issue {
+		if record != nil && lint.Value(record, "Name") == "Globex" {
+			return []*lint.Issue{{Field: "Name", Severity: lint.Severity_Info, Message: "Globex is a competitor"}}
+		}
+		return nil
+	})
+	linter, err := lint.New(config, noGlobex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(linter.Rules(), ",") != "formula,required,url,phone,select,link,rating,stale,email,no-globex" {
+		t.Fatalf("unexpected rules: %v", linter.Rules())
+	}
+	report, err := linter.Run(datasheet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "formula//Total required//Owner " +
+		"required/rec2/Name url/rec2/Website phone/rec2/Phone select/rec2/Status link/rec2/Company rating/rec2/Score stale/rec2/Updated email/rec2/Email " +
+		"no-globex/rec3/Name"
+	if issueKeys(report) != expected {
+		t.Fatalf("unexpected issues: %s", issueKeys(report))
+	}
+	if report.DatasheetId != "dst1" || report.Records != 3 || report.Counts["required"] != 2 || !report.HasErrors() {
+		t.Errorf("unexpected report: %+v", report)
+	}
+	severities := map[string]lint.Severity{}
+	for _, issue := range report.Issues {
+		severities[issue.Rule] = issue.Severity
+	}
+	if severities["url"] != lint.Severity_Error || severities["phone"] != lint.Severity_Warning || severities["stale"] != lint.Severity_Info ||
+		severities["email"] != lint.Severity_Warning || severities["no-globex"] != lint.Severity_Info {
+		t.Errorf("unexpected severities: %v", severities)
+	}
+	if issue := report.Issues[6]; issue.Value != "rec9" {
+		t.Errorf("unexpected dangling link: %+v", issue)
+	}
+
+	var buf bytes.Buffer
+	if err = report.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	decoded := &lint.Report{}
+	if err = json.Unmarshal(buf.Bytes(), decoded); err != nil || len(decoded.Issues) != len(report.Issues) || decoded.Issues[9].Message != "Email is not an email: nobody" {
+		t.Errorf("unexpected json report: %s, %v", buf.String(), err)
+	}
+	text := report.String()
+	for _, line := range []string{
+		"dst1: 3 records, 11 issues (7 errors, 2 warnings, 2 infos)",
+		"error required (2):\n  -: The required field Owner is not found\n  rec2: Name is required\n",
+		"warning email (1):\n  rec2: Email is not an email: nobody\n",
+		"info no-globex (1):\n  rec3: Globex is a competitor\n",
+	} {
+		if !strings.Contains(text, line) {
+			t.Errorf("expect %q in the report:\n%s", line, text)
+		}
+	}
+
+	// Lint checks the records without the server, the stale rule uses the creation time by default
+	linter, _ = lint.New(&lint.Config{StaleAfter: lint.Duration(24 * time.Hour), Disabled: []string{"formula", "url", "phone"}})
+	stale := toRecord(record("rec1", map[string]interface{}{"Status": "Closed", "Company": []interface{}{"rec3"}}))
+	stale.CreatedAt = common.Int64Ptr(int64(old))
+	report = linter.Lint(&lint.Context{Fields: fields, Now: now}, []*apitable.Record{stale})
+	if issueKeys(report) != "stale/rec1/" {
+		t.Errorf("unexpected issues: %s", issueKeys(report))
+	}
+
+	for _, invalid := range []string{
+		`{"staleAfter": "soon"}`,
+		`{"unknown": true}`,
+		`{"rules": [{"name": "x", "field": "Name", "pattern": "("}]}`,
+		`{"rules": [{"name": "url", "field": "Name"}]}`,
+		`{"phonePattern": "["}`,
+		`{"rules": [null]}`,
+		`{"rules": [{"name": "x", "field": "Name"}, {"name": "x", "field": "Email"}]}`,
+		`{"rules": [{"name": "x", "field": "Name", "severity": "fatal"}]}`,
+		`{"severities": {"url": "high"}}`,
+	} {
+		config, err := lint.ParseConfig([]byte(invalid))
+		if err == nil {
+			_, err = lint.New(config)
+		}
+		if err == nil {
+			t.Errorf("expect the error of the config %s", invalid)
+		}
+	}
+}
+
+func TestLintYAMLConfig(t *testing.T) {
+	expected, _ := lint.ParseConfig([]byte(`{
+		"required": ["Name", "Owner"],
+		"staleAfter": "30d",
+		"disabled": ["url", "phone"],
+		"severities": {"link": "warning", "stale": "error"},
+		"rules": [
+			{"name": "email", "field": "E-mail: work", "pattern": "^[^@]+@[^@]+$ #x", "severity": "warning", "message": "{field} isn't an email:\n{value}\n"},
+			{"name": "score", "field": "Score", "min": 0, "max": 9.5, "values": ["a", "b"], "required": true}
+		]
+	}`))
+	yaml := `---
+# the lint config
+required:
+  - Name
+  - "Owner"
+staleAfter: 30d   # one month
+disabled: [url, 'phone']
+severities: {link: warning, stale: error}
+rules:
+  - name: email
+    field: "E-mail: work"
+    pattern: '^[^@]+@[^@]+$ #x'
+    severity: warning
+    message: |
+      {field} isn't an email:
+      {value}
+  -
+    name: score
+    field: Score
+    min: 0
+    max: 9.5
+    values:
+    - a
+    - b
+    required: true
+`
+	dir, _ := ioutil.TempDir("", "lint")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "lint.yml")
+	_ = ioutil.WriteFile(path, []byte(yaml), 0644)
+	config, err := lint.LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, _ := json.Marshal(expected)
+	b, _ := json.Marshal(config)
+	if string(a) != string(b) {
+		t.Errorf("unexpected yaml config:\n%s\n%s", b, a)
+	}
+	for _, invalid := range []string{
+		"required: [Name",
+		"rules:\n  - name: x\n   field: y",
+		"staleAfter: 1d\nstaleAfter: 2d",
+		"unknown: true",
+		"- a\n- b",
+		"required:\n\t- Name",
+	} {
+		if _, err = lint.ParseYAMLConfig([]byte(invalid)); err == nil {
+			t.Errorf("expect the error of the yaml %q", invalid)
+		}
+	}
+	// the null rule is rejected by the linter instead of panic
+	config, err = lint.ParseYAMLConfig([]byte("rules:\n  -\n"))
+	if err == nil {
+		_, err = lint.New(config)
+	}
+	if err == nil {
+		t.Errorf("expect the error of the null rule")
+	}
+}
@@ -0,0 +1,136 @@
+package test
+
+import (
+	"bytes"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/common"
+	apitable "github.com/apitable/apitable-sdks/apitable.go/lib/datasheet"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/query"
+	"testing"
+	"time"
+)
+
+func queryRecords() ([]*apitable.Record, []*apitable.DatasheetField) {
+	fields := []*apitable.DatasheetField{
+		newTestField("fld1", "Title", apitable.FieldType_SingleText),
+		withProperty(newTestField("fld2", "Amount", apitable.FieldType_Currency), `{"symbol":"$","precision":2}`),
+		newTestField("fld3", "Owner", apitable.FieldType_Member),
+		newTestField("fld4", "Date", apitable.FieldType_DateTime),
+		newTestField("fld5", "Tags", apitable.FieldType_MultiSelect),
+	}
+	day := func(month time.Month, d int) float64 {
+		return float64(time.Date(2024, month, d, 12, 0, 0, 0, time.UTC).UnixNano() / int64(time.Millisecond))
+	}
+	owner := func(name string) []interface{} {
+		return []interface{}{map[string]interface{}{"unitName": name}}
+	}
+	rows := []map[string]interface{}{
+		{"Title": "a", "Amount": 10.5, "Owner": owner("Alice"), "Date": day(1, 5), "Tags": []interface{}{"x", "y"}},
+		{"Title": "b", "Amount": 20.0, "Owner": owner("Bob"), "Date": day(1, 20), "Tags": []interface{}{"x"}},
+		{"Title": "c | d", "Amount": 5.25, "Owner": owner("Alice"), "Date": day(2, 1)},
+		{"Title": "e", "Owner": owner("Alice"), "Date": day(2, 10)},
+	}
+	records := []*apitable.Record{}
+	for i, row := range rows {
+		fields := apitable.Field{}
+		for key, value := range row {
+			fields[key] = value
+		}
+		records = append(records, &apitable.Record{BaseRecord: &apitable.BaseRecord{RecordId: common.StringPtr(string(rune('1' + i))), Fields: &fields}})
+	}
+	return records, fields
+}
+
+func TestQueryAggregate(t *testing.T) {
+	records, fields := queryRecords()
+	engine := query.New(records, fields)
+
+	table, err := engine.Aggregate(
+		[]*query.GroupBy{{Field: "Owner"}, {Field: "Date", Bucket: query.Bucket_Month}},
+		[]*query.Measure{{Field: "Amount", Aggregate: query.Aggregate_Sum}, {Aggregate: query.Aggregate_Count}, {Field: "Amount", Aggregate: query.Aggregate_Avg, As: "avg"}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	_ = table.WriteCSV(&buf)
+	expected := "Owner,Date,sum(Amount),count,avg\n" +
+		"Alice,2024-01,10.50,1,10.50\n" +
+		"Alice,2024-02,5.25,2,5.25\n" +
+		"Bob,2024-01,20.00,1,20.00\n"
+	if buf.String() != expected {
+		t.Errorf("unexpected csv:\n%s", buf.String())
+	}
+
+	// the record is in the group of each tag
+	table, _ = engine.Filter(func(record *apitable.Record) bool {
+		return query.Value(record, "Amount") != nil
+	}).Aggregate([]*query.GroupBy{{Field: "Tags"}}, []*query.Measure{{Field: "Amount", Aggregate: query.Aggregate_Max}, {Field: "Owner", Aggregate: query.Aggregate_CountDistinct}})
+	buf.Reset()
+	_ = table.WriteCSV(&buf)
+	if buf.String() != "Tags,max(Amount),countDistinct(Owner)\nx,20.00,2\ny,10.50,1\n,5.25,1\n" {
+		t.Errorf("unexpected tags csv:\n%s", buf.String())
+	}
+
+	if _, err = engine.Aggregate([]*query.GroupBy{{Field: "Missing"}}, nil); err == nil {
+		t.Errorf("expect the field not found error")
+	}
+}
+
+func TestQueryPivot(t *testing.T) {
+	records, fields := queryRecords()
+	engine := query.New(records, fields)
+	table, err := engine.Pivot(&query.GroupBy{Field: "Owner"}, &query.GroupBy{Field: "Date", Bucket: query.Bucket_Quarter}, &query.Measure{Field: "Amount", Aggregate: query.Aggregate_Sum})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	_ = table.WriteMarkdown(&buf)
+	expected := "| Owner | 2024-Q1 |\n| --- | ---: |\n| Alice | 15.75 |\n| Bob | 20.00 |\n"
+	if buf.String() != expected {
+		t.Errorf("unexpected markdown:\n%s", buf.String())
+	}
+
+	table, _ = engine.Pivot(&query.GroupBy{Field: "Date", Bucket: query.Bucket_Month}, &query.GroupBy{Field: "Owner"}, &query.Measure{Aggregate: query.Aggregate_Count})
+	buf.Reset()
+	_ = table.WriteCSV(&buf)
+	if buf.String() != "Date,Alice,Bob\n2024-01,1,1\n2024-02,2,\n" {
+		t.Errorf("unexpected pivot csv:\n%s", buf.String())
+	}
+
+	table, _ = engine.Select("Title", "Amount", "Owner")
+	buf.Reset()
+	_ = table.WriteMarkdown(&buf)
+	if buf.String() != "| Title | Amount | Owner |\n| --- | ---: | --- |\n| a | 10.50 | Alice |\n| b | 20.00 | Bob |\n| c \\| d | 5.25 | Alice |\n| e |  | Alice |\n" {
+		t.Errorf("unexpected select markdown:\n%s", buf.String())
+	}
+}
+
+func TestQueryPercent(t *testing.T) {
+	fields := []*apitable.DatasheetField{
+		newTestField("fld1", "Team", apitable.FieldType_SingleText),
+		withProperty(newTestField("fld2", "Rate", apitable.FieldType_Percent), `{"precision":1}`),
+	}
+	records := []*apitable.Record{
+		toRecord(record("rec1", map[string]interface{}{"Team": "a", "Rate": 0.125})),
+		toRecord(record("rec2", map[string]interface{}{"Team": "b", "Rate": 0.5})),
+	}
+	engine := query.New(records, fields)
+	table, err := engine.Select("Team", "Rate")
+	if err != nil || table.Rows[0][1] != 0.125 || table.Text(0, 1) != "12.5%" {
+		t.Errorf("unexpected percent: %v, %v", table, err)
+	}
+	table, _ = engine.Aggregate([]*query.GroupBy{{Field: "Team"}}, []*query.Measure{{Field: "Rate", Aggregate: query.Aggregate_Avg}, {Aggregate: query.Aggregate_Count}})
+	if table.Text(1, 1) != "50.0%" || table.Text(1, 2) != "1" {
+		t.Errorf("unexpected aggregated percent: %s, %s", table.Text(1, 1), table.Text(1, 2))
+	}
+	table, _ = engine.Pivot(&query.GroupBy{Field: "Team"}, &query.GroupBy{Field: "Team"}, &query.Measure{Field: "Rate", Aggregate: query.Aggregate_Max})
+	if table.Text(0, 1) != "12.5%" || table.Text(0, 0) != "a" {
+		t.Errorf("unexpected pivot percent: %v", table.Rows)
+	}
+	if _, err = engine.Pivot(nil, &query.GroupBy{Field: "Team"}, &query.Measure{Aggregate: query.Aggregate_Count}); err == nil {
+		t.Errorf("expect the error of the nil group by")
+	}
+	if _, err = engine.Pivot(&query.GroupBy{Field: "Team"}, &query.GroupBy{Field: "Team"}, nil); err == nil {
+		t.Errorf("expect the error of the nil measure")
+	}
+}
@@ -0,0 +1,24 @@
+package test
+
+import (
+	"github.com/apitable/apitable-sdks/apitable.go/lib/common"
+	apitable "github.com/apitable/apitable-sdks/apitable.go/lib/datasheet"
+	"net/http"
+	"testing"
+)
+
+func TestDeleteRecordsResponse(t *testing.T) {
+	remote := newFakeRecords("dst1", map[string]interface{}{"Name": "a"}, map[string]interface{}{"Name": "b"})
+	server, credential, cpf := newTestServer(t, remote.register(map[string]func(r *http.Request) interface{}{}))
+	defer server.Close()
+	datasheet, _ := apitable.NewDatasheet(credential, "dst1", cpf)
+	// the data of the delete response is true
+	request := apitable.NewDeleteRecordsRequest()
+	request.RecordIds = common.StringPtrs([]string{"rec1"})
+	if err := datasheet.DeleteRecords(request); err != nil {
+		t.Fatal(err)
+	}
+	if len(remote.records) != 1 || remote.get("rec2") == nil {
+		t.Errorf("unexpected records after deletion: %v", remote.records)
+	}
+}
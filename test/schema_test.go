@@ -0,0 +1,67 @@
+package test
+
+import (
+	"encoding/json"
+	apitable "github.com/apitable/apitable-sdks/apitable.go/lib/datasheet"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func withProperty(field *apitable.DatasheetField, property string) *apitable.DatasheetField {
+	raw := json.RawMessage(property)
+	field.Property = &raw
+	return field
+}
+
+func TestDiffSchema(t *testing.T) {
+	before := &apitable.Schema{
+		Version: apitable.SchemaVersion,
+		Fields: []*apitable.DatasheetField{
+			newTestField("fld1", "Title", apitable.FieldType_SingleText),
+			withProperty(newTestField("fld2", "Status", apitable.FieldType_SingleSelect), `{"options":[{"id":"opt1","name":"Open"}]}`),
+			withProperty(newTestField("fld3", "Owner", apitable.FieldType_MagicLink), `{"foreignDatasheetId":"dst1"}`),
+			newTestField("fld4", "Removed", apitable.FieldType_Text),
+		},
+	}
+	after := &apitable.Schema{
+		Version: apitable.SchemaVersion,
+		Fields: []*apitable.DatasheetField{
+			newTestField("fld1", "Name", apitable.FieldType_Text),
+			withProperty(newTestField("fld2", "Status", apitable.FieldType_SingleSelect), `{"options":[{"id":"opt1","name":"Open"},{"id":"opt2","name":"Closed"}]}`),
+			withProperty(newTestField("fld3", "Owner", apitable.FieldType_MagicLink), `{"foreignDatasheetId":"dst2"}`),
+			newTestField("fld5", "Added", apitable.FieldType_Number),
+		},
+	}
+	dir, _ := ioutil.TempDir("", "schema")
+	defer os.RemoveAll(dir)
+	filePath := filepath.Join(dir, "schema.json")
+	if err := apitable.WriteSchema(filePath, before); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := apitable.ReadSchema(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff := apitable.DiffSchema(saved, after)
+	expected := map[apitable.SchemaChangeType]string{
+		apitable.SchemaChange_FieldRenamed:         "fld1",
+		apitable.SchemaChange_FieldTypeChanged:     "fld1",
+		apitable.SchemaChange_SelectOptionsChanged: "fld2",
+		apitable.SchemaChange_LinkTargetChanged:    "fld3",
+		apitable.SchemaChange_FieldRemoved:         "fld4",
+		apitable.SchemaChange_FieldAdded:           "fld5",
+	}
+	if len(diff.Changes) != len(expected) {
+		t.Fatalf("unexpected changes:\n%s", diff)
+	}
+	for _, change := range diff.Changes {
+		if expected[change.Type] != change.Id {
+			t.Errorf("unexpected change: %s", change)
+		}
+	}
+	if apitable.DiffSchema(after, after).HasDrift() {
+		t.Errorf("the same schema should not drift")
+	}
+}
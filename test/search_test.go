@@ -0,0 +1,144 @@
+package test
+
+import (
+	"fmt"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/common"
+	apitable "github.com/apitable/apitable-sdks/apitable.go/lib/datasheet"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/search"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func hitIds(hits []*search.Hit) string {
+	ids := []string{}
+	for _, hit := range hits {
+		ids = append(ids, hit.RecordId)
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestTokenize(t *testing.T) {
+	terms := search.Tokenize("Hello, Ｗｏｒｌｄ 2024 数据表格 ｶﾞｲﾄﾞ e-mail 中")
+	if strings.Join(terms, " ") != "hello world 2024 数据 据表 表格 ガイ イド e mail 中" {
+		t.Errorf("unexpected terms: %v", terms)
+	}
+}
+
+func TestSearchIndex(t *testing.T) {
+	fields := []*apitable.DatasheetField{
+		newTestField("fld1", "Name", apitable.FieldType_SingleText),
+		newTestField("fld2", "Status", apitable.FieldType_SingleSelect),
+		newTestField("fld3", "Owner", apitable.FieldType_Member),
+		withProperty(newTestField("fld4", "Company", apitable.FieldType_MagicLink), `{"foreignDatasheetId":"dst3"}`),
+		newTestField("fld5", "Notes", apitable.FieldType_Text),
+		newTestField("fld6", "Amount", apitable.FieldType_Number),
+	}
+	leads := newFakeRecords("dst1",
+		map[string]interface{}{"Name": "Apple pie", "Status": "Open", "Owner": []interface{}{map[string]interface{}{"unitName": "Alice"}}, "Company": []interface{}{"rec1"}},
+		map[string]interface{}{"Name": "Apple apple juice", "Status": "Closed", "Notes": "open the 数据表 later", "Amount": 42.0},
+		map[string]interface{}{"Name": "Application form with a very long description of the application", "Status": "Open"},
+		map[string]interface{}{"Name": "数据分析", "Company": []interface{}{"rec2"}},
+	)
+	companies := newFakeRecords("dst3", map[string]interface{}{"Title": "Acme Corp"}, map[string]interface{}{"Title": "Globex"})
+	handlers := companies.register(leads.register(map[string]func(r *http.Request) interface{}{
+		"GET /fusion/v1/datasheets/dst1/fields": func(r *http.Request) interface{} {
+			return map[string]interface{}{"fields": fields}
+		},
+		"GET /fusion/v1/datasheets/dst3/fields": func(r *http.Request) interface{} {
+			return map[string]interface{}{"fields": []*apitable.DatasheetField{newTestField("fld9", "Title", apitable.FieldType_SingleText)}}
+		},
+	}))
+	server, credential, cpf := newTestServer(t, handlers)
+	defer server.Close()
+	datasheet, _ := apitable.NewDatasheet(credential, "dst1", cpf)
+	records, _ := datasheet.DescribeAllRecords(nil)
+	links, err := search.ResolveLinks(datasheet, records)
+	if err != nil || links["rec1"] != "Acme Corp" || links["rec2"] != "Globex" {
+		t.Fatalf("unexpected links: %v, %v", links, err)
+	}
+
+	index := search.NewIndex(fields, &search.Options{LinkDisplay: links})
+	index.Add(records...)
+	if strings.Join(index.Fields(), ",") != "Name,Status,Owner,Company,Notes" || index.Len() != 4 {
+		t.Fatalf("unexpected index: %v, %d", index.Fields(), index.Len())
+	}
+	cases := map[string]string{
+		// the higher term frequency and the shorter field rank first
+		"apple":            "rec2,rec1",
+		"APPLE Pie":        "rec1",
+		"app*":             "rec3,rec2,rec1",
+		"status:open":      "rec1,rec3",
+		"open":             "rec1,rec2,rec3",
+		"name:open":        "",
+		"owner:alice":      "rec1",
+		"company:acme":     "rec1",
+		`"Company":globex`: "rec4",
+		"数据":               "rec4,rec2",
+		"数":                "rec4,rec2",
+		"析":                "rec4",
+		"表":                "rec2",
+		"数据分析":             "rec4",
+		"missing":          "",
+		"42":               "",
+	}
+	for query, expected := range cases {
+		hits, err := index.Search(query, 0)
+		if err != nil || hitIds(hits) != expected {
+			t.Errorf("unexpected hits of %q: %s, %v", query, hitIds(hits), err)
+		}
+	}
+	hits, _ := index.Search("status:open app*", 1)
+	if len(hits) != 1 || hits[0].RecordId != "rec3" || strings.Join(hits[0].Fields, ",") != "Name,Status" {
+		t.Errorf("unexpected hits: %+v", hits[0])
+	}
+	if _, err = index.Search(`"Unknown":x`, 0); err == nil {
+		t.Errorf("expect the field not indexed error")
+	}
+
+	// the records are updated incrementally by the watch events
+	updated := record("rec1", map[string]interface{}{"Name": "Banana bread", "Status": "Open"})
+	index.Apply(
+		&apitable.RecordEvent{Type: apitable.RecordEvent_Updated, RecordId: "rec1", Record: toRecord(updated)},
+		&apitable.RecordEvent{Type: apitable.RecordEvent_Deleted, RecordId: "rec3"},
+		&apitable.RecordEvent{Type: apitable.RecordEvent_Created, RecordId: "rec5", Record: toRecord(record("rec5", map[string]interface{}{"Name": "Apple tart"}))},
+	)
+	for query, expected := range map[string]string{"apple": "rec5,rec2", "banana": "rec1", "status:open": "rec1", "app*": "rec5,rec2"} {
+		if hits, _ := index.Search(query, 0); hitIds(hits) != expected {
+			t.Errorf("unexpected hits of %q after the updates: %s", query, hitIds(hits))
+		}
+	}
+
+	dir, _ := ioutil.TempDir("", "search")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "index", "leads.idx")
+	if err = index.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := search.Load(path, fields, nil)
+	if err != nil || loaded.Len() != 4 {
+		t.Fatalf("unexpected loaded index: %d, %v", loaded.Len(), err)
+	}
+	for _, query := range []string{"apple", "数据", "company:globex", "ban*"} {
+		expected, _ := index.Search(query, 0)
+		actual, _ := loaded.Search(query, 0)
+		if fmt.Sprintf("%s %v", hitIds(expected), expected[0].Score) != fmt.Sprintf("%s %v", hitIds(actual), actual[0].Score) {
+			t.Errorf("unexpected loaded hits of %q: %s", query, hitIds(actual))
+		}
+	}
+	// the index of other fields is not loaded
+	if other, err := search.Load(path, fields, &search.Options{Fields: []string{"Name"}}); err != nil || other.Len() != 0 {
+		t.Errorf("expect the empty index: %v", err)
+	}
+}
+
+func toRecord(fields map[string]interface{}) *apitable.Record {
+	values := apitable.Field{}
+	for key, value := range fields["fields"].(map[string]interface{}) {
+		values[key] = value
+	}
+	return &apitable.Record{BaseRecord: &apitable.BaseRecord{RecordId: common.StringPtr(fields["recordId"].(string)), Fields: &values}}
+}
@@ -0,0 +1,191 @@
+package test
+
+import (
+	"encoding/json"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/common"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/common/profile"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// newTestServer start a fake api server, the handlers are keyed by `METHOD path`
+func newTestServer(t *testing.T, handlers map[string]func(r *http.Request) interface{}) (*httptest.Server, *common.Credential, *profile.ClientProfile) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler, ok := handlers[r.Method+" "+r.URL.Path]
+		if !ok {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"code":    200,
+			"success": true,
+			"message": "SUCCESS",
+			"data":    handler(r),
+		})
+	}))
+	cpf := profile.NewClientProfile()
+	cpf.HttpProfile.Scheme = "HTTP"
+	cpf.HttpProfile.Domain = strings.TrimPrefix(server.URL, "http://")
+	return server, common.NewCredential("token"), cpf
+}
+
+func node(id string, name string, nodeType string) map[string]interface{} {
+	return map[string]interface{}{"id": id, "name": name, "type": nodeType}
+}
+
+// spaceTreeHandlers serve the node tree: Sales/2024/Leads, Sales/Leads, Docs
+func spaceTreeHandlers() map[string]func(r *http.Request) interface{} {
+	children := map[string][]interface{}{
+		"fod1": {node("fod2", "2024", "Folder"), node("dst2", "Leads", "Datasheet")},
+		"fod2": {node("dst1", "Leads", "Datasheet")},
+	}
+	handlers := map[string]func(r *http.Request) interface{}{
+		"GET /fusion/v1/spaces/spc1/nodes": func(r *http.Request) interface{} {
+			return map[string]interface{}{"nodes": []interface{}{node("fod1", "Sales", "Folder"), node("dst3", "Docs", "Datasheet")}}
+		},
+	}
+	for id, nodes := range children {
+		id, nodes := id, nodes
+		handlers["GET /fusion/v1/spaces/spc1/nodes/"+id] = func(r *http.Request) interface{} {
+			return map[string]interface{}{"id": id, "type": "Folder", "children": nodes}
+		}
+	}
+	return handlers
+}
+
+// fakeRecords the records of a fake datasheet, served by the record handlers
+type fakeRecords struct {
+	mu      sync.Mutex
+	path    string
+	records []map[string]interface{}
+	nextId  int
+}
+
+// newFakeRecords init the fake records, the record ids are assigned in order: rec1, rec2...
+func newFakeRecords(datasheetId string, records ...map[string]interface{}) *fakeRecords {
+	f := &fakeRecords{path: "/fusion/v1/datasheets/" + datasheetId + "/records"}
+	for _, fields := range records {
+		f.add(fields)
+	}
+	return f
+}
+
+func (f *fakeRecords) add(fields map[string]interface{}) map[string]interface{} {
+	f.nextId++
+	created := record("rec"+strconv.Itoa(f.nextId), fields)
+	f.records = append(f.records, created)
+	return created
+}
+
+// get get the fields of the record, nil if not found
+func (f *fakeRecords) get(recordId string) map[string]interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, r := range f.records {
+		if r["recordId"] == recordId {
+			return r["fields"].(map[string]interface{})
+		}
+	}
+	return nil
+}
+
+// listParam get the list query param, such as: recordIds.0, recordIds.1
+func listParam(r *http.Request, name string) []string {
+	keys := []string{}
+	for key := range r.URL.Query() {
+		if strings.HasPrefix(key, name+".") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, _ := strconv.Atoi(strings.TrimPrefix(keys[i], name+"."))
+		b, _ := strconv.Atoi(strings.TrimPrefix(keys[j], name+"."))
+		return a < b
+	})
+	values := make([]string, len(keys))
+	for i, key := range keys {
+		values[i] = r.URL.Query().Get(key)
+	}
+	return values
+}
+
+// register add the handlers to describe, create, modify and delete the records
+func (f *fakeRecords) register(handlers map[string]func(r *http.Request) interface{}) map[string]func(r *http.Request) interface{} {
+	handlers["GET "+f.path] = func(r *http.Request) interface{} {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		ids := map[string]bool{}
+		for _, id := range listParam(r, "recordIds") {
+			ids[id] = true
+		}
+		records := []interface{}{}
+		for _, r := range f.records {
+			if len(ids) == 0 || ids[r["recordId"].(string)] {
+				records = append(records, r)
+			}
+		}
+		return recordPage(records...)
+	}
+	handlers["POST "+f.path] = func(r *http.Request) interface{} {
+		body := struct {
+			Records []struct {
+				Fields map[string]interface{} `json:"fields"`
+			} `json:"records"`
+		}{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		created := []interface{}{}
+		for _, r := range body.Records {
+			created = append(created, f.add(r.Fields))
+		}
+		return map[string]interface{}{"records": created}
+	}
+	handlers["PATCH "+f.path] = func(r *http.Request) interface{} {
+		body := struct {
+			Records []struct {
+				RecordId string                 `json:"recordId"`
+				Fields   map[string]interface{} `json:"fields"`
+			} `json:"records"`
+		}{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		modified := []interface{}{}
+		for _, patch := range body.Records {
+			for _, r := range f.records {
+				if r["recordId"] == patch.RecordId {
+					fields := r["fields"].(map[string]interface{})
+					for key, value := range patch.Fields {
+						fields[key] = value
+					}
+					modified = append(modified, r)
+				}
+			}
+		}
+		return map[string]interface{}{"records": modified}
+	}
+	handlers["DELETE "+f.path] = func(r *http.Request) interface{} {
+		ids := map[string]bool{}
+		for _, id := range listParam(r, "recordIds") {
+			ids[id] = true
+		}
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		kept := []map[string]interface{}{}
+		for _, r := range f.records {
+			if !ids[r["recordId"].(string)] {
+				kept = append(kept, r)
+			}
+		}
+		f.records = kept
+		return true
+	}
+	return handlers
+}
@@ -0,0 +1,156 @@
+package test
+
+import (
+	"github.com/apitable/apitable-sdks/apitable.go/lib/common"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/space"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestWalkNodes(t *testing.T) {
+	handlers := spaceTreeHandlers()
+	handlers["GET /fusion/v2/spaces/spc1/nodes"] = func(r *http.Request) interface{} {
+		if r.URL.Query().Get("query") != "Leads" || r.URL.Query().Get("permissions") != "0,1" {
+			t.Errorf("unexpected search query: %s", r.URL.RawQuery)
+		}
+		return map[string]interface{}{"nodes": []interface{}{node("dst1", "Leads", "Datasheet")}}
+	}
+	server, credential, cpf := newTestServer(t, handlers)
+	defer server.Close()
+	spaceClient, _ := space.NewSpace(credential, "spc1", cpf)
+
+	paths := []string{}
+	err := spaceClient.WalkNodesWithOptions(&space.WalkOptions{Concurrency: 2}, func(node *space.NodeInfo) error {
+		paths = append(paths, node.Path)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(paths)
+	expected := "Docs,Sales,Sales/2024,Sales/2024/Leads,Sales/Leads"
+	if strings.Join(paths, ",") != expected {
+		t.Errorf("unexpected paths: %v", paths)
+	}
+
+	request := space.NewSearchNodesRequest().WithPermissions(space.NodePermission_Manager, space.NodePermission_Editor)
+	request.Query = common.StringPtr("Leads")
+	nodes, err := spaceClient.SearchNodes(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes) != 1 || nodes[0].Path != "Sales/2024/Leads" || nodes[0].ParentId != "fod2" {
+		t.Errorf("unexpected search result: %+v", nodes)
+	}
+}
+
+func TestResolvePath(t *testing.T) {
+	handlers := spaceTreeHandlers()
+	requests := 0
+	root := handlers["GET /fusion/v1/spaces/spc1/nodes"]
+	handlers["GET /fusion/v1/spaces/spc1/nodes"] = func(r *http.Request) interface{} {
+		requests++
+		return root(r)
+	}
+	handlers["GET /fusion/v1/spaces"] = func(r *http.Request) interface{} {
+		return map[string]interface{}{"spaces": []interface{}{
+			map[string]interface{}{"id": "spc1", "name": "Team"},
+			map[string]interface{}{"id": "spc2", "name": "Other"},
+			map[string]interface{}{"id": "spc3", "name": "Other"},
+		}}
+	}
+	server, credential, cpf := newTestServer(t, handlers)
+	defer server.Close()
+	spaceClient, _ := space.NewSpace(credential, "spc1", cpf)
+
+	datasheet, err := spaceClient.ResolvePath("Sales/2024/Leads")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if datasheet.DatasheetId != "dst1" || datasheet.SpaceId != "spc1" {
+		t.Errorf("unexpected datasheet: %s", datasheet.DatasheetId)
+	}
+	_, _ = spaceClient.ResolvePath("/Sales/2024/Leads/")
+	if requests != 1 {
+		t.Errorf("the resolved path should be cached, but requested %d times", requests)
+	}
+	if _, err = spaceClient.ResolvePath("Sales/2025/Leads"); err == nil || !strings.Contains(err.Error(), "Sales/2025") {
+		t.Errorf("expect not found error, but got %v", err)
+	}
+	if _, err = spaceClient.ResolvePath("Sales/2024"); err == nil {
+		t.Errorf("folder should not be resolved as datasheet")
+	}
+
+	userClient, _ := space.NewSpace(credential, "", cpf)
+	datasheet, err = userClient.OpenByPath("Team", "Sales/Leads")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if datasheet.DatasheetId != "dst2" {
+		t.Errorf("unexpected datasheet: %s", datasheet.DatasheetId)
+	}
+	if _, err = userClient.OpenByPath("Other", "Docs"); err == nil || !strings.Contains(err.Error(), "spc2") {
+		t.Errorf("expect ambiguous error, but got %v", err)
+	}
+	if parts := space.SplitPath(`a\/b/c`); len(parts) != 2 || parts[0] != "a/b" {
+		t.Errorf("unexpected split path: %v", parts)
+	}
+	// the names joined by JoinPath are split back as they are
+	names := []string{`a/b`, `c\`, `\/d\\`, "e"}
+	path := ""
+	for _, name := range names {
+		path = space.JoinPath(path, name)
+	}
+	if parts := space.SplitPath(path); strings.Join(parts, "|") != strings.Join(names, "|") {
+		t.Errorf("unexpected round trip of the path %s: %v", path, parts)
+	}
+}
+
+func TestCreateDatasheetWithoutId(t *testing.T) {
+	created := map[string]interface{}{"createdAt": 1}
+	server, credential, cpf := newTestServer(t, map[string]func(r *http.Request) interface{}{
+		"POST /fusion/v1/spaces/spc1/datasheets": func(r *http.Request) interface{} {
+			return created
+		},
+	})
+	defer server.Close()
+	spaceClient, _ := space.NewSpace(credential, "spc1", cpf)
+	if _, err := spaceClient.CreateDatasheet("Leads", "", nil, ""); err == nil || !strings.Contains(err.Error(), "no datasheet id") {
+		t.Errorf("expect the error of the response without id, but got %v", err)
+	}
+	created["id"] = "dst1"
+	datasheet, err := spaceClient.CreateDatasheet("Leads", "", nil, "")
+	if err != nil || datasheet.DatasheetId != "dst1" {
+		t.Errorf("unexpected datasheet: %v", err)
+	}
+}
+
+func TestWalkNodesWithoutName(t *testing.T) {
+	handlers := spaceTreeHandlers()
+	// the node without name is named by its id
+	handlers["GET /fusion/v1/spaces/spc1/nodes/fod2"] = func(r *http.Request) interface{} {
+		return map[string]interface{}{"id": "fod2", "type": "Folder", "children": []interface{}{map[string]interface{}{"id": "dst9", "type": "Datasheet"}}}
+	}
+	handlers["GET /fusion/v2/spaces/spc1/nodes"] = func(r *http.Request) interface{} {
+		return map[string]interface{}{"nodes": []interface{}{map[string]interface{}{"id": "dst9"}, map[string]interface{}{"type": "Datasheet"}}}
+	}
+	server, credential, cpf := newTestServer(t, handlers)
+	defer server.Close()
+	spaceClient, _ := space.NewSpace(credential, "spc1", cpf)
+
+	paths := []string{}
+	err := spaceClient.WalkNodes(func(node *space.NodeInfo) error {
+		paths = append(paths, node.Path)
+		return nil
+	})
+	sort.Strings(paths)
+	if err != nil || strings.Join(paths, ",") != "Docs,Sales,Sales/2024,Sales/2024/dst9,Sales/Leads" {
+		t.Errorf("unexpected paths: %v, %v", paths, err)
+	}
+	nodes, err := spaceClient.SearchNodes(space.NewSearchNodesRequest())
+	if err != nil || len(nodes) != 2 || nodes[0].Path != "Sales/2024/dst9" || nodes[1].Path != "" {
+		t.Errorf("unexpected search result: %+v, %v", nodes, err)
+	}
+}
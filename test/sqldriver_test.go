@@ -0,0 +1,171 @@
+package test
+
+import (
+	"database/sql"
+	"fmt"
+	apitable "github.com/apitable/apitable-sdks/apitable.go/lib/datasheet"
+	_ "github.com/apitable/apitable-sdks/apitable.go/lib/sqldriver"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSQLDriver(t *testing.T) {
+	day := float64(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC).UnixNano() / int64(time.Millisecond))
+	leads := newFakeRecords("dst2",
+		map[string]interface{}{"Name": "Beta", "Status": "Open", "Amount": 30.0, "Date": day, "Done": true},
+		map[string]interface{}{"Name": "Echo", "Status": "Open", "Amount": 20.0, "Tags": []interface{}{"vip"}},
+		map[string]interface{}{"Name": "alpha", "Status": "Open", "Amount": 10.0},
+		map[string]interface{}{"Name": "Delta", "Status": "Open", "Amount": 3.0},
+		map[string]interface{}{"Name": "Charlie", "Status": "Done", "Amount": 50.0, "Tags": []interface{}{"old"}},
+	)
+	handlers := leads.register(spaceTreeHandlers())
+	queries := []string{}
+	list := handlers["GET /fusion/v1/datasheets/dst2/records"]
+	handlers["GET /fusion/v1/datasheets/dst2/records"] = func(r *http.Request) interface{} {
+		q := r.URL.Query()
+		queries = append(queries, fmt.Sprintf("formula=%s sort=%s:%s max=%s fields=%v",
+			q.Get("filterByFormula"), q.Get("sort.0.field"), q.Get("sort.0.order"), q.Get("maxRecords"), listParam(r, "fields")))
+		return list(r)
+	}
+	creates := 0
+	create := handlers["POST /fusion/v1/datasheets/dst2/records"]
+	handlers["POST /fusion/v1/datasheets/dst2/records"] = func(r *http.Request) interface{} {
+		creates++
+		return create(r)
+	}
+	handlers["GET /fusion/v1/datasheets/dst2/fields"] = func(r *http.Request) interface{} {
+		return map[string]interface{}{"fields": []*apitable.DatasheetField{
+			newTestField("fld1", "Name", apitable.FieldType_SingleText),
+			newTestField("fld2", "Status", apitable.FieldType_SingleSelect),
+			newTestField("fld3", "Amount", apitable.FieldType_Number),
+			newTestField("fld4", "Date", apitable.FieldType_DateTime),
+			newTestField("fld5", "Done", apitable.FieldType_Checkbox),
+			newTestField("fld6", "Tags", apitable.FieldType_MultiSelect),
+			withProperty(newTestField("fld7", "Total", apitable.FieldType_Formula), `{"valueType":"Number"}`),
+		}}
+	}
+	server, _, _ := newTestServer(t, handlers)
+	defer server.Close()
+	db, err := sql.Open("vika", "token=token&space=spc1&scheme=http&domain="+strings.TrimPrefix(server.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// the where clause, the sort and the limit are pushed down
+	rows, err := db.Query(`SELECT Name, Amount AS amount FROM "Sales/Leads" WHERE Status = 'Open' AND Amount > ? ORDER BY Amount DESC LIMIT 2`, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	columns, _ := rows.Columns()
+	names := []string{}
+	for rows.Next() {
+		var name string
+		var amount float64
+		_ = rows.Scan(&name, &amount)
+		names = append(names, fmt.Sprintf("%s:%v", name, amount))
+	}
+	if strings.Join(names, ",") != "Beta:30,Echo:20" || strings.Join(columns, ",") != "Name,amount" {
+		t.Errorf("unexpected rows: %v, columns: %v", names, columns)
+	}
+	if queries[0] != `formula=AND({Status}="Open", {Amount}>5) sort=Amount:desc max=2 fields=[Name Status Amount]` {
+		t.Errorf("unexpected query: %s", queries[0])
+	}
+
+	// LIKE 'a%' can't be translated, so the OR is evaluated by the client, and _recordId is sorted by the client
+	rows, err = db.Query("SELECT _recordId, Name, Date, Done, Tags FROM dst2 WHERE Name LIKE 'a%' OR Tags IS NOT NULL ORDER BY _recordId DESC LIMIT 2 OFFSET 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	types, _ := rows.ColumnTypes()
+	typeNames := []string{}
+	for _, columnType := range types {
+		typeNames = append(typeNames, columnType.DatabaseTypeName())
+	}
+	names = names[:0]
+	for rows.Next() {
+		var id, name string
+		var date sql.NullTime
+		var done bool
+		var tags sql.NullString
+		if err = rows.Scan(&id, &name, &date, &done, &tags); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, fmt.Sprintf("%s:%s:%v:%v:%s", id, name, date.Valid, done, tags.String))
+	}
+	if strings.Join(typeNames, ",") != "TEXT,TEXT,DATETIME,BOOLEAN,JSON" || strings.Join(names, ",") != `rec3:alpha:false:false:,rec2:Echo:false:false:["vip"]` {
+		t.Errorf("unexpected rows: %v, types: %v", names, typeNames)
+	}
+	if queries[1] != "formula= sort=: max= fields=[Name Date Done Tags]" {
+		t.Errorf("unexpected query: %s", queries[1])
+	}
+
+	row := db.QueryRow("SELECT Date FROM dst2 WHERE Date >= '2024-03-01' AND Done = TRUE")
+	var date time.Time
+	if err = row.Scan(&date); err != nil || !date.Equal(time.Unix(0, int64(day)*int64(time.Millisecond))) {
+		t.Errorf("unexpected date: %v, %v", date, err)
+	}
+
+	// the values are written in batches
+	values := []string{}
+	args := []interface{}{}
+	for i := 0; i < 12; i++ {
+		values = append(values, "(?, ?, ?, 'New')")
+		args = append(args, fmt.Sprintf("new%d", i), i, `["a","b"]`)
+	}
+	result, err := db.Exec("INSERT INTO `Sales/Leads` (Name, Amount, Tags, Status) VALUES "+strings.Join(values, ", "), args...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if affected, _ := result.RowsAffected(); affected != 12 || creates != 2 {
+		t.Errorf("unexpected insert: %d rows, %d requests", affected, creates)
+	}
+	created := leads.get("rec6")
+	if created["Name"] != "new0" || created["Amount"] != 0.0 || fmt.Sprint(created["Tags"]) != "[a b]" {
+		t.Errorf("unexpected created record: %v", created)
+	}
+	if _, err = result.LastInsertId(); err == nil {
+		t.Errorf("expect LastInsertId not supported")
+	}
+
+	result, err = db.Exec(`UPDATE "Sales/Leads" SET Status = $2, Done = TRUE WHERE Status = $1 AND Amount < 5`, "New", "Small")
+	if affected, _ := result.RowsAffected(); err != nil || affected != 5 {
+		t.Fatalf("unexpected update: %d, %v", affected, err)
+	}
+	if updated := leads.get("rec10"); updated["Status"] != "Small" || updated["Done"] != true || leads.get("rec11")["Status"] != "New" {
+		t.Errorf("unexpected updated records: %v", updated)
+	}
+
+	result, err = db.Exec("DELETE FROM dst2 WHERE Status IN ('Small', 'Done')")
+	if affected, _ := result.RowsAffected(); err != nil || affected != 6 || len(leads.records) != 11 {
+		t.Errorf("unexpected delete: %d, %v", affected, err)
+	}
+	if !strings.HasPrefix(queries[len(queries)-1], `formula=OR({Status}="Small", {Status}="Done")`) {
+		t.Errorf("unexpected delete query: %s", queries[len(queries)-1])
+	}
+
+	// the []byte values are written as strings
+	if _, err = db.Exec("INSERT INTO dst2 (Name, Tags) VALUES (?, ?)", []byte("bytes"), []byte(`["c"]`)); err != nil {
+		t.Fatal(err)
+	}
+	if inserted := leads.records[len(leads.records)-1]["fields"].(map[string]interface{}); inserted["Name"] != "bytes" || fmt.Sprint(inserted["Tags"]) != "[c]" {
+		t.Errorf("unexpected inserted record: %v", inserted)
+	}
+
+	if _, err = db.Prepare("SELECT Name FROM dst2 WHERE Name = $99999999999999999999"); err == nil || !strings.Contains(err.Error(), "Invalid placeholder") {
+		t.Errorf("expect the error of the overflow placeholder, but got %v", err)
+	}
+	for _, query := range []string{"SELECT Missing FROM dst2", "SELECT Name FROM dst2 WHERE", `SELECT Name FROM "Sales/Unknown"`} {
+		if _, err = db.Query(query); err == nil {
+			t.Errorf("expect the error of %s", query)
+		}
+	}
+	if _, err = db.Exec("UPDATE dst2 SET Total = 1"); err == nil {
+		t.Errorf("expect the read only column error")
+	}
+	if _, err = db.Begin(); err == nil {
+		t.Errorf("expect the transaction not supported")
+	}
+}
@@ -0,0 +1,305 @@
+package test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	apitable "github.com/apitable/apitable-sdks/apitable.go/lib/datasheet"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/sqlsync"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeTable a table of the fake sql driver, only the statements generated by sqlsync are supported.
+type fakeTable struct {
+	mu   sync.Mutex
+	rows []map[string]driver.Value
+}
+
+var fakeTables = map[string]*fakeTable{}
+
+func init() {
+	sql.Register("fakesql", fakeDriver{})
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	return &fakeConn{table: fakeTables[name]}, nil
+}
+
+type fakeConn struct {
+	table *fakeTable
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{table: c.table, query: query}, nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return c, nil
+}
+
+func (c *fakeConn) Commit() error {
+	return nil
+}
+
+func (c *fakeConn) Rollback() error {
+	return nil
+}
+
+var (
+	selectPattern = regexp.MustCompile(`^SELECT (.+) FROM "\w+"$`)
+	insertPattern = regexp.MustCompile(`^INSERT INTO "\w+" \((.+)\) VALUES`)
+	updatePattern = regexp.MustCompile(`^UPDATE "\w+" SET (.+) WHERE "(\w+)" = \?$`)
+	deletePattern = regexp.MustCompile(`^DELETE FROM "\w+" WHERE "(\w+)" = \?$`)
+)
+
+type fakeStmt struct {
+	table *fakeTable
+	query string
+}
+
+func (s *fakeStmt) Close() error {
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int {
+	return -1
+}
+
+func columnNames(list string) []string {
+	names := strings.Split(list, ", ")
+	for i, name := range names {
+		names[i] = strings.Trim(strings.Split(name, " = ")[0], `"`)
+	}
+	return names
+}
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.table.mu.Lock()
+	defer s.table.mu.Unlock()
+	if m := insertPattern.FindStringSubmatch(s.query); m != nil {
+		row := map[string]driver.Value{}
+		for i, name := range columnNames(m[1]) {
+			row[name] = args[i]
+		}
+		s.table.rows = append(s.table.rows, row)
+		return driver.RowsAffected(1), nil
+	}
+	if m := updatePattern.FindStringSubmatch(s.query); m != nil {
+		key := fmt.Sprint(args[len(args)-1])
+		for _, row := range s.table.rows {
+			if fmt.Sprint(row[m[2]]) == key {
+				for i, name := range columnNames(m[1]) {
+					row[name] = args[i]
+				}
+			}
+		}
+		return driver.RowsAffected(1), nil
+	}
+	if m := deletePattern.FindStringSubmatch(s.query); m != nil {
+		kept := []map[string]driver.Value{}
+		for _, row := range s.table.rows {
+			if fmt.Sprint(row[m[1]]) != fmt.Sprint(args[0]) {
+				kept = append(kept, row)
+			}
+		}
+		s.table.rows = kept
+		return driver.RowsAffected(1), nil
+	}
+	return nil, fmt.Errorf("unsupported statement: %s", s.query)
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	m := selectPattern.FindStringSubmatch(s.query)
+	if m == nil {
+		return nil, fmt.Errorf("unsupported query: %s", s.query)
+	}
+	s.table.mu.Lock()
+	defer s.table.mu.Unlock()
+	rows := &fakeRows{columns: columnNames(m[1])}
+	for _, row := range s.table.rows {
+		values := make([]driver.Value, len(rows.columns))
+		for i, name := range rows.columns {
+			values[i] = row[name]
+		}
+		rows.values = append(rows.values, values)
+	}
+	return rows, nil
+}
+
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.columns
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func (t *fakeTable) find(id int64) map[string]driver.Value {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, row := range t.rows {
+		if row["id"] == id {
+			return row
+		}
+	}
+	return nil
+}
+
+func TestSqlSync(t *testing.T) {
+	table := &fakeTable{rows: []map[string]driver.Value{
+		{"id": int64(1), "name": []byte("Alice"), "amount": int64(100)},
+		{"id": int64(2), "name": []byte("Bob"), "amount": int64(200)},
+	}}
+	fakeTables["leads"] = table
+	db, err := sql.Open("fakesql", "leads")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	remote := newFakeRecords("dst1",
+		map[string]interface{}{"id": 1.0, "Name": "Alice", "Amount": 100.0},
+		map[string]interface{}{"id": 3.0, "Name": "Carol", "Amount": 300.0},
+	)
+	server, credential, cpf := newTestServer(t, remote.register(map[string]func(r *http.Request) interface{}{}))
+	defer server.Close()
+	datasheet, _ := apitable.NewDatasheet(credential, "dst1", cpf)
+	dir, _ := ioutil.TempDir("", "sqlsync")
+	defer os.RemoveAll(dir)
+
+	options := &sqlsync.Options{
+		Table:     "leads",
+		KeyColumn: "id",
+		Columns: []*sqlsync.Column{
+			{Column: "name", Field: "Name"},
+			{Column: "amount", Field: "Amount"},
+		},
+		State:  sqlsync.NewFileStateStore(filepath.Join(dir, "state.json")),
+		DryRun: true,
+	}
+	syncer, err := sqlsync.NewSyncer(db, datasheet, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := syncer.Sync(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Unchanged != 1 || report.Count(sqlsync.Action_CreateRecord) != 1 || report.Count(sqlsync.Action_InsertRow) != 1 {
+		t.Fatalf("unexpected dry run report: %+v", report)
+	}
+	if table.find(3) != nil || len(remote.records) != 2 {
+		t.Fatalf("dry run should not write")
+	}
+
+	options.DryRun = false
+	syncer, _ = sqlsync.NewSyncer(db, datasheet, options)
+	if _, err = syncer.Sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if row := table.find(3); row == nil || row["name"] != "Carol" || row["amount"] != int64(300) {
+		t.Fatalf("unexpected inserted row: %v", row)
+	}
+	if fields := remote.get("rec3"); fields == nil || fields["Name"] != "Bob" || fields["id"] != 2.0 {
+		t.Fatalf("unexpected created record: %v", fields)
+	}
+
+	// both sides edit Alice, the remote wins; Bob is edited locally; Carol is deleted remotely.
+	table.find(1)["amount"] = int64(120)
+	table.find(2)["name"] = "Bobby"
+	remote.get("rec1")["Amount"] = 150.0
+	remote.records = remote.records[:1]
+	remote.records = append(remote.records, record("rec3", map[string]interface{}{"id": 2.0, "Name": "Bob", "Amount": 200.0}))
+	report, err = syncer.Sync(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Conflicts) != 1 || report.Conflicts[0].Key != "1" || report.Conflicts[0].Winner != "remote" {
+		t.Errorf("unexpected conflicts: %+v", report.Conflicts)
+	}
+	if table.find(1)["amount"] != int64(150) {
+		t.Errorf("the remote should win the conflict: %v", table.find(1))
+	}
+	if remote.get("rec3")["Name"] != "Bobby" {
+		t.Errorf("the local edit should be pushed: %v", remote.get("rec3"))
+	}
+	if table.find(3) != nil {
+		t.Errorf("the remote deletion should be synced")
+	}
+	report, err = syncer.Sync(context.Background())
+	if err != nil || len(report.Changes) != 0 || report.Unchanged != 2 {
+		t.Errorf("unexpected report after sync: %+v, %v", report, err)
+	}
+}
+
+func TestSqlSyncEmptyValues(t *testing.T) {
+	table := &fakeTable{rows: []map[string]driver.Value{
+		{"id": int64(1), "name": "", "done": false, "tags": "[]"},
+		{"id": int64(2), "name": nil, "done": true, "tags": nil},
+	}}
+	fakeTables["empty"] = table
+	db, _ := sql.Open("fakesql", "empty")
+	defer db.Close()
+	// the api leaves the false checkbox and the empty cells out of the records
+	remote := newFakeRecords("dst1",
+		map[string]interface{}{"id": 1.0},
+		map[string]interface{}{"id": 2.0, "Done": true, "Tags": []interface{}{}},
+	)
+	server, credential, cpf := newTestServer(t, remote.register(map[string]func(r *http.Request) interface{}{}))
+	defer server.Close()
+	datasheet, _ := apitable.NewDatasheet(credential, "dst1", cpf)
+	dir, _ := ioutil.TempDir("", "sqlsync")
+	defer os.RemoveAll(dir)
+
+	syncer, err := sqlsync.NewSyncer(db, datasheet, &sqlsync.Options{
+		Table:     "empty",
+		KeyColumn: "id",
+		Columns: []*sqlsync.Column{
+			{Column: "name", Field: "Name"},
+			{Column: "done", Field: "Done"},
+			{Column: "tags", Field: "Tags"},
+		},
+		State:  sqlsync.NewFileStateStore(filepath.Join(dir, "state.json")),
+		Policy: sqlsync.ConflictPolicy_LocalWins,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		report, err := syncer.Sync(context.Background())
+		if err != nil || len(report.Changes) != 0 || len(report.Conflicts) != 0 || report.Unchanged != 2 {
+			t.Fatalf("the empty values should be unchanged: %+v, %v", report, err)
+		}
+	}
+	if row := table.find(1); row["done"] != false || row["tags"] != "[]" {
+		t.Errorf("the empty values should not be written: %v", row)
+	}
+}
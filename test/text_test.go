@@ -0,0 +1,15 @@
+package test
+
+import (
+	"github.com/apitable/apitable-sdks/apitable.go/lib/common/text"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	if s := text.Normalize("  ＡＢＣ　ｶﾞﾊﾟ  ｳﾞ１ "); s != "abc ガパ ヴ1" {
+		t.Errorf("unexpected normalized text: %q", s)
+	}
+	if d := text.Distance("kitten", "sitting"); d != 3 {
+		t.Errorf("unexpected distance: %d", d)
+	}
+}
@@ -0,0 +1,62 @@
+package test
+
+import (
+	"github.com/apitable/apitable-sdks/apitable.go/lib/common"
+	apitable "github.com/apitable/apitable-sdks/apitable.go/lib/datasheet"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileContentType(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "upload")
+	defer os.RemoveAll(dir)
+	empty := filepath.Join(dir, "empty.txt")
+	_ = ioutil.WriteFile(empty, nil, 0644)
+	f, _ := os.Open(empty)
+	defer f.Close()
+	if contentType, err := common.GetFileContentType(f); err != nil || contentType != "text/plain; charset=utf-8" {
+		t.Errorf("unexpected content type of the empty file: %s, %v", contentType, err)
+	}
+
+	// the sniffed bytes are read again after the content type is detected
+	small := filepath.Join(dir, "small.txt")
+	_ = ioutil.WriteFile(small, []byte("hello"), 0644)
+	f, _ = os.Open(small)
+	defer f.Close()
+	contentType, err := common.GetFileContentType(f)
+	content, _ := ioutil.ReadAll(f)
+	if err != nil || !strings.HasPrefix(contentType, "text/plain") || string(content) != "hello" {
+		t.Errorf("unexpected content type: %s, %s, %v", contentType, content, err)
+	}
+}
+
+func TestUploadSmallFile(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "upload")
+	defer os.RemoveAll(dir)
+	filePath := filepath.Join(dir, "a.txt")
+	_ = ioutil.WriteFile(filePath, []byte("hello"), 0644)
+	uploaded := ""
+	server, credential, cpf := newTestServer(t, map[string]func(r *http.Request) interface{}{
+		"POST /fusion/v1/datasheets/dst1/attachments": func(r *http.Request) interface{} {
+			file, _, err := r.FormFile("file")
+			if err == nil {
+				b, _ := ioutil.ReadAll(file)
+				uploaded = string(b)
+			}
+			return map[string]interface{}{"token": "tok1", "name": "a.txt", "size": len(uploaded)}
+		},
+	})
+	defer server.Close()
+	// the file of the request is sent without the upload profile
+	datasheet, _ := apitable.NewDatasheet(credential, "dst1", cpf)
+	request := apitable.NewUploadRequest()
+	request.FilePath = filePath
+	attachment, err := datasheet.UploadFile(request)
+	if err != nil || uploaded != "hello" || attachment.Token == nil || *attachment.Token != "tok1" {
+		t.Errorf("unexpected upload: %q, %+v, %v", uploaded, attachment, err)
+	}
+}
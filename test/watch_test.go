@@ -0,0 +1,80 @@
+package test
+
+import (
+	"context"
+	apitable "github.com/apitable/apitable-sdks/apitable.go/lib/datasheet"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	var mu sync.Mutex
+	polls := 0
+	remote := []interface{}{
+		record("rec1", map[string]interface{}{"Name": "a", "Amount": 1}),
+		record("rec2", map[string]interface{}{"Name": "b"}),
+	}
+	server, credential, cpf := newTestServer(t, map[string]func(r *http.Request) interface{}{
+		"GET /fusion/v1/datasheets/dst1/records": func(r *http.Request) interface{} {
+			mu.Lock()
+			defer mu.Unlock()
+			polls++
+			if polls == 2 {
+				remote = []interface{}{
+					record("rec1", map[string]interface{}{"Name": "a", "Amount": 2}),
+					record("rec3", map[string]interface{}{"Name": "c"}),
+				}
+			}
+			return recordPage(remote...)
+		},
+	})
+	defer server.Close()
+	datasheet, _ := apitable.NewDatasheet(credential, "dst1", cpf)
+	dir, _ := ioutil.TempDir("", "watch")
+	defer os.RemoveAll(dir)
+	checkpoint := apitable.NewFileCheckpointStore(filepath.Join(dir, "checkpoint.json"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := datasheet.Watch(ctx, &apitable.WatchOptions{
+		Interval:   10 * time.Millisecond,
+		Checkpoint: checkpoint,
+		OnError:    func(err error) { t.Error(err) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	received := map[apitable.RecordEventType]*apitable.RecordEvent{}
+	timeout := time.After(5 * time.Second)
+	for len(received) < 3 {
+		select {
+		case event := <-events:
+			received[event.Type] = event
+		case <-timeout:
+			t.Fatalf("events are not received: %v", received)
+		}
+	}
+	cancel()
+	for range events {
+	}
+	updated := received[apitable.RecordEvent_Updated]
+	if updated.RecordId != "rec1" || len(updated.Changes) != 1 || updated.Changes[0].Field != "Amount" ||
+		updated.Changes[0].Before.(float64) != 1 || updated.Changes[0].After.(float64) != 2 {
+		t.Errorf("unexpected updated event: %+v", updated)
+	}
+	if received[apitable.RecordEvent_Created].RecordId != "rec3" || received[apitable.RecordEvent_Deleted].RecordId != "rec2" {
+		t.Errorf("unexpected events: %+v", received)
+	}
+
+	// restart from the checkpoint, the history should not be replayed.
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	events, _ = datasheet.Watch(ctx, &apitable.WatchOptions{Interval: 10 * time.Millisecond, Checkpoint: checkpoint})
+	for event := range events {
+		t.Errorf("unexpected replayed event: %+v", event)
+	}
+}
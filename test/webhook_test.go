@@ -0,0 +1,65 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/apitable/apitable-sdks/apitable.go/lib/webhook"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebhookReceiver(t *testing.T) {
+	receiver := webhook.NewReceiver("secret", nil)
+	handled := map[string]int{}
+	fail := true
+	receiver.Handle("dst1", webhook.TriggerType_RecordCreated, func(ctx context.Context, event *webhook.Event) error {
+		handled["created"]++
+		if (*event.Record.Fields)["Name"] != "a" {
+			t.Errorf("unexpected record: %v", *event.Record.Fields)
+		}
+		return nil
+	})
+	receiver.Handle(webhook.AnyDatasheet, webhook.TriggerType_Any, func(ctx context.Context, event *webhook.Event) error {
+		handled["any"]++
+		if fail {
+			fail = false
+			return errors.New("temporary error")
+		}
+		return nil
+	})
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	post := func(body string, signature string) int {
+		request, _ := http.NewRequest(http.MethodPost, server.URL, bytes.NewBufferString(body))
+		request.Header.Set(webhook.DefaultSignatureHeader, signature)
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		response.Body.Close()
+		return response.StatusCode
+	}
+	created := `{"eventId":"evt1","datasheetId":"dst1","triggerType":"RecordCreated","record":{"recordId":"rec1","fields":{"Name":"a"}}}`
+	if status := post(created, "sha256=00"); status != http.StatusUnauthorized {
+		t.Errorf("invalid signature should be rejected, got %d", status)
+	}
+	if status := post(created, webhook.Sign("secret", []byte(created))); status != http.StatusOK {
+		t.Errorf("unexpected status %d", status)
+	}
+	if status := post(created, webhook.Sign("secret", []byte(created))); status != http.StatusOK || handled["created"] != 1 {
+		t.Errorf("redelivery should be deduplicated, handled %d times", handled["created"])
+	}
+	other := `{"datasheetId":"dst2","triggerType":"FormSubmitted","records":[]}`
+	if status := post(other, webhook.Sign("secret", []byte(other))); status != http.StatusInternalServerError {
+		t.Errorf("failed handler should respond 500, got %d", status)
+	}
+	if status := post(other, webhook.Sign("secret", []byte(other))); status != http.StatusOK || handled["any"] != 2 {
+		t.Errorf("failed event should be redelivered, handled %d times", handled["any"])
+	}
+	if status := post("{", webhook.Sign("secret", []byte("{"))); status != http.StatusBadRequest {
+		t.Errorf("invalid payload should be rejected, got %d", status)
+	}
+}